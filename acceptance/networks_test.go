@@ -13,7 +13,6 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/client"
 	"github.com/cloudfoundry-incubator/ducati-daemon/config"
 	"github.com/cloudfoundry-incubator/ducati-daemon/container"
-	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 	"github.com/cloudfoundry-incubator/ducati-daemon/network"
@@ -200,6 +199,14 @@ var _ = Describe("Networks", func() {
 			Expect(containers[0].HostIP).To(Equal(hostAddress))
 		})
 
+		It("reports the network as undefined alongside its containers", func() {
+			details, err := daemonClient.GetNetwork(networkID)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(details.Network).To(BeNil())
+			Expect(details.Containers).To(HaveLen(1))
+		})
+
 		It("makes container metadata available on the /containers endpoint", func() {
 			containers, err := daemonClient.ListContainers()
 			Expect(err).NotTo(HaveOccurred())
@@ -221,10 +228,32 @@ var _ = Describe("Networks", func() {
 			Expect(container.App).To(Equal(appID))
 		})
 
+		It("reports the allocation on the ipam usage endpoint", func() {
+			usage, err := daemonClient.GetIPAMUsage(networkID)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(usage.NetworkID).To(Equal(networkID))
+			Expect(usage.Pools).NotTo(BeEmpty())
+			Expect(usage.Pools[0].Allocated).To(BeNumerically(">=", 1))
+			Expect(usage.Pools[0].Allocations).To(HaveKeyWithValue(containerID, ipamResult.IP4.IP.IP.String()))
+			Expect(usage.Pools[0].Free).To(BeNumerically("<", usage.Pools[0].Size))
+		})
+
 		Context("when the ADD endpoint is called a second time with the same container ID", func() {
-			It("should return an error and not crash the system", func() {
-				_, err := daemonClient.ContainerUp(upSpec) // 2nd time we're calling this
-				Expect(err).To(MatchError(ipam.AlreadyOnNetworkError))
+			It("returns the original result and does not crash the system", func() {
+				result, err := daemonClient.ContainerUp(upSpec) // 2nd time we're calling this
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ipamResult))
+			})
+
+			Context("through a different interface", func() {
+				It("returns a container conflict error", func() {
+					conflicting := upSpec
+					conflicting.InterfaceName = "vx-eth1"
+
+					_, err := daemonClient.ContainerUp(conflicting)
+					Expect(err).To(MatchError(client.ContainerConflictError))
+				})
 			})
 		})
 
@@ -16,6 +16,11 @@ import (
 )
 
 var RecordNotFoundError error = errors.New("record not found")
+var ContainerConflictError error = models.ContainerConflictError
+var InvalidArgsError error = models.InvalidArgsError
+var UndefinedNetworkError error = models.UndefinedNetworkError
+var LockTimeoutError error = models.LockTimeoutError
+var NetworkInUseError error = models.NetworkInUseError
 
 func New(baseURL string, httpClient *http.Client) *DaemonClient {
 	return &DaemonClient{
@@ -78,8 +83,16 @@ func (d *DaemonClient) ContainerUp(payload models.CNIAddPayload) (types.Result,
 		ResponseResult:    &ipamResult,
 		SuccessStatusCode: http.StatusCreated,
 		MeaningfulErrors: map[int]error{
-			http.StatusBadRequest: ipam.AlreadyOnNetworkError,
-			http.StatusConflict:   ipam.NoMoreAddressesError,
+			http.StatusBadRequest:          ipam.AlreadyOnNetworkError,
+			http.StatusConflict:            ipam.NoMoreAddressesError,
+			http.StatusUnprocessableEntity: ContainerConflictError,
+			http.StatusServiceUnavailable:  LockTimeoutError,
+		},
+		KnownErrors: []error{
+			ipam.AddressNotInSubnetError,
+			ipam.AddressTakenError,
+			InvalidArgsError,
+			UndefinedNetworkError,
 		},
 	})
 	return ipamResult, err
@@ -92,6 +105,9 @@ func (d *DaemonClient) ContainerDown(payload models.CNIDelPayload) error {
 		URL:               "/cni/del",
 		RequestPayload:    payload,
 		SuccessStatusCode: http.StatusNoContent,
+		MeaningfulErrors: map[int]error{
+			http.StatusServiceUnavailable: LockTimeoutError,
+		},
 	})
 }
 
@@ -113,17 +129,105 @@ func (d *DaemonClient) GetContainer(containerID string) (models.Container, error
 }
 
 func (d *DaemonClient) ListNetworkContainers(networkID string) ([]models.Container, error) {
-	var containers []models.Container
+	details, err := d.GetNetwork(networkID)
+	return details.Containers, err
+}
+
+func (d *DaemonClient) GetNetwork(networkID string) (models.NetworkDetails, error) {
+	var details models.NetworkDetails
 
 	err := d.JSONClient.BuildAndDo(ClientConfig{
-		Action:            "ListNetworkContainers",
+		Action:            "GetNetwork",
 		Method:            "GET",
 		URL:               path.Join("networks", networkID),
 		RequestPayload:    nil,
-		ResponseResult:    &containers,
+		ResponseResult:    &details,
 		SuccessStatusCode: http.StatusOK,
 	})
-	return containers, err
+	return details, err
+}
+
+func (d *DaemonClient) CreateNetwork(network models.Network) (models.Network, error) {
+	var created models.Network
+
+	err := d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "CreateNetwork",
+		Method:            "POST",
+		URL:               "networks",
+		RequestPayload:    network,
+		ResponseResult:    &created,
+		SuccessStatusCode: http.StatusCreated,
+	})
+	return created, err
+}
+
+func (d *DaemonClient) ListNetworks() ([]models.Network, error) {
+	var networks []models.Network
+
+	err := d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "ListNetworks",
+		Method:            "GET",
+		URL:               "networks",
+		RequestPayload:    nil,
+		ResponseResult:    &networks,
+		SuccessStatusCode: http.StatusOK,
+	})
+	return networks, err
+}
+
+func (d *DaemonClient) DeleteNetwork(networkID string) error {
+	return d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "DeleteNetwork",
+		Method:            "DELETE",
+		URL:               path.Join("networks", networkID),
+		RequestPayload:    nil,
+		SuccessStatusCode: http.StatusNoContent,
+		MeaningfulErrors: map[int]error{
+			http.StatusNotFound: RecordNotFoundError,
+			http.StatusConflict: NetworkInUseError,
+		},
+	})
+}
+
+func (d *DaemonClient) CreatePolicy(policy models.Policy) (models.Policy, error) {
+	var created models.Policy
+
+	err := d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "CreatePolicy",
+		Method:            "POST",
+		URL:               "policies",
+		RequestPayload:    policy,
+		ResponseResult:    &created,
+		SuccessStatusCode: http.StatusCreated,
+	})
+	return created, err
+}
+
+func (d *DaemonClient) ListPolicies() ([]models.Policy, error) {
+	var policies []models.Policy
+
+	err := d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "ListPolicies",
+		Method:            "GET",
+		URL:               "policies",
+		RequestPayload:    nil,
+		ResponseResult:    &policies,
+		SuccessStatusCode: http.StatusOK,
+	})
+	return policies, err
+}
+
+func (d *DaemonClient) DeletePolicy(policyID string) error {
+	return d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "DeletePolicy",
+		Method:            "DELETE",
+		URL:               path.Join("policies", policyID),
+		RequestPayload:    nil,
+		SuccessStatusCode: http.StatusNoContent,
+		MeaningfulErrors: map[int]error{
+			http.StatusNotFound: RecordNotFoundError,
+		},
+	})
 }
 
 func (d *DaemonClient) ListContainers() ([]models.Container, error) {
@@ -140,6 +244,23 @@ func (d *DaemonClient) ListContainers() ([]models.Container, error) {
 	return containers, err
 }
 
+func (d *DaemonClient) GetIPAMUsage(networkID string) (models.IPAMUsage, error) {
+	var usage models.IPAMUsage
+
+	err := d.JSONClient.BuildAndDo(ClientConfig{
+		Action:            "GetIPAMUsage",
+		Method:            "GET",
+		URL:               path.Join("ipam", "networks", networkID),
+		RequestPayload:    nil,
+		ResponseResult:    &usage,
+		SuccessStatusCode: http.StatusOK,
+		MeaningfulErrors: map[int]error{
+			http.StatusNotFound: RecordNotFoundError,
+		},
+	})
+	return usage, err
+}
+
 func checkStatus(method string, receivedStatus, expectedStatus int) error {
 	if receivedStatus != expectedStatus {
 		return fmt.Errorf("unexpected status code on %s: expected %d but got %d", method, expectedStatus, receivedStatus)
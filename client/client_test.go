@@ -132,4 +132,55 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("ContainerUp", func() {
+		Context("when the container lock times out", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/cni/add"),
+					ghttp.RespondWithJSONEncoded(http.StatusServiceUnavailable, map[string]string{
+						"error": "timed out waiting for another operation on this container",
+					}),
+				))
+			})
+
+			It("returns a LockTimeoutError", func() {
+				_, err := c.ContainerUp(models.CNIAddPayload{ContainerID: "some-container-id"})
+				Expect(err).To(Equal(client.LockTimeoutError))
+			})
+		})
+	})
+
+	Describe("ContainerDown", func() {
+		Context("when the container lock times out", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/cni/del"),
+					ghttp.RespondWithJSONEncoded(http.StatusServiceUnavailable, map[string]string{
+						"error": "timed out waiting for another operation on this container",
+					}),
+				))
+			})
+
+			It("returns a LockTimeoutError", func() {
+				err := c.ContainerDown(models.CNIDelPayload{ContainerID: "some-container-id"})
+				Expect(err).To(Equal(client.LockTimeoutError))
+			})
+		})
+	})
+	Describe("GetIPAMUsage", func() {
+		Context("when the network is unknown", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/ipam/networks/some-network-id"),
+					ghttp.RespondWith(http.StatusNotFound, nil),
+				))
+			})
+
+			It("returns a RecordNotFoundError", func() {
+				_, err := c.GetIPAMUsage("some-network-id")
+				Expect(err).To(Equal(client.RecordNotFoundError))
+			})
+		})
+	})
 })
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -27,6 +28,10 @@ type ClientConfig struct {
 	ResponseResult    interface{}
 	SuccessStatusCode int
 	MeaningfulErrors  map[int]error
+	// KnownErrors are matched against the error message in the response body
+	// before falling back to MeaningfulErrors, for when several errors share
+	// a status code.
+	KnownErrors []error
 }
 
 func (d *JSONClient) BuildAndDo(config ClientConfig) error {
@@ -59,6 +64,9 @@ func (d *JSONClient) BuildAndDo(config ClientConfig) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != config.SuccessStatusCode {
+		if knownError := matchKnownError(resp.Body, config.KnownErrors); knownError != nil {
+			return knownError
+		}
 		for statusCode, meaningfulError := range config.MeaningfulErrors {
 			if statusCode == resp.StatusCode {
 				return meaningfulError
@@ -79,6 +87,28 @@ func (d *JSONClient) BuildAndDo(config ClientConfig) error {
 	return nil
 }
 
+func matchKnownError(body io.Reader, knownErrors []error) error {
+	if len(knownErrors) == 0 {
+		return nil
+	}
+
+	var errorBody struct {
+		Error string `json:"error"`
+	}
+	err := json.NewDecoder(body).Decode(&errorBody)
+	if err != nil {
+		return nil
+	}
+
+	for _, knownError := range knownErrors {
+		if knownError.Error() == errorBody.Error {
+			return knownError
+		}
+	}
+
+	return nil
+}
+
 func (d *JSONClient) buildURL(routeElements ...string) (string, error) {
 	parsedURL, err := url.Parse(d.BaseURL)
 	if err != nil {
@@ -168,6 +168,37 @@ var _ = Describe("JSON Client", func() {
 			})
 		})
 
+		Context("when the response body names a known error", func() {
+			var knownError error
+
+			BeforeEach(func() {
+				knownError = errors.New("something specific")
+				config.KnownErrors = []error{errors.New("something else"), knownError}
+
+				server.SetHandler(0, ghttp.RespondWithJSONEncoded(http.StatusBadRequest, map[string]string{
+					"error": "something specific",
+				}))
+			})
+
+			It("returns that error in preference to the status code mapping", func() {
+				err := jsonClient.BuildAndDo(config)
+				Expect(err).To(BeIdenticalTo(knownError))
+			})
+
+			Context("when the message is not known", func() {
+				BeforeEach(func() {
+					server.SetHandler(0, ghttp.RespondWithJSONEncoded(http.StatusBadRequest, map[string]string{
+						"error": "something generic",
+					}))
+				})
+
+				It("falls back to the status code mapping", func() {
+					err := jsonClient.BuildAndDo(config)
+					Expect(err).To(MatchError("bad request"))
+				})
+			})
+		})
+
 		Context("when the BaseURL has a trailing slash", func() {
 			It("handles just fine", func() {
 				jsonClient.BaseURL += "/"
@@ -19,9 +19,11 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/config"
 	"github.com/cloudfoundry-incubator/ducati-daemon/container"
+	"github.com/cloudfoundry-incubator/ducati-daemon/distributor"
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
 	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/firewall"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/ip"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/links"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
@@ -30,10 +32,14 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/subscriber"
 	"github.com/cloudfoundry-incubator/ducati-daemon/network"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ossupport"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+	"github.com/cloudfoundry-incubator/ducati-daemon/reaper"
 	"github.com/cloudfoundry-incubator/ducati-daemon/reloader"
 	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
 	"github.com/cloudfoundry-incubator/ducati-daemon/store"
 	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/clock"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/grouper"
 	"github.com/tedsuo/ifrit/http_server"
@@ -81,14 +87,43 @@ func main() {
 
 	logger, reconfigurableSink := cf_lager.New("ducatid")
 
+	networkStore := store.NewNetworkStore(dbConnectionPool)
+
 	configFactory := &ipam.ConfigFactory{
-		Config: types.IPConfig{
-			IP: *subnet,
+		Config: ipam.Config{
+			IP4: &types.IPConfig{
+				IP: *subnet,
+			},
 		},
+		Definitions: networkStore,
+		NotFound:    store.RecordNotFoundError,
+		Reserved:    conf.ReservedAddresses,
+	}
+	if conf.LocalSubnetV6 != nil {
+		configFactory.Config.IP6 = &types.IPConfig{
+			IP: *conf.LocalSubnetV6,
+		}
+	}
+	for networkID, networkIPAM := range conf.NetworkIPAM {
+		if configFactory.Networks == nil {
+			configFactory.Networks = map[string]ipam.Config{}
+		}
+		configFactory.Networks[networkID] = ipam.Config{
+			IP4:        networkIPAM.IP4,
+			IP6:        networkIPAM.IP6,
+			Exclusions: networkIPAM.Exclusions,
+		}
+	}
+
+	reservationStoreFactory := &store.ReservationStoreFactory{
+		DB:         dbConnectionPool,
+		Clock:      clock.NewClock(),
+		Quarantine: conf.IPQuarantine,
+		HostIP:     conf.HostAddress.String(),
 	}
 
 	ipAllocator := ipam.New(
-		&ipam.StoreFactory{},
+		reservationStoreFactory,
 		&sync.Mutex{},
 		configFactory,
 		&sync.Mutex{},
@@ -115,9 +150,15 @@ func main() {
 		Logger:    logger.Session("subscriber"),
 		Netlinker: nl.Netlink,
 	}
+	resolverCache := &watcher.ResolverCache{
+		Store:       dataStore,
+		Clock:       clock.NewClock(),
+		TTL:         conf.ResolverCacheTTL,
+		NegativeTTL: conf.ResolverNegativeCacheTTL,
+	}
 	resolver := &watcher.Resolver{
 		Logger: logger,
-		Store:  dataStore,
+		Store:  resolverCache,
 	}
 	arpInserter := &neigh.ARPInserter{
 		Logger:    logger,
@@ -130,10 +171,24 @@ func main() {
 		resolver,
 		arpInserter,
 	)
-	networkMapper := &network.FixedNetworkMapper{DefaultNetworkID: "default"}
+	vniRegistry := store.NewVNIRegistry(dbConnectionPool)
+	networkMapper := &network.RegistryNetworkMapper{
+		Logger:   logger.Session("network-mapper"),
+		Mapper:   newNetworkMapper(conf.NetworkMapping, store.NewMappingStore(dbConnectionPool), vniRegistry),
+		Registry: vniRegistry,
+	}
+
+	egress := newEgress(conf.Egress)
+	egressLinks := store.NewEgressLinkRegistry(dbConnectionPool, conf.HostAddress.String(), egress.LinkCount())
 
 	reloader := &reloader.Reloader{
 		Watcher: missWatcher,
+		Egress: &container.EgressReloader{
+			Egress:     egress,
+			Containers: dataStore,
+			Links:      linkFactory,
+			Firewall:   firewall.IPTables{},
+		},
 	}
 
 	sandboxRepo := &sandbox.Repository{
@@ -154,6 +209,7 @@ func main() {
 	commandBuilder := &container.CommandBuilder{
 		MissWatcher:   missWatcher,
 		HostNamespace: hostNamespace,
+		Egress:        egress,
 	}
 	dnsFactory := &executor.DNSFactory{
 		Logger:           logger,
@@ -171,6 +227,7 @@ func main() {
 		sandboxRepo,
 		executor.ListenUDPFunc(net.ListenUDP),
 		dnsFactory,
+		firewall.IPTables{},
 	)
 	creator := &container.Creator{
 		Executor:        executor,
@@ -180,24 +237,43 @@ func main() {
 		DNSAddress:      fmt.Sprintf("%s:%d", conf.OverlayDNSAddress, 53),
 		HostIP:          conf.HostAddress,
 		NamespaceOpener: namespaceOpener,
+		Egress:          egress,
+		EgressLinks:     egressLinks,
+		HostFirewall:    firewall.IPTables{},
 	}
 	deletor := &container.Deletor{
 		Executor:        executor,
 		NamespaceOpener: namespaceOpener,
+		Egress:          egress,
+		EgressLinks:     egressLinks,
+	}
+
+	containerLocker := &cni.ContainerLocker{
+		Clock:   clock.NewClock(),
+		Timeout: conf.ContainerLockTimeout,
 	}
 
 	addController := &cni.AddController{
-		IPAllocator:   ipAllocator,
-		NetworkMapper: networkMapper,
-		Creator:       creator,
-		Datastore:     dataStore,
+		IPAllocator:    ipAllocator,
+		NetworkMapper:  networkMapper,
+		Creator:        creator,
+		Deletor:        deletor,
+		Datastore:      dataStore,
+		Networks:       networkStore,
+		StrictNetworks: conf.StrictNetworks,
+		ResolverCache:  resolverCache,
+		Locker:         containerLocker,
 	}
 
 	delController := &cni.DelController{
+		Logger:        logger,
 		Datastore:     dataStore,
+		Reservations:  store.NewReservationIndex(dbConnectionPool),
 		Deletor:       deletor,
 		IPAllocator:   ipAllocator,
 		NetworkMapper: networkMapper,
+		ResolverCache: resolverCache,
+		Locker:        containerLocker,
 	}
 
 	marshaler := marshal.MarshalFunc(json.Marshal)
@@ -213,6 +289,65 @@ func main() {
 		Marshaler: marshaler,
 		Logger:    logger,
 		Datastore: dataStore,
+		Networks:  networkStore,
+	}
+
+	rataHandlers["create_network"] = &handlers.CreateNetwork{
+		Unmarshaler:   unmarshaler,
+		Marshaler:     marshaler,
+		Logger:        logger,
+		Networks:      networkStore,
+		NetworkMapper: networkMapper,
+		IPAllocator:   ipAllocator,
+	}
+
+	rataHandlers["list_networks"] = &handlers.ListNetworks{
+		Marshaler: marshaler,
+		Logger:    logger,
+		Networks:  networkStore,
+	}
+
+	rataHandlers["delete_network"] = &handlers.DeleteNetwork{
+		Marshaler:   marshaler,
+		Logger:      logger,
+		Networks:    networkStore,
+		IPAllocator: ipAllocator,
+	}
+
+	policyStore := store.NewPolicyStore(dbConnectionPool)
+	policyEnforcer := &policy.Enforcer{
+		Logger:     logger,
+		HostIP:     conf.HostAddress.String(),
+		Policies:   policyStore,
+		Containers: dataStore,
+		Sandboxes:  sandboxRepo,
+		Writer:     policy.IPTables{},
+		Writer6:    policy.IPTables{IPv6: true},
+		Links: &container.EgressLinkLister{
+			Egress:   egress,
+			Registry: egressLinks,
+		},
+		Routes: &policy.HostRoutes{Netlinker: nl.Netlink},
+	}
+
+	rataHandlers["create_policy"] = &handlers.CreatePolicy{
+		Unmarshaler: unmarshaler,
+		Marshaler:   marshaler,
+		Logger:      logger,
+		Policies:    policyStore,
+		Enforcer:    policyEnforcer,
+	}
+
+	rataHandlers["list_policies"] = &handlers.ListPolicies{
+		Marshaler: marshaler,
+		Logger:    logger,
+		Policies:  policyStore,
+	}
+
+	rataHandlers["delete_policy"] = &handlers.DeletePolicy{
+		Logger:   logger,
+		Policies: policyStore,
+		Enforcer: policyEnforcer,
 	}
 
 	rataHandlers["list_containers"] = &handlers.ListContainers{
@@ -221,6 +356,12 @@ func main() {
 		Datastore: dataStore,
 	}
 
+	rataHandlers["ipam_network_usage"] = &handlers.IPAMNetworkUsage{
+		Marshaler:   marshaler,
+		Logger:      logger,
+		IPAllocator: ipAllocator,
+	}
+
 	rataHandlers["cni_add"] = &handlers.CNIAdd{
 		Logger:      logger,
 		Marshaler:   marshaler,
@@ -238,7 +379,14 @@ func main() {
 	routes := rata.Routes{
 		{Name: "get_container", Method: "GET", Path: "/containers/:container_id"},
 		{Name: "networks_list_containers", Method: "GET", Path: "/networks/:network_id"},
+		{Name: "create_network", Method: "POST", Path: "/networks"},
+		{Name: "list_networks", Method: "GET", Path: "/networks"},
+		{Name: "delete_network", Method: "DELETE", Path: "/networks/:network_id"},
+		{Name: "create_policy", Method: "POST", Path: "/policies"},
+		{Name: "list_policies", Method: "GET", Path: "/policies"},
+		{Name: "delete_policy", Method: "DELETE", Path: "/policies/:policy_id"},
 		{Name: "list_containers", Method: "GET", Path: "/containers"},
+		{Name: "ipam_network_usage", Method: "GET", Path: "/ipam/networks/:network_id"},
 		{Name: "cni_add", Method: "POST", Path: "/cni/add"},
 		{Name: "cni_del", Method: "POST", Path: "/cni/del"},
 	}
@@ -258,12 +406,96 @@ func main() {
 		log.Fatalf("unable to restart monitors: %s", err)
 	}
 
+	reservationRestorer := &ipam.Restorer{
+		Logger:       logger,
+		Clock:        clock.NewClock(),
+		Datastore:    dataStore,
+		StoreFactory: reservationStoreFactory,
+		Orphans:      store.NewReservationIndex(dbConnectionPool),
+		GracePeriod:  conf.IPOrphanGracePeriod,
+		Quarantine:   conf.IPQuarantine,
+		HostIP:       conf.HostAddress,
+	}
+
+	err = reservationRestorer.Restore()
+	if _, ok := err.(ipam.ConflictError); ok {
+		logger.Error("ip-reservation-conflicts", err)
+	} else if err != nil {
+		log.Fatalf("unable to restore ip reservations: %s", err)
+	}
+
+	containerEventListener := &distributor.Listener{
+		Logger:      logger,
+		PQListener:  pq.NewListener(databaseURL, time.Second, time.Minute, nil),
+		Unmarshaler: unmarshaler,
+		Distributor: distributor.Chain{
+			&distributor.Distributor{
+				Logger:        logger,
+				HostIP:        conf.HostAddress,
+				NetworkMapper: networkMapper,
+				SandboxRepo:   sandboxRepo,
+				Neighbors:     arpInserter,
+				ResolverCache: resolverCache,
+			},
+			policyEnforcer,
+		},
+	}
+
+	policyEventListener := &policy.Listener{
+		Logger:     logger,
+		PQListener: pq.NewListener(databaseURL, time.Second, time.Minute, nil),
+		Syncer:     policyEnforcer,
+	}
+
 	httpServer := http_server.New(conf.ListenAddress, rataRouter)
 
 	members := grouper.Members{
+		{"container-event-listener", containerEventListener},
+		{"policy-event-listener", policyEventListener},
 		{"http_server", httpServer},
 	}
 
+	if conf.NeighborEvictionInterval > 0 {
+		members = append(members, grouper.Member{"neighbor-eviction", &neigh.EvictionLoop{
+			Logger:    logger,
+			Clock:     clock.NewClock(),
+			Interval:  conf.NeighborEvictionInterval,
+			Sandboxes: sandboxRepo,
+			Evictor: &neigh.Evictor{
+				Logger:    logger,
+				Netlinker: nl.Netlink,
+				Store:     dataStore,
+			},
+		}})
+	}
+
+	if conf.IPReapInterval > 0 {
+		members = append(members, grouper.Member{"ip-reaper", &reaper.Reaper{
+			Logger:        logger,
+			Clock:         clock.NewClock(),
+			Interval:      conf.IPReapInterval,
+			LeaseDuration: conf.IPLeaseDuration,
+			HostIP:        conf.HostAddress,
+			Datastore:     dataStore,
+			Leases:        store.NewLeaseStore(dbConnectionPool),
+			Links: &reaper.SandboxLinkChecker{
+				Sandboxes:   sandboxRepo,
+				LinkFactory: linkFactory,
+			},
+			IPAllocator: ipAllocator,
+			Locker:      containerLocker,
+		}})
+	}
+
+	if conf.PolicySyncInterval > 0 {
+		members = append(members, grouper.Member{"policy-sync", &policy.SyncLoop{
+			Logger:   logger,
+			Clock:    clock.NewClock(),
+			Interval: conf.PolicySyncInterval,
+			Syncer:   policyEnforcer,
+		}})
+	}
+
 	if conf.DebugAddress != "" {
 		members = append(grouper.Members{
 			{"debug-server", cf_debug_server.Runner(conf.DebugAddress, reconfigurableSink)},
@@ -279,3 +511,50 @@ func main() {
 		log.Fatalf("daemon terminated: %s", err)
 	}
 }
+
+func newNetworkMapper(mapping config.NetworkMapping, mappingStore store.MappingStore, vniRegistry store.VNIRegistry) network.NetworkMapper {
+	switch mapping.Policy {
+	case config.AppPolicy:
+		return &network.PropertyNetworkMapper{Property: network.AppIDProperty, DefaultNetworkID: mapping.DefaultNetwork}
+	case config.OrgPolicy:
+		return &network.PropertyNetworkMapper{Property: network.OrgIDProperty, DefaultNetworkID: mapping.DefaultNetwork}
+	case config.TablePolicy:
+		tableMapper := &network.TableNetworkMapper{
+			Apps:             mapping.Apps,
+			Spaces:           mapping.Spaces,
+			DefaultNetworkID: mapping.DefaultNetwork,
+		}
+		if mapping.UseDatastore {
+			tableMapper.Store = mappingStore
+		}
+		return tableMapper
+	case config.ExecPolicy:
+		return &network.ExecNetworkMapper{
+			Executable: mapping.Executable,
+			Runner:     network.ExecRunner{},
+			Registry:   vniRegistry,
+			Timeout:    time.Duration(mapping.ExecTimeout) * time.Second,
+			Clock:      clock.NewClock(),
+			CacheTTL:   time.Duration(mapping.ExecCacheTTL) * time.Second,
+		}
+	default:
+		return &network.FixedNetworkMapper{DefaultNetworkID: mapping.DefaultNetwork}
+	}
+}
+
+func newEgress(egress config.ValidatedEgress) container.Egress {
+	networks := map[string]container.NetworkEgress{}
+	for networkID, n := range egress.Networks {
+		networks[networkID] = container.NetworkEgress{
+			Enabled: n.Enabled,
+			Deny:    n.Deny,
+		}
+	}
+
+	return container.Egress{
+		Enabled:    egress.Enabled,
+		LinkSubnet: egress.LinkSubnet,
+		Deny:       egress.Deny,
+		Networks:   networks,
+	}
+}
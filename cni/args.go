@@ -0,0 +1,39 @@
+package cni
+
+import (
+	"net"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+var InvalidArgsError = models.InvalidArgsError
+
+// requestedIP returns the address named by the IP arg, if any. Args follow
+// the CNI convention of semicolon separated KEY=VALUE pairs; keys other than
+// IP are ignored.
+func requestedIP(args string) (net.IP, error) {
+	var ip net.IP
+
+	for _, pair := range strings.Split(args, ";") {
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, InvalidArgsError
+		}
+
+		if kv[0] != "IP" {
+			continue
+		}
+
+		ip = net.ParseIP(kv[1])
+		if ip == nil {
+			return nil, InvalidArgsError
+		}
+	}
+
+	return ip, nil
+}
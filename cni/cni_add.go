@@ -2,6 +2,7 @@ package cni
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/appc/cni/pkg/types"
 	"github.com/cloudfoundry-incubator/ducati-daemon/container"
@@ -11,11 +12,23 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/store"
 )
 
+var ContainerConflictError = models.ContainerConflictError
+var UndefinedNetworkError = models.UndefinedNetworkError
+
+// AddController attaches containers to the network their payload maps to.
+// The network's definition, when it has one, supplies the MTU and DNS
+// settings; with StrictNetworks set, networks without a definition are
+// refused.
 type AddController struct {
-	IPAllocator   ipam.IPAllocator
-	NetworkMapper network.NetworkMapper
-	Creator       creator
-	Datastore     store.Store
+	IPAllocator    ipam.IPAllocator
+	NetworkMapper  network.NetworkMapper
+	Creator        creator
+	Deletor        deletor
+	Datastore      store.Store
+	Networks       store.NetworkStore
+	StrictNetworks bool
+	ResolverCache  cacheInvalidator
+	Locker         containerLocker
 }
 
 //go:generate counterfeiter -o ../fakes/creator.go --fake-name Creator . creator
@@ -23,22 +36,65 @@ type creator interface {
 	Setup(container.CreatorConfig) (models.Container, error)
 }
 
+// Add is idempotent: when the container is already attached through the same
+// namespace and interface, the result of the original ADD is returned and
+// nothing is set up again.
 func (c *AddController) Add(payload models.CNIAddPayload) (*types.Result, error) {
+	err := c.Locker.Lock(payload.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Locker.Unlock(payload.ContainerID)
+
+	existing, err := c.Datastore.Get(payload.ContainerID)
+	switch err {
+	case nil:
+		return existingResult(existing, payload)
+	case store.RecordNotFoundError:
+	default:
+		return nil, fmt.Errorf("datastore get: %s", err)
+	}
+
+	staticIP, err := requestedIP(payload.Args)
+	if err != nil {
+		return nil, err
+	}
+
 	networkID, err := c.NetworkMapper.GetNetworkID(payload.Network)
 	if err != nil {
 		return nil, fmt.Errorf("get network id: %s", err)
 	}
 
+	definition, err := c.Networks.Get(networkID)
+	switch err {
+	case nil:
+	case store.RecordNotFoundError:
+		if c.StrictNetworks {
+			return nil, UndefinedNetworkError
+		}
+	default:
+		return nil, fmt.Errorf("get network definition: %s", err)
+	}
+
 	vni, err := c.NetworkMapper.GetVNI(networkID)
 	if err != nil {
 		return nil, fmt.Errorf("get vni: %s", err)
 	}
 
-	ipamResult, err := c.IPAllocator.AllocateIP(networkID, payload.ContainerID)
+	var ipamResult *types.Result
+	if staticIP != nil {
+		ipamResult, err = c.IPAllocator.AllocateStaticIP(networkID, payload.ContainerID, staticIP)
+	} else {
+		ipamResult, err = c.IPAllocator.AllocateIP(networkID, payload.ContainerID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if !isEmptyDNS(definition.DNS.DNS) {
+		ipamResult.DNS = definition.DNS.DNS
+	}
+
 	containerConfig := container.CreatorConfig{
 		NetworkID:       networkID,
 		App:             payload.Network.Properties.AppID,
@@ -46,18 +102,65 @@ func (c *AddController) Add(payload models.CNIAddPayload) (*types.Result, error)
 		ContainerID:     payload.ContainerID,
 		InterfaceName:   payload.InterfaceName,
 		VNI:             vni,
+		MTU:             definition.MTU,
 		IPAMResult:      ipamResult,
 	}
 
 	container, err := c.Creator.Setup(containerConfig)
 	if err != nil {
-		return nil, fmt.Errorf("container setup: %s", err)
+		return nil, c.rollback(payload, networkID, vni, fmt.Errorf("container setup: %s", err))
 	}
 
 	err = c.Datastore.Create(container)
 	if err != nil {
-		return nil, fmt.Errorf("datastore create: %s", err)
+		return nil, c.rollback(payload, networkID, vni, fmt.Errorf("datastore create: %s", err))
+	}
+
+	c.ResolverCache.Invalidate(container.SandboxName, container.IP)
+	if container.IP6 != "" {
+		c.ResolverCache.Invalidate(container.SandboxName, container.IP6)
 	}
 
 	return ipamResult, nil
 }
+
+func isEmptyDNS(dns types.DNS) bool {
+	return len(dns.Nameservers) == 0 && dns.Domain == "" && len(dns.Search) == 0 && len(dns.Options) == 0
+}
+
+func existingResult(existing models.Container, payload models.CNIAddPayload) (*types.Result, error) {
+	if existing.ContainerNamespace != payload.ContainerNamespace ||
+		existing.InterfaceName != payload.InterfaceName ||
+		existing.IPAMResult == nil {
+		return nil, ContainerConflictError
+	}
+
+	result := existing.IPAMResult.Result
+	return &result, nil
+}
+
+// rollback undoes everything Add did after the IP was allocated so that a
+// failed ADD leaves the host as it found it. The original error is returned,
+// annotated with any failure to clean up.
+func (c *AddController) rollback(payload models.CNIAddPayload, networkID string, vni int, cause error) error {
+	sandboxName := fmt.Sprintf("vni-%d", vni)
+	vxlanDeviceName := fmt.Sprintf("vxlan%d", vni)
+
+	var failures []string
+
+	err := c.Deletor.Delete(payload.InterfaceName, payload.ContainerNamespace, sandboxName, vxlanDeviceName)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("deletor: %s", err))
+	}
+
+	err = c.IPAllocator.ReleaseIP(networkID, payload.ContainerID)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("release ip: %s", err))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s (rollback failed: %s)", cause, strings.Join(failures, ", "))
+	}
+
+	return cause
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/container"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -16,26 +18,38 @@ import (
 var _ = Describe("CniAdd", func() {
 	var (
 		datastore     *fakes.Store
+		networks      *fakes.NetworkStore
 		ipamResult    *types.Result
 		creator       *fakes.Creator
+		deletor       *fakes.Deletor
 		controller    *cni.AddController
 		ipAllocator   *fakes.IPAllocator
 		networkMapper *fakes.NetworkMapper
+		resolverCache *fakes.CacheInvalidator
+		locker        *fakes.ContainerLocker
 		payload       models.CNIAddPayload
 	)
 
 	BeforeEach(func() {
 		datastore = &fakes.Store{}
+		networks = &fakes.NetworkStore{}
 		creator = &fakes.Creator{}
+		deletor = &fakes.Deletor{}
 
 		ipAllocator = &fakes.IPAllocator{}
 		networkMapper = &fakes.NetworkMapper{}
+		resolverCache = &fakes.CacheInvalidator{}
+		locker = &fakes.ContainerLocker{}
 
 		controller = &cni.AddController{
 			Datastore:     datastore,
+			Networks:      networks,
 			Creator:       creator,
+			Deletor:       deletor,
 			IPAllocator:   ipAllocator,
 			NetworkMapper: networkMapper,
+			ResolverCache: resolverCache,
+			Locker:        locker,
 		}
 
 		ipamResult = &types.Result{
@@ -64,17 +78,20 @@ var _ = Describe("CniAdd", func() {
 		var err error
 		Expect(err).NotTo(HaveOccurred())
 
+		datastore.GetReturns(models.Container{}, store.RecordNotFoundError)
+		networks.GetReturns(models.Network{}, store.RecordNotFoundError)
 		ipAllocator.AllocateIPReturns(ipamResult, nil)
 
 		networkMapper.GetVNIReturns(99, nil)
 		networkMapper.GetNetworkIDReturns("network-id-1", nil)
 		creator.SetupReturns(models.Container{
-			ID:        "container-id",
-			NetworkID: "network-id-1",
-			App:       "app-id-1",
-			MAC:       "00:00:00:00:00",
-			HostIP:    "10.12.100.4",
-			IP:        "192.168.160.3",
+			ID:          "container-id",
+			NetworkID:   "network-id-1",
+			App:         "app-id-1",
+			MAC:         "00:00:00:00:00",
+			HostIP:      "10.12.100.4",
+			IP:          "192.168.160.3",
+			SandboxName: "vni-99",
 		}, nil)
 
 		payload = models.CNIAddPayload{
@@ -90,6 +107,36 @@ var _ = Describe("CniAdd", func() {
 		}
 	})
 
+	It("holds the container lock for the duration of the operation", func() {
+		datastore.CreateStub = func(models.Container) error {
+			Expect(locker.LockCallCount()).To(Equal(1))
+			Expect(locker.UnlockCallCount()).To(Equal(0))
+			return nil
+		}
+
+		_, err := controller.Add(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(locker.LockCallCount()).To(Equal(1))
+		Expect(locker.LockArgsForCall(0)).To(Equal("container-id"))
+		Expect(locker.UnlockCallCount()).To(Equal(1))
+		Expect(locker.UnlockArgsForCall(0)).To(Equal("container-id"))
+	})
+
+	Context("when the container lock cannot be taken", func() {
+		BeforeEach(func() {
+			locker.LockReturns(cni.LockTimeoutError)
+		})
+
+		It("returns the error without touching anything", func() {
+			_, err := controller.Add(payload)
+			Expect(err).To(Equal(cni.LockTimeoutError))
+
+			Expect(datastore.GetCallCount()).To(Equal(0))
+			Expect(locker.UnlockCallCount()).To(Equal(0))
+		})
+	})
+
 	It("sets up the container network", func() {
 		_, err := controller.Add(payload)
 		Expect(err).NotTo(HaveOccurred())
@@ -107,15 +154,101 @@ var _ = Describe("CniAdd", func() {
 
 		Expect(datastore.CreateCallCount()).To(Equal(1))
 		Expect(datastore.CreateArgsForCall(0)).To(Equal(models.Container{
-			ID:        "container-id",
-			NetworkID: "network-id-1",
-			App:       "app-id-1",
-			MAC:       "00:00:00:00:00",
-			HostIP:    "10.12.100.4",
-			IP:        "192.168.160.3",
+			ID:          "container-id",
+			NetworkID:   "network-id-1",
+			App:         "app-id-1",
+			MAC:         "00:00:00:00:00",
+			HostIP:      "10.12.100.4",
+			IP:          "192.168.160.3",
+			SandboxName: "vni-99",
 		}))
 	})
 
+	It("invalidates any cached resolution for the new container address", func() {
+		_, err := controller.Add(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolverCache.InvalidateCallCount()).To(Equal(1))
+		sandboxName, ip := resolverCache.InvalidateArgsForCall(0)
+		Expect(sandboxName).To(Equal("vni-99"))
+		Expect(ip).To(Equal("192.168.160.3"))
+	})
+
+	It("checks the datastore for an existing record of the container", func() {
+		_, err := controller.Add(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(datastore.GetCallCount()).To(Equal(1))
+		Expect(datastore.GetArgsForCall(0)).To(Equal("container-id"))
+	})
+
+	Context("when the container is already attached", func() {
+		var existing models.Container
+
+		BeforeEach(func() {
+			existing = models.Container{
+				ID:                 "container-id",
+				NetworkID:          "network-id-1",
+				IP:                 "192.168.100.2",
+				SandboxName:        "vni-99",
+				ContainerNamespace: "/some/namespace/path",
+				InterfaceName:      "interface-name",
+				IPAMResult:         &models.IPAMResult{Result: *ipamResult},
+			}
+			datastore.GetReturns(existing, nil)
+		})
+
+		It("returns the stored result without setting anything up", func() {
+			result, err := controller.Add(payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(ipamResult))
+
+			Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+			Expect(creator.SetupCallCount()).To(Equal(0))
+			Expect(datastore.CreateCallCount()).To(Equal(0))
+		})
+
+		Context("through a different namespace", func() {
+			BeforeEach(func() {
+				existing.ContainerNamespace = "/some/other/namespace/path"
+				datastore.GetReturns(existing, nil)
+			})
+
+			It("returns a conflict error and leaves the existing attachment alone", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(Equal(cni.ContainerConflictError))
+
+				Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+				Expect(creator.SetupCallCount()).To(Equal(0))
+				Expect(deletor.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("through a different interface", func() {
+			BeforeEach(func() {
+				existing.InterfaceName = "some-other-interface"
+				datastore.GetReturns(existing, nil)
+			})
+
+			It("returns a conflict error", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(Equal(cni.ContainerConflictError))
+			})
+		})
+	})
+
+	Context("when the datastore lookup fails", func() {
+		BeforeEach(func() {
+			datastore.GetReturns(models.Container{}, errors.New("pineapple"))
+		})
+
+		It("returns a wrapped error", func() {
+			_, err := controller.Add(payload)
+			Expect(err).To(MatchError("datastore get: pineapple"))
+			Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+		})
+	})
+
 	It("gets the networkID from the network mapper", func() {
 		_, err := controller.Add(payload)
 		Expect(err).NotTo(HaveOccurred())
@@ -135,6 +268,76 @@ var _ = Describe("CniAdd", func() {
 		})
 	})
 
+	It("looks up the definition of the network", func() {
+		_, err := controller.Add(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(networks.GetCallCount()).To(Equal(1))
+		Expect(networks.GetArgsForCall(0)).To(Equal("network-id-1"))
+	})
+
+	Context("when the network is defined", func() {
+		BeforeEach(func() {
+			networks.GetReturns(models.Network{
+				ID:  "network-id-1",
+				MTU: 1400,
+				DNS: models.NetworkDNS{DNS: types.DNS{
+					Nameservers: []string{"10.10.0.53"},
+					Search:      []string{"apps.internal"},
+				}},
+			}, nil)
+		})
+
+		It("sets up the container with the MTU of the network", func() {
+			_, err := controller.Add(payload)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(creator.SetupArgsForCall(0).MTU).To(Equal(1400))
+		})
+
+		It("returns the DNS settings of the network in the result", func() {
+			result, err := controller.Add(payload)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.DNS).To(Equal(types.DNS{
+				Nameservers: []string{"10.10.0.53"},
+				Search:      []string{"apps.internal"},
+			}))
+		})
+	})
+
+	Context("when strict mode is on", func() {
+		BeforeEach(func() {
+			controller.StrictNetworks = true
+		})
+
+		It("refuses networks without a definition before allocating", func() {
+			_, err := controller.Add(payload)
+			Expect(err).To(Equal(cni.UndefinedNetworkError))
+
+			Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+			Expect(creator.SetupCallCount()).To(Equal(0))
+		})
+
+		It("accepts defined networks", func() {
+			networks.GetReturns(models.Network{ID: "network-id-1"}, nil)
+
+			_, err := controller.Add(payload)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when looking up the network definition fails", func() {
+		BeforeEach(func() {
+			networks.GetReturns(models.Network{}, errors.New("turnip"))
+		})
+
+		It("returns a meaningful error", func() {
+			_, err := controller.Add(payload)
+			Expect(err).To(MatchError("get network definition: turnip"))
+		})
+	})
+
 	It("uses the network id to get the VNI", func() {
 		_, err := controller.Add(payload)
 		Expect(err).NotTo(HaveOccurred())
@@ -155,6 +358,63 @@ var _ = Describe("CniAdd", func() {
 		Expect(returnedIPAMResult).To(BeIdenticalTo(ipamResult))
 	})
 
+	Context("when the args request a static IP", func() {
+		BeforeEach(func() {
+			payload.Args = "FOO=BAR;IP=192.168.160.9;ABC=123"
+			ipAllocator.AllocateStaticIPReturns(ipamResult, nil)
+		})
+
+		It("reserves that address instead of allocating the next one", func() {
+			returnedIPAMResult, err := controller.Add(payload)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+			Expect(ipAllocator.AllocateStaticIPCallCount()).To(Equal(1))
+			networkID, containerID, ip := ipAllocator.AllocateStaticIPArgsForCall(0)
+			Expect(networkID).To(Equal("network-id-1"))
+			Expect(containerID).To(Equal("container-id"))
+			Expect(ip.Equal(net.ParseIP("192.168.160.9"))).To(BeTrue())
+
+			Expect(returnedIPAMResult).To(BeIdenticalTo(ipamResult))
+		})
+
+		Context("when the address cannot be reserved", func() {
+			BeforeEach(func() {
+				ipAllocator.AllocateStaticIPReturns(nil, ipam.AddressTakenError)
+			})
+
+			It("returns the error without wrapping it", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(Equal(ipam.AddressTakenError))
+				Expect(creator.SetupCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the IP is not an address", func() {
+			BeforeEach(func() {
+				payload.Args = "IP=banana"
+			})
+
+			It("returns an invalid args error before allocating", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(Equal(cni.InvalidArgsError))
+				Expect(ipAllocator.AllocateStaticIPCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when the args are not KEY=VALUE pairs", func() {
+		BeforeEach(func() {
+			payload.Args = "FOO=BAR;garbage"
+		})
+
+		It("returns an invalid args error before allocating", func() {
+			_, err := controller.Add(payload)
+			Expect(err).To(Equal(cni.InvalidArgsError))
+			Expect(ipAllocator.AllocateIPCallCount()).To(Equal(0))
+		})
+	})
+
 	Context("when getting the VNI fails", func() {
 		It("aborts and returns a wrapped error", func() {
 			networkMapper.GetVNIReturns(0, errors.New("some error"))
@@ -176,21 +436,111 @@ var _ = Describe("CniAdd", func() {
 		})
 	})
 
+	It("does not roll anything back when it succeeds", func() {
+		_, err := controller.Add(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deletor.DeleteCallCount()).To(Equal(0))
+		Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+	})
+
 	Context("when container creation fails", func() {
-		It("aborts and returns a wrapped error", func() {
+		BeforeEach(func() {
 			creator.SetupReturns(models.Container{}, errors.New("some error"))
+		})
+
+		It("aborts and returns a wrapped error", func() {
 			_, err := controller.Add(payload)
 
 			Expect(datastore.CreateCallCount()).To(BeZero())
 			Expect(err).To(MatchError("container setup: some error"))
 		})
+
+		It("removes the container link and cleans up the sandbox", func() {
+			controller.Add(payload)
+
+			Expect(deletor.DeleteCallCount()).To(Equal(1))
+			ifName, nsPath, sandboxName, vxlanName := deletor.DeleteArgsForCall(0)
+			Expect(ifName).To(Equal("interface-name"))
+			Expect(nsPath).To(Equal("/some/namespace/path"))
+			Expect(sandboxName).To(Equal("vni-99"))
+			Expect(vxlanName).To(Equal("vxlan99"))
+		})
+
+		It("releases the allocated IP", func() {
+			controller.Add(payload)
+
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(1))
+			networkID, containerID := ipAllocator.ReleaseIPArgsForCall(0)
+			Expect(networkID).To(Equal("network-id-1"))
+			Expect(containerID).To(Equal("container-id"))
+		})
+
+		Context("when the rollback fails", func() {
+			BeforeEach(func() {
+				deletor.DeleteReturns(errors.New("kiwi"))
+				ipAllocator.ReleaseIPReturns(errors.New("lime"))
+			})
+
+			It("attempts every step and reports the failures alongside the original error", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(MatchError("container setup: some error (rollback failed: deletor: kiwi, release ip: lime)"))
+
+				Expect(deletor.DeleteCallCount()).To(Equal(1))
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(1))
+			})
+		})
 	})
 
 	Context("when datastore create fails", func() {
-		It("returns a wrapped error", func() {
+		BeforeEach(func() {
 			datastore.CreateReturns(errors.New("some error"))
+		})
+
+		It("returns a wrapped error", func() {
 			_, err := controller.Add(payload)
 			Expect(err).To(MatchError("datastore create: some error"))
+			Expect(resolverCache.InvalidateCallCount()).To(Equal(0))
+		})
+
+		It("tears down the container network and releases the IP", func() {
+			controller.Add(payload)
+
+			Expect(deletor.DeleteCallCount()).To(Equal(1))
+			ifName, nsPath, sandboxName, vxlanName := deletor.DeleteArgsForCall(0)
+			Expect(ifName).To(Equal("interface-name"))
+			Expect(nsPath).To(Equal("/some/namespace/path"))
+			Expect(sandboxName).To(Equal("vni-99"))
+			Expect(vxlanName).To(Equal("vxlan99"))
+
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(1))
+			networkID, containerID := ipAllocator.ReleaseIPArgsForCall(0)
+			Expect(networkID).To(Equal("network-id-1"))
+			Expect(containerID).To(Equal("container-id"))
+		})
+
+		Context("when releasing the IP fails", func() {
+			BeforeEach(func() {
+				ipAllocator.ReleaseIPReturns(errors.New("lime"))
+			})
+
+			It("reports the failure alongside the original error", func() {
+				_, err := controller.Add(payload)
+				Expect(err).To(MatchError("datastore create: some error (rollback failed: release ip: lime)"))
+			})
+		})
+	})
+
+	Context("when the allocation fails", func() {
+		BeforeEach(func() {
+			ipAllocator.AllocateIPReturns(nil, errors.New("no addresses"))
+		})
+
+		It("has nothing to roll back", func() {
+			controller.Add(payload)
+
+			Expect(deletor.DeleteCallCount()).To(Equal(0))
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
 		})
 	})
 })
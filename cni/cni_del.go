@@ -9,6 +9,7 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/network"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ossupport"
 	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
 )
 
 //go:generate counterfeiter -o ../fakes/deletor.go --fake-name Deletor . deletor
@@ -16,20 +17,42 @@ type deletor interface {
 	Delete(interfaceName string, containerNSPath string, sandboxName string, vxlanDeviceName string) error
 }
 
+//go:generate counterfeiter -o ../fakes/cache_invalidator.go --fake-name CacheInvalidator . cacheInvalidator
+type cacheInvalidator interface {
+	Invalidate(sandboxName, ip string)
+}
+
 type repository interface {
 	Get(string) (namespace.Namespace, error)
 }
 
 type DelController struct {
+	Logger         lager.Logger
 	Datastore      store.Store
+	Reservations   store.ReservationIndex
 	Deletor        deletor
 	IPAllocator    ipam.IPAllocator
 	NetworkMapper  network.NetworkMapper
 	OSThreadLocker ossupport.OSThreadLocker
+	ResolverCache  cacheInvalidator
+	Locker         containerLocker
 }
 
+// Del is idempotent: the datastore record is removed last, so a retried DEL
+// redoes whatever a failed attempt left behind. Once the record is gone, any
+// addresses the container still holds are released and what remains of its
+// attachment is cleaned up on a best-effort basis.
 func (c *DelController) Del(payload models.CNIDelPayload) error {
+	err := c.Locker.Lock(payload.ContainerID)
+	if err != nil {
+		return err
+	}
+	defer c.Locker.Unlock(payload.ContainerID)
+
 	dbRecord, err := c.Datastore.Get(payload.ContainerID)
+	if err == store.RecordNotFoundError {
+		return c.delRemains(payload)
+	}
 	if err != nil {
 		return fmt.Errorf("datastore get: %s", err)
 	}
@@ -47,14 +70,58 @@ func (c *DelController) Del(payload models.CNIDelPayload) error {
 		return fmt.Errorf("deletor: %s", err)
 	}
 
-	err = c.Datastore.Delete(payload.ContainerID)
+	err = c.IPAllocator.ReleaseIP(dbRecord.NetworkID, payload.ContainerID)
 	if err != nil {
+		return fmt.Errorf("release ip: %s", err)
+	}
+
+	err = c.Datastore.Delete(payload.ContainerID)
+	if err != nil && err != store.RecordNotFoundError {
 		return fmt.Errorf("datastore delete: %s", err)
 	}
 
-	err = c.IPAllocator.ReleaseIP(dbRecord.NetworkID, payload.ContainerID)
+	c.ResolverCache.Invalidate(dbRecord.SandboxName, dbRecord.IP)
+	if dbRecord.IP6 != "" {
+		c.ResolverCache.Invalidate(dbRecord.SandboxName, dbRecord.IP6)
+	}
+
+	return nil
+}
+
+// delRemains cleans up after a container that has no record, such as one
+// whose ADD failed partway. The sandbox is found through the networks the
+// container still holds addresses on; failures to tear down the attachment
+// are logged rather than returned since there may be nothing left of it.
+func (c *DelController) delRemains(payload models.CNIDelPayload) error {
+	logger := c.Logger.Session("del-remains", lager.Data{"container_id": payload.ContainerID})
+
+	networkIDs, err := c.Reservations.Networks(payload.ContainerID)
 	if err != nil {
-		return fmt.Errorf("release ip: %s", err)
+		return fmt.Errorf("list reservations: %s", err)
+	}
+
+	for _, networkID := range networkIDs {
+		data := lager.Data{"network_id": networkID}
+
+		vni, err := c.NetworkMapper.GetVNI(networkID)
+		if err != nil {
+			logger.Error("get-vni-failed", err, data)
+		} else {
+			sandboxName := fmt.Sprintf("vni-%d", vni)
+			vxlanDeviceName := fmt.Sprintf("vxlan%d", vni)
+
+			err = c.Deletor.Delete(payload.InterfaceName, payload.ContainerNamespace, sandboxName, vxlanDeviceName)
+			if err != nil {
+				logger.Error("deletor-failed", err, data)
+			}
+		}
+
+		err = c.IPAllocator.ReleaseIP(networkID, payload.ContainerID)
+		if err != nil {
+			return fmt.Errorf("release ip: %s", err)
+		}
+
+		logger.Info("released", data)
 	}
 
 	return nil
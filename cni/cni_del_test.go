@@ -6,9 +6,12 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager/lagertest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("CniDel", func() {
@@ -18,6 +21,10 @@ var _ = Describe("CniDel", func() {
 		controller    *cni.DelController
 		ipAllocator   *fakes.IPAllocator
 		networkMapper *fakes.NetworkMapper
+		resolverCache *fakes.CacheInvalidator
+		locker        *fakes.ContainerLocker
+		reservations  *fakes.ReservationIndex
+		logger        *lagertest.TestLogger
 		payload       models.CNIDelPayload
 	)
 
@@ -26,17 +33,27 @@ var _ = Describe("CniDel", func() {
 		deletor = &fakes.Deletor{}
 		ipAllocator = &fakes.IPAllocator{}
 		networkMapper = &fakes.NetworkMapper{}
+		resolverCache = &fakes.CacheInvalidator{}
+		locker = &fakes.ContainerLocker{}
+		reservations = &fakes.ReservationIndex{}
+		logger = lagertest.NewTestLogger("test")
 
 		networkMapper.GetVNIReturns(42, nil)
 		datastore.GetReturns(models.Container{
-			NetworkID: "some-network-id",
+			NetworkID:   "some-network-id",
+			IP:          "192.168.1.2",
+			SandboxName: "vni-42",
 		}, nil)
 
 		controller = &cni.DelController{
+			Logger:        logger,
 			Datastore:     datastore,
+			Reservations:  reservations,
 			Deletor:       deletor,
 			IPAllocator:   ipAllocator,
 			NetworkMapper: networkMapper,
+			ResolverCache: resolverCache,
+			Locker:        locker,
 		}
 
 		payload = models.CNIDelPayload{
@@ -46,6 +63,36 @@ var _ = Describe("CniDel", func() {
 		}
 	})
 
+	It("holds the container lock for the duration of the operation", func() {
+		deletor.DeleteStub = func(string, string, string, string) error {
+			Expect(locker.LockCallCount()).To(Equal(1))
+			Expect(locker.UnlockCallCount()).To(Equal(0))
+			return nil
+		}
+
+		err := controller.Del(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(locker.LockCallCount()).To(Equal(1))
+		Expect(locker.LockArgsForCall(0)).To(Equal("some-container-id"))
+		Expect(locker.UnlockCallCount()).To(Equal(1))
+		Expect(locker.UnlockArgsForCall(0)).To(Equal("some-container-id"))
+	})
+
+	Context("when the container lock cannot be taken", func() {
+		BeforeEach(func() {
+			locker.LockReturns(cni.LockTimeoutError)
+		})
+
+		It("returns the error without touching anything", func() {
+			err := controller.Del(payload)
+			Expect(err).To(Equal(cni.LockTimeoutError))
+
+			Expect(datastore.GetCallCount()).To(Equal(0))
+			Expect(locker.UnlockCallCount()).To(Equal(0))
+		})
+	})
+
 	It("gets the network id from the datastore", func() {
 		err := controller.Del(payload)
 		Expect(err).NotTo(HaveOccurred())
@@ -55,6 +102,115 @@ var _ = Describe("CniDel", func() {
 		Expect(containerID).To(Equal("some-container-id"))
 	})
 
+	Context("when the record is already gone", func() {
+		BeforeEach(func() {
+			datastore.GetReturns(models.Container{}, store.RecordNotFoundError)
+		})
+
+		It("succeeds without doing anything when nothing remains", func() {
+			err := controller.Del(payload)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reservations.NetworksCallCount()).To(Equal(1))
+			Expect(reservations.NetworksArgsForCall(0)).To(Equal("some-container-id"))
+
+			Expect(deletor.DeleteCallCount()).To(Equal(0))
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+			Expect(datastore.DeleteCallCount()).To(Equal(0))
+		})
+
+		Context("when the container still holds addresses", func() {
+			BeforeEach(func() {
+				reservations.NetworksReturns([]string{"some-network-id", "some-other-network-id"}, nil)
+				networkMapper.GetVNIStub = func(networkID string) (int, error) {
+					if networkID == "some-network-id" {
+						return 42, nil
+					}
+					return 43, nil
+				}
+			})
+
+			It("cleans up the sandbox of each network", func() {
+				err := controller.Del(payload)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(deletor.DeleteCallCount()).To(Equal(2))
+
+				ifName, cnsPath, sbName, vxName := deletor.DeleteArgsForCall(0)
+				Expect(ifName).To(Equal("some-interface-name"))
+				Expect(cnsPath).To(Equal("/some/container/namespace/path"))
+				Expect(sbName).To(Equal("vni-42"))
+				Expect(vxName).To(Equal("vxlan42"))
+
+				_, _, sbName, vxName = deletor.DeleteArgsForCall(1)
+				Expect(sbName).To(Equal("vni-43"))
+				Expect(vxName).To(Equal("vxlan43"))
+			})
+
+			It("releases the addresses", func() {
+				err := controller.Del(payload)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(2))
+
+				networkID, containerID := ipAllocator.ReleaseIPArgsForCall(0)
+				Expect(networkID).To(Equal("some-network-id"))
+				Expect(containerID).To(Equal("some-container-id"))
+
+				networkID, _ = ipAllocator.ReleaseIPArgsForCall(1)
+				Expect(networkID).To(Equal("some-other-network-id"))
+			})
+
+			Context("when the cleanup fails", func() {
+				BeforeEach(func() {
+					networkMapper.GetVNIStub = nil
+					networkMapper.GetVNIReturns(0, errors.New("banana"))
+					deletor.DeleteReturns(errors.New("some-deletor-error"))
+				})
+
+				It("logs and still releases the addresses", func() {
+					err := controller.Del(payload)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(logger).To(gbytes.Say("get-vni-failed.*banana"))
+					Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(2))
+				})
+
+				It("logs deletor failures", func() {
+					networkMapper.GetVNIReturns(42, nil)
+
+					err := controller.Del(payload)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(logger).To(gbytes.Say("deletor-failed.*some-deletor-error"))
+					Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(2))
+				})
+			})
+
+			Context("when releasing fails", func() {
+				BeforeEach(func() {
+					ipAllocator.ReleaseIPReturns(errors.New("kiwi"))
+				})
+
+				It("returns a wrapped error", func() {
+					err := controller.Del(payload)
+					Expect(err).To(MatchError("release ip: kiwi"))
+				})
+			})
+		})
+
+		Context("when listing the reservations fails", func() {
+			BeforeEach(func() {
+				reservations.NetworksReturns(nil, errors.New("lime"))
+			})
+
+			It("returns a wrapped error", func() {
+				err := controller.Del(payload)
+				Expect(err).To(MatchError("list reservations: lime"))
+			})
+		})
+	})
+
 	Context("when getting the record from the datastore fails", func() {
 		BeforeEach(func() {
 			datastore.GetReturns(models.Container{}, errors.New("some error"))
@@ -132,9 +288,21 @@ var _ = Describe("CniDel", func() {
 		It("returns a wrapped error", func() {
 			err := controller.Del(payload)
 			Expect(err).To(MatchError("datastore delete: some-datastore-error"))
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(1))
+			Expect(resolverCache.InvalidateCallCount()).To(Equal(0))
 		})
 	})
 
+	It("invalidates any cached resolution for the container address", func() {
+		err := controller.Del(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resolverCache.InvalidateCallCount()).To(Equal(1))
+		sandboxName, ip := resolverCache.InvalidateArgsForCall(0)
+		Expect(sandboxName).To(Equal("vni-42"))
+		Expect(ip).To(Equal("192.168.1.2"))
+	})
+
 	It("releases the IP allocation", func() {
 		err := controller.Del(payload)
 		Expect(err).NotTo(HaveOccurred())
@@ -155,5 +323,22 @@ var _ = Describe("CniDel", func() {
 			err := controller.Del(payload)
 			Expect(err).To(MatchError("release ip: mango"))
 		})
+
+		It("keeps the record so that a retry can finish the job", func() {
+			controller.Del(payload)
+			Expect(datastore.DeleteCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the record is deleted concurrently", func() {
+		BeforeEach(func() {
+			datastore.DeleteReturns(store.RecordNotFoundError)
+		})
+
+		It("succeeds", func() {
+			err := controller.Del(payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolverCache.InvalidateCallCount()).To(Equal(1))
+		})
 	})
 })
@@ -0,0 +1,98 @@
+package cni
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/clock"
+)
+
+var LockTimeoutError = models.LockTimeoutError
+
+//go:generate counterfeiter -o ../fakes/container_locker.go --fake-name ContainerLocker . containerLocker
+type containerLocker interface {
+	Lock(containerID string) error
+	Unlock(containerID string)
+}
+
+type keyedLock struct {
+	held    chan struct{}
+	waiters int
+}
+
+// ContainerLocker serializes operations on the same container ID while
+// letting operations on different containers proceed in parallel. A caller
+// that cannot take the lock within Timeout gets a LockTimeoutError; a zero
+// Timeout waits indefinitely.
+type ContainerLocker struct {
+	Clock   clock.Clock
+	Timeout time.Duration
+
+	mutex sync.Mutex
+	locks map[string]*keyedLock
+}
+
+func (l *ContainerLocker) Lock(containerID string) error {
+	lock := l.reference(containerID)
+
+	select {
+	case lock.held <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.Timeout <= 0 {
+		lock.held <- struct{}{}
+		return nil
+	}
+
+	timer := l.Clock.NewTimer(l.Timeout)
+	defer timer.Stop()
+
+	select {
+	case lock.held <- struct{}{}:
+		return nil
+	case <-timer.C():
+		l.release(containerID)
+		return LockTimeoutError
+	}
+}
+
+func (l *ContainerLocker) Unlock(containerID string) {
+	l.mutex.Lock()
+	lock := l.locks[containerID]
+	l.mutex.Unlock()
+
+	<-lock.held
+	l.release(containerID)
+}
+
+func (l *ContainerLocker) reference(containerID string) *keyedLock {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.locks == nil {
+		l.locks = map[string]*keyedLock{}
+	}
+
+	lock, ok := l.locks[containerID]
+	if !ok {
+		lock = &keyedLock{held: make(chan struct{}, 1)}
+		l.locks[containerID] = lock
+	}
+	lock.waiters++
+
+	return lock
+}
+
+func (l *ContainerLocker) release(containerID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	lock := l.locks[containerID]
+	lock.waiters--
+	if lock.waiters == 0 {
+		delete(l.locks, containerID)
+	}
+}
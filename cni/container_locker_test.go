@@ -0,0 +1,98 @@
+package cni_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+)
+
+var _ = Describe("ContainerLocker", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		locker    *cni.ContainerLocker
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		locker = &cni.ContainerLocker{
+			Clock:   fakeClock,
+			Timeout: 5 * time.Second,
+		}
+	})
+
+	It("serializes operations on the same container", func() {
+		Expect(locker.Lock("some-container")).To(Succeed())
+
+		acquired := make(chan error)
+		go func() {
+			acquired <- locker.Lock("some-container")
+		}()
+		Consistently(acquired).ShouldNot(Receive())
+
+		locker.Unlock("some-container")
+		Eventually(acquired).Should(Receive(BeNil()))
+
+		locker.Unlock("some-container")
+	})
+
+	It("lets operations on different containers proceed in parallel", func() {
+		Expect(locker.Lock("some-container")).To(Succeed())
+		Expect(locker.Lock("some-other-container")).To(Succeed())
+
+		locker.Unlock("some-container")
+		locker.Unlock("some-other-container")
+	})
+
+	It("can be taken again once it has been released", func() {
+		Expect(locker.Lock("some-container")).To(Succeed())
+		locker.Unlock("some-container")
+
+		Expect(locker.Lock("some-container")).To(Succeed())
+		locker.Unlock("some-container")
+	})
+
+	Context("when the lock is not released within the timeout", func() {
+		It("returns a LockTimeoutError", func() {
+			Expect(locker.Lock("some-container")).To(Succeed())
+
+			acquired := make(chan error)
+			go func() {
+				acquired <- locker.Lock("some-container")
+			}()
+
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+			fakeClock.Increment(5 * time.Second)
+
+			Eventually(acquired).Should(Receive(Equal(cni.LockTimeoutError)))
+
+			locker.Unlock("some-container")
+			Expect(locker.Lock("some-container")).To(Succeed())
+			locker.Unlock("some-container")
+		})
+	})
+
+	Context("when the timeout is zero", func() {
+		BeforeEach(func() {
+			locker.Timeout = 0
+		})
+
+		It("waits for as long as it takes", func() {
+			Expect(locker.Lock("some-container")).To(Succeed())
+
+			acquired := make(chan error)
+			go func() {
+				acquired <- locker.Lock("some-container")
+			}()
+
+			fakeClock.Increment(time.Hour)
+			Consistently(acquired).ShouldNot(Receive())
+
+			locker.Unlock("some-container")
+			Eventually(acquired).Should(Receive(BeNil()))
+			locker.Unlock("some-container")
+		})
+	})
+})
@@ -9,6 +9,9 @@ import (
 	"net"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/appc/cni/pkg/types"
 )
 
 type Daemon struct {
@@ -16,6 +19,8 @@ type Daemon struct {
 	ListenPort        int       `json:"listen_port"`
 	LocalSubnet       string    `json:"local_subnet"`
 	OverlayNetwork    string    `json:"overlay_network"`
+	LocalSubnetV6     string    `json:"local_subnet_v6"`
+	OverlayNetworkV6  string    `json:"overlay_network_v6"`
 	SandboxDir        string    `json:"sandbox_dir"`
 	Database          db.Config `json:"database"`
 	Index             int       `json:"index"`
@@ -24,6 +29,110 @@ type Daemon struct {
 	ExternalDNSServer string    `json:"dns_server"`
 	Suffix            string    `json:"suffix"`
 	DebugAddress      string    `json:"debug_address"`
+
+	ResolverCacheTTL         int `json:"resolver_cache_ttl"`
+	ResolverNegativeCacheTTL int `json:"resolver_negative_cache_ttl"`
+	NeighborEvictionInterval int `json:"neighbor_eviction_interval"`
+	ContainerLockTimeout     int `json:"container_lock_timeout"`
+	IPQuarantine             int `json:"ip_quarantine"`
+	IPReapInterval           int `json:"ip_reap_interval"`
+	IPLeaseDuration          int `json:"ip_lease_duration"`
+	IPOrphanGracePeriod      int `json:"ip_orphan_grace_period"`
+	PolicySyncInterval       int `json:"policy_sync_interval"`
+
+	// ReservedAddresses are addresses or CIDR ranges, such as infrastructure
+	// VIPs, that are never handed out on any network. The overlay DNS address
+	// is always reserved.
+	ReservedAddresses []string `json:"reserved_addresses"`
+
+	NetworkIPAM map[string]NetworkIPAM `json:"network_ipam"`
+
+	NetworkMapping NetworkMapping `json:"network_mapping"`
+
+	// StrictNetworks refuses to attach containers to networks that have not
+	// been defined through the networks API.
+	StrictNetworks bool `json:"strict_networks"`
+
+	Egress Egress `json:"egress"`
+}
+
+// defaultIPOrphanGracePeriod is the number of seconds a reservation without a
+// container record is kept at startup when no "ip_orphan_grace_period" is
+// configured. It covers ADDs on other hosts that have reserved an address
+// but not yet written their record.
+const defaultIPOrphanGracePeriod = 300
+
+// defaultEgressLinkSubnet stays clear of 169.254.169.254, which clouds use
+// for their metadata service.
+const defaultEgressLinkSubnet = "169.254.0.0/17"
+
+// Egress gives containers a path to networks outside the overlay through
+// the host. Each sandbox is linked to the host with a /30 of LinkSubnet,
+// handed out by a per-host registry so that sandboxes never share one.
+// Deny lists addresses or CIDR ranges that containers may not reach. An
+// entry in Networks turns egress on or off for one network and adds to
+// Deny for it.
+type Egress struct {
+	Enabled    bool                     `json:"enabled"`
+	LinkSubnet string                   `json:"link_subnet"`
+	Deny       []string                 `json:"deny"`
+	Networks   map[string]NetworkEgress `json:"networks"`
+}
+
+// NetworkEgress overrides egress for a single network. A network without
+// "enabled" follows the top-level setting.
+type NetworkEgress struct {
+	Enabled *bool    `json:"enabled"`
+	Deny    []string `json:"deny"`
+}
+
+// Network mapping policies choose the isolation boundary: every container
+// with the same app, space or org shares a network, the network comes from
+// an explicit table of apps and spaces, or an external executable decides.
+const (
+	AppPolicy   = "app"
+	SpacePolicy = "space"
+	OrgPolicy   = "org"
+	TablePolicy = "table"
+	ExecPolicy  = "exec"
+)
+
+// defaultExecTimeout is the number of seconds the exec policy waits for the
+// executable when no "exec_timeout" is configured.
+const defaultExecTimeout = 5
+
+// NetworkMapping selects how CNI payloads are mapped to networks. Payloads
+// without the property the policy needs, or without an entry in the table,
+// are put on DefaultNetwork. The table policy looks up Apps before Spaces,
+// and the network_mapping table of the datastore when UseDatastore is set.
+// The exec policy runs Executable, waiting ExecTimeout seconds for it, and
+// caches its decisions for ExecCacheTTL seconds.
+type NetworkMapping struct {
+	Policy         string            `json:"policy"`
+	DefaultNetwork string            `json:"default_network"`
+	Apps           map[string]string `json:"apps"`
+	Spaces         map[string]string `json:"spaces"`
+	UseDatastore   bool              `json:"use_datastore"`
+	Executable     string            `json:"executable"`
+	ExecTimeout    int               `json:"exec_timeout"`
+	ExecCacheTTL   int               `json:"exec_cache_ttl"`
+}
+
+// NetworkIPAM overrides the address space for a single network. Subnets may
+// use ${index} like local_subnet. Exclusions are addresses or CIDR ranges
+// that are never handed out.
+type NetworkIPAM struct {
+	Subnet     string   `json:"subnet"`
+	Gateway    string   `json:"gateway"`
+	SubnetV6   string   `json:"subnet_v6"`
+	GatewayV6  string   `json:"gateway_v6"`
+	Routes     []Route  `json:"routes"`
+	Exclusions []string `json:"exclusions"`
+}
+
+type Route struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway"`
 }
 
 func Unmarshal(input io.Reader) (Daemon, error) {
@@ -53,6 +162,8 @@ type ValidatedConfig struct {
 	ListenAddress     string
 	OverlayNetwork    *net.IPNet
 	LocalSubnet       *net.IPNet
+	OverlayNetworkV6  *net.IPNet
+	LocalSubnetV6     *net.IPNet
 	DatabaseURL       string
 	SandboxRepoDir    string
 	HostAddress       net.IP
@@ -60,6 +171,43 @@ type ValidatedConfig struct {
 	ExternalDNSServer net.IP
 	Suffix            string
 	DebugAddress      string
+
+	ResolverCacheTTL         time.Duration
+	ResolverNegativeCacheTTL time.Duration
+	NeighborEvictionInterval time.Duration
+	ContainerLockTimeout     time.Duration
+	IPQuarantine             time.Duration
+	IPReapInterval           time.Duration
+	IPLeaseDuration          time.Duration
+	IPOrphanGracePeriod      time.Duration
+	PolicySyncInterval       time.Duration
+
+	ReservedAddresses []net.IPNet
+
+	NetworkIPAM map[string]ValidatedNetworkIPAM
+
+	NetworkMapping NetworkMapping
+	StrictNetworks bool
+
+	Egress ValidatedEgress
+}
+
+type ValidatedEgress struct {
+	Enabled    bool
+	LinkSubnet *net.IPNet
+	Deny       []net.IPNet
+	Networks   map[string]ValidatedNetworkEgress
+}
+
+type ValidatedNetworkEgress struct {
+	Enabled bool
+	Deny    []net.IPNet
+}
+
+type ValidatedNetworkIPAM struct {
+	IP4        *types.IPConfig
+	IP6        *types.IPConfig
+	Exclusions []net.IPNet
 }
 
 func (d Daemon) ParseAndValidate() (*ValidatedConfig, error) {
@@ -104,22 +252,49 @@ func (d Daemon) ParseAndValidate() (*ValidatedConfig, error) {
 		return nil, errors.New(`missing required config: "suffix"`)
 	}
 
-	interpolatedSubnet := strings.Replace(d.LocalSubnet, "${index}", fmt.Sprintf("%d", d.Index), -1)
-	startIP, subnet, err := net.ParseCIDR(interpolatedSubnet)
+	localSubnet, err := d.parseLocalSubnet(d.LocalSubnet)
 	if err != nil {
 		return nil, fmt.Errorf(`bad config "local_subnet": %s`, err)
 	}
 
-	localSubnet := &net.IPNet{
-		IP:   startIP,
-		Mask: subnet.Mask,
-	}
-
 	_, overlay, err := net.ParseCIDR(d.OverlayNetwork)
 	if err != nil {
 		return nil, fmt.Errorf(`bad config "overlay_network": %s`, err)
 	}
 
+	var localSubnetV6, overlayV6 *net.IPNet
+	if d.LocalSubnetV6 != "" || d.OverlayNetworkV6 != "" {
+		if d.LocalSubnetV6 == "" {
+			return nil, errors.New(`missing required config: "local_subnet_v6"`)
+		}
+
+		if d.OverlayNetworkV6 == "" {
+			return nil, errors.New(`missing required config: "overlay_network_v6"`)
+		}
+
+		localSubnetV6, err = d.parseLocalSubnet(d.LocalSubnetV6)
+		if err != nil {
+			return nil, fmt.Errorf(`bad config "local_subnet_v6": %s`, err)
+		}
+
+		if localSubnetV6.IP.To4() != nil {
+			return nil, errors.New(`bad config "local_subnet_v6": not an IPv6 subnet`)
+		}
+
+		_, overlayV6, err = net.ParseCIDR(d.OverlayNetworkV6)
+		if err != nil {
+			return nil, fmt.Errorf(`bad config "overlay_network_v6": %s`, err)
+		}
+
+		if overlayV6.IP.To4() != nil {
+			return nil, errors.New(`bad config "overlay_network_v6": not an IPv6 network`)
+		}
+
+		if !overlayV6.Contains(localSubnetV6.IP) {
+			return nil, errors.New(`bad config "local_subnet_v6": not in overlay network`)
+		}
+	}
+
 	overlayDNSAddress := net.ParseIP(d.OverlayDNSAddress)
 	if overlayDNSAddress == nil {
 		return nil, fmt.Errorf(`bad config "overlay_dns_address": %s is not an IP address`, d.OverlayDNSAddress)
@@ -142,10 +317,89 @@ func (d Daemon) ParseAndValidate() (*ValidatedConfig, error) {
 		return nil, fmt.Errorf(`bad config "host_address": must be nonzero`)
 	}
 
+	if d.ResolverCacheTTL < 0 {
+		return nil, errors.New(`bad config "resolver_cache_ttl": must not be negative`)
+	}
+
+	if d.ResolverNegativeCacheTTL < 0 {
+		return nil, errors.New(`bad config "resolver_negative_cache_ttl": must not be negative`)
+	}
+
+	if d.NeighborEvictionInterval < 0 {
+		return nil, errors.New(`bad config "neighbor_eviction_interval": must not be negative`)
+	}
+
+	if d.ContainerLockTimeout < 0 {
+		return nil, errors.New(`bad config "container_lock_timeout": must not be negative`)
+	}
+
+	if d.IPQuarantine < 0 {
+		return nil, errors.New(`bad config "ip_quarantine": must not be negative`)
+	}
+
+	if d.IPReapInterval < 0 {
+		return nil, errors.New(`bad config "ip_reap_interval": must not be negative`)
+	}
+
+	if d.IPLeaseDuration < 0 {
+		return nil, errors.New(`bad config "ip_lease_duration": must not be negative`)
+	}
+
+	if d.IPReapInterval > 0 && d.IPLeaseDuration <= d.IPReapInterval {
+		return nil, errors.New(`bad config "ip_lease_duration": must be longer than "ip_reap_interval" when reaping is enabled`)
+	}
+
+	if d.IPOrphanGracePeriod < 0 {
+		return nil, errors.New(`bad config "ip_orphan_grace_period": must not be negative`)
+	}
+
+	ipOrphanGracePeriod := d.IPOrphanGracePeriod
+	if ipOrphanGracePeriod == 0 {
+		ipOrphanGracePeriod = defaultIPOrphanGracePeriod
+	}
+
+	if d.PolicySyncInterval < 0 {
+		return nil, errors.New(`bad config "policy_sync_interval": must not be negative`)
+	}
+
+	reservedAddresses := []net.IPNet{hostNetwork(overlayDNSAddress)}
+	for _, r := range d.ReservedAddresses {
+		reserved, err := parseExclusion(r)
+		if err != nil {
+			return nil, fmt.Errorf(`bad config "reserved_addresses": %s`, err)
+		}
+		reservedAddresses = append(reservedAddresses, reserved)
+	}
+
+	var networkIPAM map[string]ValidatedNetworkIPAM
+	for networkID, n := range d.NetworkIPAM {
+		validated, err := d.parseNetworkIPAM(n)
+		if err != nil {
+			return nil, fmt.Errorf(`bad config "network_ipam" for network %q: %s`, networkID, err)
+		}
+
+		if networkIPAM == nil {
+			networkIPAM = map[string]ValidatedNetworkIPAM{}
+		}
+		networkIPAM[networkID] = validated
+	}
+
+	networkMapping, err := parseNetworkMapping(d.NetworkMapping)
+	if err != nil {
+		return nil, fmt.Errorf(`bad config "network_mapping": %s`, err)
+	}
+
+	egress, err := parseEgress(d.Egress)
+	if err != nil {
+		return nil, fmt.Errorf(`bad config "egress": %s`, err)
+	}
+
 	return &ValidatedConfig{
 		ListenAddress:     fmt.Sprintf("%s:%d", d.ListenHost, d.ListenPort),
 		OverlayNetwork:    overlay,
 		LocalSubnet:       localSubnet,
+		OverlayNetworkV6:  overlayV6,
+		LocalSubnetV6:     localSubnetV6,
 		DatabaseURL:       dbURL,
 		SandboxRepoDir:    d.SandboxDir,
 		HostAddress:       hostAddress,
@@ -153,6 +407,247 @@ func (d Daemon) ParseAndValidate() (*ValidatedConfig, error) {
 		ExternalDNSServer: externalDNSServer,
 		Suffix:            d.Suffix,
 		DebugAddress:      d.DebugAddress,
+
+		ResolverCacheTTL:         time.Duration(d.ResolverCacheTTL) * time.Second,
+		ResolverNegativeCacheTTL: time.Duration(d.ResolverNegativeCacheTTL) * time.Second,
+		NeighborEvictionInterval: time.Duration(d.NeighborEvictionInterval) * time.Second,
+		ContainerLockTimeout:     time.Duration(d.ContainerLockTimeout) * time.Second,
+		IPQuarantine:             time.Duration(d.IPQuarantine) * time.Second,
+		IPReapInterval:           time.Duration(d.IPReapInterval) * time.Second,
+		IPLeaseDuration:          time.Duration(d.IPLeaseDuration) * time.Second,
+		IPOrphanGracePeriod:      time.Duration(ipOrphanGracePeriod) * time.Second,
+		PolicySyncInterval:       time.Duration(d.PolicySyncInterval) * time.Second,
+
+		ReservedAddresses: reservedAddresses,
+
+		NetworkIPAM: networkIPAM,
+
+		NetworkMapping: networkMapping,
+		StrictNetworks: d.StrictNetworks,
+
+		Egress: egress,
+	}, nil
+}
+
+func parseEgress(e Egress) (ValidatedEgress, error) {
+	validated := ValidatedEgress{Enabled: e.Enabled}
+
+	linkSubnet := e.LinkSubnet
+	if linkSubnet == "" {
+		linkSubnet = defaultEgressLinkSubnet
+	}
+
+	_, subnet, err := net.ParseCIDR(linkSubnet)
+	if err != nil {
+		return validated, fmt.Errorf(`"link_subnet": %s`, err)
+	}
+	if subnet.IP.To4() == nil {
+		return validated, errors.New(`"link_subnet": not an IPv4 subnet`)
+	}
+	if ones, _ := subnet.Mask.Size(); ones > 30 {
+		return validated, errors.New(`"link_subnet": must be a /30 or larger`)
+	}
+	validated.LinkSubnet = subnet
+
+	validated.Deny, err = parseExclusions(e.Deny)
+	if err != nil {
+		return validated, fmt.Errorf(`"deny": %s`, err)
+	}
+
+	for networkID, n := range e.Networks {
+		network := ValidatedNetworkEgress{Enabled: e.Enabled}
+		if n.Enabled != nil {
+			network.Enabled = *n.Enabled
+		}
+
+		network.Deny, err = parseExclusions(n.Deny)
+		if err != nil {
+			return validated, fmt.Errorf(`"networks" for network %q: "deny": %s`, networkID, err)
+		}
+
+		if validated.Networks == nil {
+			validated.Networks = map[string]ValidatedNetworkEgress{}
+		}
+		validated.Networks[networkID] = network
+	}
+
+	return validated, nil
+}
+
+func parseExclusions(exclusions []string) ([]net.IPNet, error) {
+	var parsed []net.IPNet
+	for _, e := range exclusions {
+		exclusion, err := parseExclusion(e)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, exclusion)
+	}
+
+	return parsed, nil
+}
+
+func parseNetworkMapping(m NetworkMapping) (NetworkMapping, error) {
+	if m.Policy == "" {
+		m.Policy = SpacePolicy
+	}
+
+	if m.DefaultNetwork == "" {
+		m.DefaultNetwork = "default"
+	}
+
+	if m.Policy != TablePolicy && (len(m.Apps) > 0 || len(m.Spaces) > 0 || m.UseDatastore) {
+		return m, fmt.Errorf(`"apps", "spaces" and "use_datastore" need the %q policy`, TablePolicy)
+	}
+
+	if m.Policy != ExecPolicy && (m.Executable != "" || m.ExecTimeout != 0 || m.ExecCacheTTL != 0) {
+		return m, fmt.Errorf(`"executable", "exec_timeout" and "exec_cache_ttl" need the %q policy`, ExecPolicy)
+	}
+
+	switch m.Policy {
+	case AppPolicy, SpacePolicy, OrgPolicy:
+	case TablePolicy:
+		if len(m.Apps) == 0 && len(m.Spaces) == 0 && !m.UseDatastore {
+			return m, fmt.Errorf(`the %q policy needs "apps", "spaces" or "use_datastore"`, TablePolicy)
+		}
+	case ExecPolicy:
+		if m.Executable == "" {
+			return m, fmt.Errorf(`the %q policy needs "executable"`, ExecPolicy)
+		}
+		if m.ExecTimeout < 0 {
+			return m, errors.New(`"exec_timeout" must not be negative`)
+		}
+		if m.ExecCacheTTL < 0 {
+			return m, errors.New(`"exec_cache_ttl" must not be negative`)
+		}
+		if m.ExecTimeout == 0 {
+			m.ExecTimeout = defaultExecTimeout
+		}
+	default:
+		return m, fmt.Errorf("unknown policy %q", m.Policy)
+	}
+
+	return m, nil
+}
+
+func (d Daemon) parseNetworkIPAM(n NetworkIPAM) (ValidatedNetworkIPAM, error) {
+	var validated ValidatedNetworkIPAM
+
+	if n.Subnet == "" {
+		return validated, errors.New(`missing required config: "subnet"`)
+	}
+
+	ip4, err := d.parseIPConfig(n.Subnet, n.Gateway)
+	if err != nil {
+		return validated, fmt.Errorf(`"subnet": %s`, err)
+	}
+	if ip4.IP.IP.To4() == nil {
+		return validated, errors.New(`"subnet": not an IPv4 subnet`)
+	}
+	validated.IP4 = ip4
+
+	if n.SubnetV6 != "" {
+		ip6, err := d.parseIPConfig(n.SubnetV6, n.GatewayV6)
+		if err != nil {
+			return validated, fmt.Errorf(`"subnet_v6": %s`, err)
+		}
+		if ip6.IP.IP.To4() != nil {
+			return validated, errors.New(`"subnet_v6": not an IPv6 subnet`)
+		}
+		validated.IP6 = ip6
+	}
+
+	for _, r := range n.Routes {
+		_, dst, err := net.ParseCIDR(r.Destination)
+		if err != nil {
+			return validated, fmt.Errorf(`"routes": %s`, err)
+		}
+
+		route := types.Route{Dst: *dst}
+		if r.Gateway != "" {
+			route.GW = net.ParseIP(r.Gateway)
+			if route.GW == nil {
+				return validated, fmt.Errorf(`"routes": %s is not an IP address`, r.Gateway)
+			}
+		}
+
+		if dst.IP.To4() != nil {
+			validated.IP4.Routes = append(validated.IP4.Routes, route)
+		} else if validated.IP6 != nil {
+			validated.IP6.Routes = append(validated.IP6.Routes, route)
+		} else {
+			return validated, fmt.Errorf(`"routes": %s is IPv6 but there is no "subnet_v6"`, r.Destination)
+		}
+	}
+
+	for _, e := range n.Exclusions {
+		exclusion, err := parseExclusion(e)
+		if err != nil {
+			return validated, fmt.Errorf(`"exclusions": %s`, err)
+		}
+		validated.Exclusions = append(validated.Exclusions, exclusion)
+	}
+
+	return validated, nil
+}
+
+func (d Daemon) parseIPConfig(subnet, gateway string) (*types.IPConfig, error) {
+	ipNet, err := d.parseLocalSubnet(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &types.IPConfig{IP: *ipNet}
+	if gateway != "" {
+		config.Gateway = net.ParseIP(gateway)
+		if config.Gateway == nil {
+			return nil, fmt.Errorf("gateway %s is not an IP address", gateway)
+		}
+		if !ipNet.Contains(config.Gateway) {
+			return nil, fmt.Errorf("gateway %s is not in the subnet", gateway)
+		}
+	}
+
+	return config, nil
+}
+
+// parseExclusion accepts either a single address or a CIDR range.
+func parseExclusion(exclusion string) (net.IPNet, error) {
+	if strings.Contains(exclusion, "/") {
+		_, ipNet, err := net.ParseCIDR(exclusion)
+		if err != nil {
+			return net.IPNet{}, err
+		}
+		return *ipNet, nil
+	}
+
+	ip := net.ParseIP(exclusion)
+	if ip == nil {
+		return net.IPNet{}, fmt.Errorf("%s is not an IP address", exclusion)
+	}
+
+	return hostNetwork(ip), nil
+}
+
+func hostNetwork(ip net.IP) net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// parseLocalSubnet interpolates the instance index into the subnet and keeps
+// the unmasked address, which is where allocation starts.
+func (d Daemon) parseLocalSubnet(subnet string) (*net.IPNet, error) {
+	interpolatedSubnet := strings.Replace(subnet, "${index}", fmt.Sprintf("%d", d.Index), -1)
+	startIP, ipNet, err := net.ParseCIDR(interpolatedSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &net.IPNet{
+		IP:   startIP,
+		Mask: ipNet.Mask,
 	}, nil
 }
 
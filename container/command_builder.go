@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor/conditions"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/links"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
 	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
 )
@@ -14,6 +15,7 @@ import (
 type CommandBuilder struct {
 	MissWatcher   watcher.MissWatcher
 	HostNamespace namespace.Namespace
+	Egress        Egress
 }
 
 func (b *CommandBuilder) IdempotentlyCreateSandbox(sandboxName, vxlanName string, vni int, dnsAddress string) executor.Command {
@@ -82,39 +84,50 @@ func (b *CommandBuilder) AddRoutes(interfaceName string, ipConfig *types.IPConfi
 	return commands.All(routeCommands...)
 }
 
+// SetupVeth creates the container veth with the given MTU, or with the
+// overlay MTU when it is zero.
 func (b *CommandBuilder) SetupVeth(
 	containerNS namespace.Namespace,
 	sandboxLinkName string,
 	containerLinkName string,
-	address net.IPNet,
+	mtu int,
+	ipamResult *types.Result,
 	sandboxName string,
 	routeCommand executor.Command,
 ) executor.Command {
+	if mtu == 0 {
+		mtu = links.VxlanVethMTU
+	}
+
+	setupCommands := []executor.Command{
+		commands.CreateVeth{
+			Name:     containerLinkName,
+			PeerName: sandboxLinkName,
+			MTU:      mtu,
+		},
+		commands.MoveLink{
+			Name:        sandboxLinkName,
+			SandboxName: sandboxName,
+		},
+	}
+
+	for _, ipConfig := range ipConfigs(ipamResult) {
+		setupCommands = append(setupCommands, commands.AddAddress{
+			InterfaceName: containerLinkName,
+			Address:       ipConfig.IP,
+		})
+	}
+
+	setupCommands = append(setupCommands,
+		commands.SetLinkUp{
+			LinkName: containerLinkName,
+		},
+		routeCommand,
+	)
+
 	return commands.InNamespace{
 		Namespace: containerNS,
-		Command: commands.Group(
-			append(
-				[]executor.Command{
-					commands.CreateVeth{
-						Name:     containerLinkName,
-						PeerName: sandboxLinkName,
-						MTU:      1450,
-					},
-					commands.MoveLink{
-						Name:        sandboxLinkName,
-						SandboxName: sandboxName,
-					},
-					commands.AddAddress{
-						InterfaceName: containerLinkName,
-						Address:       address,
-					},
-					commands.SetLinkUp{
-						LinkName: containerLinkName,
-					},
-				},
-				routeCommand,
-			),
-		),
+		Command:   commands.Group(setupCommands),
 	}
 }
 
@@ -123,6 +136,32 @@ func (b *CommandBuilder) IdempotentlySetupBridge(
 	sandboxNS namespace.Namespace,
 	ipamResult *types.Result,
 ) executor.Command {
+	bridgeCommands := []executor.Command{
+		commands.CreateBridge{
+			Name: bridgeName,
+		},
+	}
+
+	for _, ipConfig := range ipConfigs(ipamResult) {
+		bridgeCommands = append(bridgeCommands, commands.AddAddress{
+			InterfaceName: bridgeName,
+			Address: net.IPNet{
+				IP:   ipConfig.Gateway,
+				Mask: ipConfig.IP.Mask,
+			},
+		})
+	}
+
+	bridgeCommands = append(bridgeCommands,
+		commands.SetLinkUp{
+			LinkName: bridgeName,
+		},
+		commands.SetLinkMaster{
+			Master: bridgeName,
+			Slave:  commands.DNS_INTERFACE_NAME,
+		},
+	)
+
 	return commands.InNamespace{
 		Namespace: sandboxNS,
 		Command: commands.All(
@@ -133,25 +172,7 @@ func (b *CommandBuilder) IdempotentlySetupBridge(
 				Condition: conditions.LinkExists{
 					Name: bridgeName,
 				},
-				Command: commands.All(
-					commands.CreateBridge{
-						Name: bridgeName,
-					},
-					commands.AddAddress{
-						InterfaceName: bridgeName,
-						Address: net.IPNet{
-							IP:   ipamResult.IP4.Gateway,
-							Mask: ipamResult.IP4.IP.Mask,
-						},
-					},
-					commands.SetLinkUp{
-						LinkName: bridgeName,
-					},
-					commands.SetLinkMaster{
-						Master: bridgeName,
-						Slave:  commands.DNS_INTERFACE_NAME,
-					},
-				),
+				Command: commands.All(bridgeCommands...),
 			},
 			commands.SetLinkMaster{
 				Master: bridgeName,
@@ -164,3 +185,128 @@ func (b *CommandBuilder) IdempotentlySetupBridge(
 		),
 	}
 }
+
+// SetupEgress links the sandbox to the host over the egress link with the
+// given index the first time a container of the network is set up, makes
+// sure the host forwards and masquerades traffic from the egress links,
+// reprograms the denied destinations of the sandbox and gives the container
+// a default route through the bridge. It does nothing when egress is
+// disabled for the network.
+func (b *CommandBuilder) SetupEgress(
+	networkID string,
+	link int,
+	sandboxName string,
+	sandboxNS namespace.Namespace,
+	containerNS namespace.Namespace,
+	containerLinkName string,
+	ipamResult *types.Result,
+) executor.Command {
+	enabled, _ := b.Egress.forNetwork(networkID)
+	if !enabled || ipamResult.IP4 == nil {
+		return commands.All()
+	}
+
+	hostLinkName, sandboxLinkName := NameEgressLinks(sandboxName)
+	hostAddress, sandboxAddress := b.Egress.linkAddresses(link)
+
+	egressCommands := []executor.Command{
+		commands.Unless{
+			Condition: conditions.LinkExists{
+				Name: hostLinkName,
+			},
+			Command: commands.All(
+				commands.CreateVeth{
+					Name:     hostLinkName,
+					PeerName: sandboxLinkName,
+					MTU:      links.BridgeMTU,
+				},
+				commands.MoveLink{
+					Name:        sandboxLinkName,
+					SandboxName: sandboxName,
+				},
+				commands.AddAddress{
+					InterfaceName: hostLinkName,
+					Address:       hostAddress,
+				},
+				commands.SetLinkUp{
+					LinkName: hostLinkName,
+				},
+				commands.InNamespace{
+					Namespace: sandboxNS,
+					Command: commands.All(
+						commands.AddAddress{
+							InterfaceName: sandboxLinkName,
+							Address:       sandboxAddress,
+						},
+						commands.SetLinkUp{
+							LinkName: sandboxLinkName,
+						},
+						commands.AddRoute{
+							Interface:   sandboxLinkName,
+							Destination: defaultRoute,
+							Gateway:     hostAddress.IP,
+						},
+						commands.EnableIPForwarding{},
+						commands.AddIPTablesRule{
+							Table: "nat",
+							Chain: "POSTROUTING",
+							Rule:  []string{"-o", sandboxLinkName, "-j", "MASQUERADE"},
+						},
+					),
+				},
+			),
+		},
+		commands.EnableIPForwarding{},
+		commands.AddIPTablesRule{
+			Table: "nat",
+			Chain: "POSTROUTING",
+			Rule:  b.Egress.hostMasqueradeRule(),
+		},
+		commands.InNamespace{
+			Namespace: sandboxNS,
+			Command: commands.ReplaceIPTablesChain{
+				Table:  "filter",
+				Chain:  EgressChain,
+				Parent: "FORWARD",
+				Rules:  b.Egress.denyRules(networkID, sandboxLinkName),
+			},
+		},
+	}
+
+	if !hasDefaultRoute(ipamResult.IP4) {
+		egressCommands = append(egressCommands, commands.InNamespace{
+			Namespace: containerNS,
+			Command: commands.AddRoute{
+				Interface:   containerLinkName,
+				Destination: defaultRoute,
+				Gateway:     ipamResult.IP4.Gateway,
+			},
+		})
+	}
+
+	return commands.All(egressCommands...)
+}
+
+var defaultRoute = net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+
+func hasDefaultRoute(ipConfig *types.IPConfig) bool {
+	for _, route := range ipConfig.Routes {
+		if ones, bits := route.Dst.Mask.Size(); bits != 0 && ones == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ipConfigs returns the address configuration for each family present in
+// the result, IPv4 first.
+func ipConfigs(ipamResult *types.Result) []*types.IPConfig {
+	var configs []*types.IPConfig
+	if ipamResult.IP4 != nil {
+		configs = append(configs, ipamResult.IP4)
+	}
+	if ipamResult.IP6 != nil {
+		configs = append(configs, ipamResult.IP6)
+	}
+	return configs
+}
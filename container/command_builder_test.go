@@ -175,16 +175,21 @@ var _ = Describe("CommandBuilder", func() {
 		})
 
 		It("should return a command group that sets up veth in container", func() {
-			address := net.IPNet{
-				IP:   net.ParseIP("192.168.2.5"),
-				Mask: net.CIDRMask(24, 32),
+			ipamResult := &types.Result{
+				IP4: &types.IPConfig{
+					IP: net.IPNet{
+						IP:   net.ParseIP("192.168.2.5"),
+						Mask: net.CIDRMask(24, 32),
+					},
+				},
 			}
 
 			cmd := b.SetupVeth(
 				containerNS,
 				"sandbox-veth",
 				"container-veth",
-				address,
+				0,
+				ipamResult,
 				"some-sandbox-name",
 				routeCommand,
 			)
@@ -206,7 +211,7 @@ var _ = Describe("CommandBuilder", func() {
 								},
 								commands.AddAddress{
 									InterfaceName: "container-veth",
-									Address:       address,
+									Address:       ipamResult.IP4.IP,
 								},
 								commands.SetLinkUp{
 									LinkName: "container-veth",
@@ -218,6 +223,59 @@ var _ = Describe("CommandBuilder", func() {
 				},
 			))
 		})
+
+		Context("when an MTU is given", func() {
+			It("creates the veth with that MTU", func() {
+				ipamResult := &types.Result{
+					IP4: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("192.168.2.5"),
+							Mask: net.CIDRMask(24, 32),
+						},
+					},
+				}
+
+				cmd := b.SetupVeth(containerNS, "sandbox-veth", "container-veth", 1400, ipamResult, "some-sandbox-name", routeCommand)
+
+				group := cmd.(commands.InNamespace).Command.(commands.Group)
+				Expect(group[0]).To(Equal(commands.CreateVeth{
+					Name:     "container-veth",
+					PeerName: "sandbox-veth",
+					MTU:      1400,
+				}))
+			})
+		})
+
+		Context("when the result includes an IPv6 address", func() {
+			It("adds both addresses to the container veth", func() {
+				ipamResult := &types.Result{
+					IP4: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("192.168.2.5"),
+							Mask: net.CIDRMask(24, 32),
+						},
+					},
+					IP6: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("fd00:0:0:2::5"),
+							Mask: net.CIDRMask(64, 128),
+						},
+					},
+				}
+
+				cmd := b.SetupVeth(containerNS, "sandbox-veth", "container-veth", 0, ipamResult, "some-sandbox-name", routeCommand)
+
+				group := cmd.(commands.InNamespace).Command.(commands.Group)
+				Expect(group).To(ContainElement(commands.AddAddress{
+					InterfaceName: "container-veth",
+					Address:       ipamResult.IP4.IP,
+				}))
+				Expect(group).To(ContainElement(commands.AddAddress{
+					InterfaceName: "container-veth",
+					Address:       ipamResult.IP6.IP,
+				}))
+			})
+		})
 	})
 
 	Describe("IdempotentlySetupBridge", func() {
@@ -289,5 +347,226 @@ var _ = Describe("CommandBuilder", func() {
 				},
 			))
 		})
+
+		Context("when the result includes an IPv6 address", func() {
+			It("adds the IPv6 gateway address to the bridge", func() {
+				sandboxNS := &fakes.Namespace{}
+				b := container.CommandBuilder{}
+
+				ipamResult := &types.Result{
+					IP4: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("192.168.100.2"),
+							Mask: net.CIDRMask(24, 32),
+						},
+						Gateway: net.ParseIP("192.168.100.1"),
+					},
+					IP6: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("fd00:0:0:2::2"),
+							Mask: net.CIDRMask(64, 128),
+						},
+						Gateway: net.ParseIP("fd00:0:0:2::1"),
+					},
+				}
+
+				cmd := b.IdempotentlySetupBridge("some-vxlan-name", "some-link-name", "some-bridge-name", sandboxNS, ipamResult)
+
+				unless := cmd.(commands.InNamespace).Command.(commands.Group)[1].(commands.Unless)
+				Expect(unless.Command).To(Equal(commands.All(
+					commands.CreateBridge{
+						Name: "some-bridge-name",
+					},
+					commands.AddAddress{
+						InterfaceName: "some-bridge-name",
+						Address: net.IPNet{
+							IP:   net.ParseIP("192.168.100.1"),
+							Mask: net.CIDRMask(24, 32),
+						},
+					},
+					commands.AddAddress{
+						InterfaceName: "some-bridge-name",
+						Address: net.IPNet{
+							IP:   net.ParseIP("fd00:0:0:2::1"),
+							Mask: net.CIDRMask(64, 128),
+						},
+					},
+					commands.SetLinkUp{
+						LinkName: "some-bridge-name",
+					},
+					commands.SetLinkMaster{
+						Master: "some-bridge-name",
+						Slave:  "dns0",
+					},
+				)))
+			})
+		})
+	})
+
+	Describe("SetupEgress", func() {
+		var (
+			b           container.CommandBuilder
+			sandboxNS   *fakes.Namespace
+			containerNS *fakes.Namespace
+			ipamResult  *types.Result
+		)
+
+		BeforeEach(func() {
+			sandboxNS = &fakes.Namespace{NameStub: func() string { return "sandbox ns sentinel" }}
+			containerNS = &fakes.Namespace{NameStub: func() string { return "container ns sentinel" }}
+
+			_, linkSubnet, _ := net.ParseCIDR("169.254.0.0/24")
+			b = container.CommandBuilder{
+				Egress: container.Egress{
+					Enabled:    true,
+					LinkSubnet: linkSubnet,
+					Deny: []net.IPNet{{
+						IP:   net.ParseIP("10.0.0.0").To4(),
+						Mask: net.CIDRMask(8, 32),
+					}},
+					Networks: map[string]container.NetworkEgress{
+						"some-disabled-network": {Enabled: false},
+						"some-other-network": {
+							Enabled: true,
+							Deny: []net.IPNet{{
+								IP:   net.ParseIP("172.16.0.0").To4(),
+								Mask: net.CIDRMask(12, 32),
+							}},
+						},
+					},
+				},
+			}
+
+			ipamResult = &types.Result{
+				IP4: &types.IPConfig{
+					IP: net.IPNet{
+						IP:   net.ParseIP("192.168.100.2"),
+						Mask: net.CIDRMask(24, 32),
+					},
+					Gateway: net.ParseIP("192.168.100.1"),
+				},
+			}
+		})
+
+		It("links the sandbox to the host over the given link and routes the container through it", func() {
+			cmd := b.SetupEgress("some-network", 3, "vni-65", sandboxNS, containerNS, "container-link", ipamResult)
+
+			defaultRoute := net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+			hostAddress := net.IPNet{IP: net.ParseIP("169.254.0.13").To4(), Mask: net.CIDRMask(30, 32)}
+			sandboxAddress := net.IPNet{IP: net.ParseIP("169.254.0.14").To4(), Mask: net.CIDRMask(30, 32)}
+
+			Expect(cmd).To(Equal(commands.All(
+				commands.Unless{
+					Condition: conditions.LinkExists{
+						Name: "egh65",
+					},
+					Command: commands.All(
+						commands.CreateVeth{
+							Name:     "egh65",
+							PeerName: "egs65",
+							MTU:      1500,
+						},
+						commands.MoveLink{
+							Name:        "egs65",
+							SandboxName: "vni-65",
+						},
+						commands.AddAddress{
+							InterfaceName: "egh65",
+							Address:       hostAddress,
+						},
+						commands.SetLinkUp{
+							LinkName: "egh65",
+						},
+						commands.InNamespace{
+							Namespace: sandboxNS,
+							Command: commands.All(
+								commands.AddAddress{
+									InterfaceName: "egs65",
+									Address:       sandboxAddress,
+								},
+								commands.SetLinkUp{
+									LinkName: "egs65",
+								},
+								commands.AddRoute{
+									Interface:   "egs65",
+									Destination: defaultRoute,
+									Gateway:     hostAddress.IP,
+								},
+								commands.EnableIPForwarding{},
+								commands.AddIPTablesRule{
+									Table: "nat",
+									Chain: "POSTROUTING",
+									Rule:  []string{"-o", "egs65", "-j", "MASQUERADE"},
+								},
+							),
+						},
+					),
+				},
+				commands.EnableIPForwarding{},
+				commands.AddIPTablesRule{
+					Table: "nat",
+					Chain: "POSTROUTING",
+					Rule:  []string{"-s", "169.254.0.0/24", "!", "-o", "egh+", "-j", "MASQUERADE"},
+				},
+				commands.InNamespace{
+					Namespace: sandboxNS,
+					Command: commands.ReplaceIPTablesChain{
+						Table:  "filter",
+						Chain:  "ducati-egress",
+						Parent: "FORWARD",
+						Rules: [][]string{
+							{"-o", "egs65", "-d", "10.0.0.0/8", "-j", "REJECT"},
+						},
+					},
+				},
+				commands.InNamespace{
+					Namespace: containerNS,
+					Command: commands.AddRoute{
+						Interface:   "container-link",
+						Destination: defaultRoute,
+						Gateway:     net.ParseIP("192.168.100.1"),
+					},
+				},
+			)))
+		})
+
+		It("adds the denied destinations of the network to the global ones", func() {
+			cmd := b.SetupEgress("some-other-network", 3, "vni-65", sandboxNS, containerNS, "container-link", ipamResult)
+
+			replace := cmd.(commands.Group)[3].(commands.InNamespace).Command.(commands.ReplaceIPTablesChain)
+			Expect(replace.Rules).To(Equal([][]string{
+				{"-o", "egs65", "-d", "10.0.0.0/8", "-j", "REJECT"},
+				{"-o", "egs65", "-d", "172.16.0.0/12", "-j", "REJECT"},
+			}))
+		})
+
+		Context("when the IPAM result already has a default route", func() {
+			BeforeEach(func() {
+				ipamResult.IP4.Routes = []types.Route{{
+					Dst: net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+				}}
+			})
+
+			It("leaves the container routes alone", func() {
+				cmd := b.SetupEgress("some-network", 3, "vni-65", sandboxNS, containerNS, "container-link", ipamResult)
+				Expect(cmd.(commands.Group)).To(HaveLen(4))
+			})
+		})
+
+		Context("when egress is disabled for the network", func() {
+			It("does nothing", func() {
+				cmd := b.SetupEgress("some-disabled-network", 3, "vni-65", sandboxNS, containerNS, "container-link", ipamResult)
+				Expect(cmd).To(Equal(commands.All()))
+			})
+		})
+
+		Context("when egress is disabled", func() {
+			It("does nothing", func() {
+				b.Egress = container.Egress{}
+
+				cmd := b.SetupEgress("some-network", 3, "vni-65", sandboxNS, containerNS, "container-link", ipamResult)
+				Expect(cmd).To(Equal(commands.All()))
+			})
+		})
 	})
 })
@@ -20,14 +20,23 @@ type commandBuilder interface {
 	IdempotentlyCreateSandbox(sandboxName, vxlanName string, vni int, dnsAddress string) executor.Command
 	IdempotentlyCreateVxlan(vxlanName string, sandboxName string, sandboxNS namespace.Namespace) executor.Command
 	AddRoutes(interfaceName string, ipConfig *types.IPConfig) executor.Command
-	SetupVeth(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, address net.IPNet, sandboxName string, routeCommand executor.Command) executor.Command
+	SetupVeth(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, mtu int, ipamResult *types.Result, sandboxName string, routeCommand executor.Command) executor.Command
 	IdempotentlySetupBridge(vxlanName, sandboxLinkName, bridgeName string, sandboxNS namespace.Namespace, ipamResult *types.Result) executor.Command
+	SetupEgress(networkID string, link int, sandboxName string, sandboxNS namespace.Namespace, containerNS namespace.Namespace, containerLinkName string, ipamResult *types.Result) executor.Command
 }
 
 type sandboxRepository interface {
 	Get(sandboxName string) (sandbox.Sandbox, error)
 }
 
+//go:generate counterfeiter -o ../fakes/forwarding_checker.go --fake-name ForwardingChecker . forwardingChecker
+type forwardingChecker interface {
+	ForwardingEnabled() (bool, error)
+}
+
+// Creator attaches containers to the sandbox of their network. When egress
+// is enabled for the network, the sandbox's egress link is taken from
+// EgressLinks, recording whether HostFirewall was already forwarding.
 type Creator struct {
 	Executor        executor.Executor
 	SandboxRepo     sandboxRepository
@@ -36,6 +45,9 @@ type Creator struct {
 	DNSAddress      string
 	HostIP          net.IP
 	NamespaceOpener namespace.Opener
+	Egress          Egress
+	EgressLinks     egressLinks
+	HostFirewall    forwardingChecker
 }
 
 type CreatorConfig struct {
@@ -45,6 +57,7 @@ type CreatorConfig struct {
 	ContainerID     string
 	InterfaceName   string
 	VNI             int
+	MTU             int
 	IPAMResult      *types.Result
 }
 
@@ -67,6 +80,12 @@ func (c *Creator) Setup(config CreatorConfig) (models.Container, error) {
 	sandboxLinkName := NameSandboxLink(config.ContainerID)
 
 	var routeCommands = c.CommandBuilder.AddRoutes(config.InterfaceName, config.IPAMResult.IP4)
+	if config.IPAMResult.IP6 != nil {
+		routeCommands = commands.All(
+			routeCommands,
+			c.CommandBuilder.AddRoutes(config.InterfaceName, config.IPAMResult.IP6),
+		)
+	}
 
 	err = c.Executor.Execute(c.CommandBuilder.IdempotentlyCreateSandbox(sandboxName, vxlanName, config.VNI, c.DNSAddress))
 	if err != nil {
@@ -81,11 +100,23 @@ func (c *Creator) Setup(config CreatorConfig) (models.Container, error) {
 	defer sandbox.Unlock()
 
 	sandboxNS := sandbox.Namespace()
+
+	egressCommand := commands.All()
+	if enabled, _ := c.Egress.forNetwork(config.NetworkID); enabled && config.IPAMResult.IP4 != nil {
+		link, err := c.acquireEgressLink(sandboxName)
+		if err != nil {
+			return models.Container{}, err
+		}
+
+		egressCommand = c.CommandBuilder.SetupEgress(config.NetworkID, link, sandboxName, sandboxNS, containerNS, config.InterfaceName, config.IPAMResult)
+	}
+
 	err = c.Executor.Execute(
 		commands.All(
 			c.CommandBuilder.IdempotentlyCreateVxlan(vxlanName, sandboxName, sandboxNS),
-			c.CommandBuilder.SetupVeth(containerNS, sandboxLinkName, config.InterfaceName, config.IPAMResult.IP4.IP, sandboxName, routeCommands),
+			c.CommandBuilder.SetupVeth(containerNS, sandboxLinkName, config.InterfaceName, config.MTU, config.IPAMResult, sandboxName, routeCommands),
 			c.CommandBuilder.IdempotentlySetupBridge(vxlanName, sandboxLinkName, bridgeName, sandboxNS, config.IPAMResult),
+			egressCommand,
 		),
 	)
 	if err != nil {
@@ -105,13 +136,36 @@ func (c *Creator) Setup(config CreatorConfig) (models.Container, error) {
 		return models.Container{}, err
 	}
 
+	var ip6 string
+	if config.IPAMResult.IP6 != nil {
+		ip6 = config.IPAMResult.IP6.IP.IP.String()
+	}
+
 	return models.Container{
-		ID:          config.ContainerID,
-		MAC:         getHardwareAddressCommand.Result.String(),
-		IP:          config.IPAMResult.IP4.IP.IP.String(),
-		NetworkID:   config.NetworkID,
-		HostIP:      c.HostIP.String(),
-		SandboxName: sandboxName,
-		App:         config.App,
+		ID:                 config.ContainerID,
+		MAC:                getHardwareAddressCommand.Result.String(),
+		IP:                 config.IPAMResult.IP4.IP.IP.String(),
+		IP6:                ip6,
+		NetworkID:          config.NetworkID,
+		HostIP:             c.HostIP.String(),
+		SandboxName:        sandboxName,
+		App:                config.App,
+		ContainerNamespace: config.ContainerNsPath,
+		InterfaceName:      config.InterfaceName,
+		IPAMResult:         &models.IPAMResult{Result: *config.IPAMResult},
 	}, nil
 }
+
+func (c *Creator) acquireEgressLink(sandboxName string) (int, error) {
+	hostForwarding, err := c.HostFirewall.ForwardingEnabled()
+	if err != nil {
+		return 0, fmt.Errorf("reading host forwarding: %s", err)
+	}
+
+	link, err := c.EgressLinks.Acquire(sandboxName, hostForwarding)
+	if err != nil {
+		return 0, fmt.Errorf("acquiring egress link: %s", err)
+	}
+
+	return link, nil
+}
@@ -31,6 +31,8 @@ var _ = Describe("Creator", func() {
 		missWatcher     watcher.MissWatcher
 		commandBuilder  *fakes.CommandBuilder
 		namespaceOpener *fakes.Opener
+		egressLinks     *fakes.EgressLinkRegistry
+		hostFirewall    *fakes.ForwardingChecker
 	)
 
 	BeforeEach(func() {
@@ -42,6 +44,9 @@ var _ = Describe("Creator", func() {
 		containerNS = &fakes.Namespace{NameStub: func() string { return "container ns sentinel" }}
 		namespaceOpener = &fakes.Opener{}
 		namespaceOpener.OpenPathReturns(containerNS, nil)
+		egressLinks = &fakes.EgressLinkRegistry{}
+		egressLinks.AcquireReturns(3, nil)
+		hostFirewall = &fakes.ForwardingChecker{}
 		creator = container.Creator{
 			Executor:        ex,
 			SandboxRepo:     sandboxRepo,
@@ -50,6 +55,9 @@ var _ = Describe("Creator", func() {
 			NamespaceOpener: namespaceOpener,
 			DNSAddress:      "some-dns-address",
 			HostIP:          net.ParseIP("10.11.12.13"),
+			Egress:          container.Egress{Enabled: true},
+			EgressLinks:     egressLinks,
+			HostFirewall:    hostFirewall,
 		}
 
 		macAddress := "01:02:03:04:05:06"
@@ -101,6 +109,7 @@ var _ = Describe("Creator", func() {
 			ContainerID:     "123456789012345",
 			InterfaceName:   "container-link",
 			VNI:             99,
+			MTU:             1400,
 			IPAMResult:      ipamResult,
 			App:             "some-app-guid",
 		}
@@ -127,13 +136,16 @@ var _ = Describe("Creator", func() {
 		container, err := creator.Setup(config)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(container).To(Equal(models.Container{
-			NetworkID:   "some-crazy-network-id",
-			ID:          "123456789012345",
-			MAC:         "01:02:03:04:05:06",
-			IP:          "192.168.100.2",
-			HostIP:      "10.11.12.13",
-			SandboxName: "vni-99",
-			App:         "some-app-guid",
+			NetworkID:          "some-crazy-network-id",
+			ID:                 "123456789012345",
+			MAC:                "01:02:03:04:05:06",
+			IP:                 "192.168.100.2",
+			HostIP:             "10.11.12.13",
+			SandboxName:        "vni-99",
+			App:                "some-app-guid",
+			ContainerNamespace: "/some/container/ns/path",
+			InterfaceName:      "container-link",
+			IPAMResult:         &models.IPAMResult{Result: *ipamResult},
 		}))
 	})
 
@@ -218,13 +230,59 @@ var _ = Describe("Creator", func() {
 		commandGroup := (ex.ExecuteArgsForCall(1)).(commands.Group)
 		Expect(commandGroup[1]).To(Equal(setupContainerResult))
 
-		contNS, sandboxLinkName, containerLinkName, address, sandboxName, routeCommands := commandBuilder.SetupVethArgsForCall(0)
+		contNS, sandboxLinkName, containerLinkName, mtu, result, sandboxName, routeCommands := commandBuilder.SetupVethArgsForCall(0)
 		Expect(contNS).To(Equal(containerNS))
 		Expect(sandboxLinkName).To(Equal("MXGEYC3M7HCW4KR"))
 		Expect(containerLinkName).To(Equal("container-link"))
-		Expect(address).To(Equal(ipamResult.IP4.IP))
+		Expect(mtu).To(Equal(1400))
+		Expect(result).To(Equal(ipamResult))
 		Expect(sandboxName).To(Equal("vni-99"))
 		Expect(routeCommands).To(BeIdenticalTo(fakeRouteCommands))
+
+		Expect(commandBuilder.AddRoutesCallCount()).To(Equal(1))
+		interfaceName, ipConfig := commandBuilder.AddRoutesArgsForCall(0)
+		Expect(interfaceName).To(Equal("container-link"))
+		Expect(ipConfig).To(Equal(ipamResult.IP4))
+	})
+
+	Context("when the result includes an IPv6 address", func() {
+		BeforeEach(func() {
+			ipamResult.IP6 = &types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:0:9::2"),
+					Mask: net.CIDRMask(64, 128),
+				},
+				Gateway: net.ParseIP("fd00:0:0:9::1"),
+			}
+		})
+
+		It("adds the routes for both families", func() {
+			v4Routes := &fakes.Command{}
+			v6Routes := &fakes.Command{}
+			commandBuilder.AddRoutesStub = func(_ string, ipConfig *types.IPConfig) executor.Command {
+				if ipConfig == ipamResult.IP6 {
+					return v6Routes
+				}
+				return v4Routes
+			}
+
+			_, err := creator.Setup(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandBuilder.AddRoutesCallCount()).To(Equal(2))
+			_, ipConfig := commandBuilder.AddRoutesArgsForCall(1)
+			Expect(ipConfig).To(Equal(ipamResult.IP6))
+
+			_, _, _, _, _, _, routeCommands := commandBuilder.SetupVethArgsForCall(0)
+			Expect(routeCommands).To(Equal(commands.All(v4Routes, v6Routes)))
+		})
+
+		It("records the IPv6 address on the container", func() {
+			container, err := creator.Setup(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(container.IP6).To(Equal("fd00:0:0:9::2"))
+		})
 	})
 
 	It("should execute the IdempotentlySetupBridge command group", func() {
@@ -267,6 +325,74 @@ var _ = Describe("Creator", func() {
 		}))
 	})
 
+	It("should execute the SetupEgress command group over the sandbox's egress link", func() {
+		setupEgressResult := &fakes.Command{}
+
+		commandBuilder.SetupEgressReturns(setupEgressResult)
+		hostFirewall.ForwardingEnabledReturns(true, nil)
+
+		_, err := creator.Setup(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(egressLinks.AcquireCallCount()).To(Equal(1))
+		acquiredFor, hostForwarding := egressLinks.AcquireArgsForCall(0)
+		Expect(acquiredFor).To(Equal("vni-99"))
+		Expect(hostForwarding).To(BeTrue())
+
+		commandGroup := (ex.ExecuteArgsForCall(1)).(commands.Group)
+		Expect(commandGroup[3]).To(Equal(setupEgressResult))
+
+		networkID, link, sandboxName, sbNS, contNS, containerLinkName, result := commandBuilder.SetupEgressArgsForCall(0)
+		Expect(networkID).To(Equal("some-crazy-network-id"))
+		Expect(link).To(Equal(3))
+		Expect(sandboxName).To(Equal("vni-99"))
+		Expect(sbNS).To(Equal(sandboxNS))
+		Expect(contNS).To(Equal(containerNS))
+		Expect(containerLinkName).To(Equal("container-link"))
+		Expect(result).To(Equal(ipamResult))
+	})
+
+	Context("when egress is disabled for the network", func() {
+		BeforeEach(func() {
+			creator.Egress = container.Egress{}
+		})
+
+		It("takes no egress link and sets up no egress", func() {
+			_, err := creator.Setup(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(egressLinks.AcquireCallCount()).To(Equal(0))
+			Expect(commandBuilder.SetupEgressCallCount()).To(Equal(0))
+
+			commandGroup := (ex.ExecuteArgsForCall(1)).(commands.Group)
+			Expect(commandGroup[3]).To(Equal(commands.All()))
+		})
+	})
+
+	Context("when reading the host forwarding fails", func() {
+		BeforeEach(func() {
+			hostFirewall.ForwardingEnabledReturns(false, errors.New("potato"))
+		})
+
+		It("returns a meaningful error", func() {
+			_, err := creator.Setup(config)
+			Expect(err).To(MatchError("reading host forwarding: potato"))
+			Expect(egressLinks.AcquireCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when no egress link can be acquired", func() {
+		BeforeEach(func() {
+			egressLinks.AcquireReturns(0, errors.New("potato"))
+		})
+
+		It("returns a meaningful error without setting anything up", func() {
+			_, err := creator.Setup(config)
+			Expect(err).To(MatchError("acquiring egress link: potato"))
+			Expect(ex.ExecuteCallCount()).To(Equal(1))
+		})
+	})
+
 	Context("when the container ID is very long", func() {
 		It("keeps the sandbox link name short", func() {
 			config.ContainerID = "1234567890123456789"
@@ -274,7 +400,7 @@ var _ = Describe("Creator", func() {
 			_, err := creator.Setup(config)
 			Expect(err).NotTo(HaveOccurred())
 
-			_, sandboxLinkName, _, _, _, _ := commandBuilder.SetupVethArgsForCall(0)
+			_, sandboxLinkName, _, _, _, _, _ := commandBuilder.SetupVethArgsForCall(0)
 			Expect(sandboxLinkName).To(HaveLen(15))
 
 			_, sandboxLinkName, _, _, _ = commandBuilder.IdempotentlySetupBridgeArgsForCall(0)
@@ -288,7 +414,7 @@ var _ = Describe("Creator", func() {
 		_, err := creator.Setup(config)
 		Expect(err).NotTo(HaveOccurred())
 
-		_, sandboxLinkName, _, _, _, _ := commandBuilder.SetupVethArgsForCall(0)
+		_, sandboxLinkName, _, _, _, _, _ := commandBuilder.SetupVethArgsForCall(0)
 
 		matches, err := regexp.MatchString("^[a-zA-Z0-9]*$", sandboxLinkName)
 		Expect(err).NotTo(HaveOccurred())
@@ -303,7 +429,7 @@ var _ = Describe("Creator", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			_, sandboxLinkName1, _, _, _ := commandBuilder.IdempotentlySetupBridgeArgsForCall(0)
-			_, sandboxLinkName1, _, _, _, _ = commandBuilder.SetupVethArgsForCall(0)
+			_, sandboxLinkName1, _, _, _, _, _ = commandBuilder.SetupVethArgsForCall(0)
 
 			config.ContainerID = "1234567890123456798"
 
@@ -311,7 +437,7 @@ var _ = Describe("Creator", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			_, sandboxLinkName2, _, _, _ := commandBuilder.IdempotentlySetupBridgeArgsForCall(1)
-			_, sandboxLinkName2, _, _, _, _ = commandBuilder.SetupVethArgsForCall(1)
+			_, sandboxLinkName2, _, _, _, _, _ = commandBuilder.SetupVethArgsForCall(1)
 
 			Expect(sandboxLinkName1).NotTo(Equal(sandboxLinkName2))
 		})
@@ -2,15 +2,21 @@ package container
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/conditions"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
 )
 
+// Deletor detaches containers from their sandbox and cleans the sandbox up
+// once it is empty, giving its egress link back to EgressLinks.
 type Deletor struct {
 	Executor        executor.Executor
 	NamespaceOpener namespace.Opener
+	Egress          Egress
+	EgressLinks     egressLinks
 }
 
 func (d *Deletor) Delete(
@@ -19,26 +25,45 @@ func (d *Deletor) Delete(
 	sandboxName string,
 	vxlanDeviceName string,
 ) error {
-	containerNS, err := d.NamespaceOpener.OpenPath(containerNSPath)
-	if err != nil {
-		return fmt.Errorf("open container netns: %s", err)
-	}
+	var steps []executor.Command
 
-	err = d.Executor.Execute(
-		commands.All(
-			commands.InNamespace{
-				Namespace: containerNS,
+	// when the container namespace is already gone, so is the veth pair
+	containerNS, err := d.NamespaceOpener.OpenPath(containerNSPath)
+	switch {
+	case err == nil:
+		steps = append(steps, commands.InNamespace{
+			Namespace: containerNS,
+			Command: commands.Unless{
+				Condition: conditions.Not{
+					Condition: conditions.LinkExists{
+						Name: interfaceName,
+					},
+				},
 				Command: commands.DeleteLink{
 					LinkName: interfaceName,
 				},
 			},
+		})
+	case os.IsNotExist(err):
+	default:
+		return fmt.Errorf("open container netns: %s", err)
+	}
 
-			commands.CleanupSandbox{
-				SandboxName:     sandboxName,
-				VxlanDeviceName: vxlanDeviceName,
-			},
-		),
-	)
+	cleanup := commands.CleanupSandbox{
+		SandboxName:     sandboxName,
+		VxlanDeviceName: vxlanDeviceName,
+	}
+	if d.EgressLinks != nil {
+		_, cleanup.EgressLinkName = NameEgressLinks(sandboxName)
+		cleanup.EgressTeardown = commands.TeardownEgress{
+			Links:          d.EgressLinks,
+			SandboxName:    sandboxName,
+			MasqueradeRule: d.Egress.hostMasqueradeRule(),
+		}
+	}
+	steps = append(steps, cleanup)
+
+	err = d.Executor.Execute(commands.All(steps...))
 	if err != nil {
 		return err
 	}
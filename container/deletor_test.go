@@ -2,9 +2,13 @@ package container_test
 
 import (
 	"errors"
+	"net"
+	"os"
+	"syscall"
 
 	"github.com/cloudfoundry-incubator/ducati-daemon/container"
 	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/conditions"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
 
@@ -51,6 +55,27 @@ var _ = Describe("Delete", func() {
 		})
 	})
 
+	Context("when the container namespace no longer exists", func() {
+		BeforeEach(func() {
+			namespaceOpener.OpenPathReturns(nil, &os.PathError{Op: "open", Path: "/path/to/container/namespace", Err: syscall.ENOENT})
+		})
+
+		It("still cleans up the sandbox", func() {
+			err := deletor.Delete("some-interface-name", "/path/to/container/namespace", "sandbox-name", "some-vxlan")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executor.ExecuteCallCount()).To(Equal(1))
+			Expect(executor.ExecuteArgsForCall(0)).To(Equal(
+				commands.All(
+					commands.CleanupSandbox{
+						SandboxName:     "sandbox-name",
+						VxlanDeviceName: "some-vxlan",
+					},
+				),
+			))
+		})
+	})
+
 	It("should construct the correct command sequence", func() {
 		err := deletor.Delete("some-interface-name", "/path/to/container/namespace", "sandbox-name", "some-vxlan")
 		Expect(err).NotTo(HaveOccurred())
@@ -60,8 +85,15 @@ var _ = Describe("Delete", func() {
 			commands.All(
 				commands.InNamespace{
 					Namespace: containerNS,
-					Command: commands.DeleteLink{
-						LinkName: "some-interface-name",
+					Command: commands.Unless{
+						Condition: conditions.Not{
+							Condition: conditions.LinkExists{
+								Name: "some-interface-name",
+							},
+						},
+						Command: commands.DeleteLink{
+							LinkName: "some-interface-name",
+						},
 					},
 				},
 
@@ -73,6 +105,35 @@ var _ = Describe("Delete", func() {
 		))
 	})
 
+	Context("when sandboxes may have egress links", func() {
+		var egressLinks *fakes.EgressLinkRegistry
+
+		BeforeEach(func() {
+			egressLinks = &fakes.EgressLinkRegistry{}
+
+			_, linkSubnet, _ := net.ParseCIDR("169.254.0.0/24")
+			deletor.Egress = container.Egress{LinkSubnet: linkSubnet}
+			deletor.EgressLinks = egressLinks
+		})
+
+		It("tears down the egress of the sandbox when it is cleaned up", func() {
+			err := deletor.Delete("some-interface-name", "/path/to/container/namespace", "vni-65", "vxlan65")
+			Expect(err).NotTo(HaveOccurred())
+
+			group := executor.ExecuteArgsForCall(0).(commands.Group)
+			Expect(group[1]).To(Equal(commands.CleanupSandbox{
+				SandboxName:     "vni-65",
+				VxlanDeviceName: "vxlan65",
+				EgressLinkName:  "egs65",
+				EgressTeardown: commands.TeardownEgress{
+					Links:          egressLinks,
+					SandboxName:    "vni-65",
+					MasqueradeRule: []string{"-s", "169.254.0.0/24", "!", "-o", "egh+", "-j", "MASQUERADE"},
+				},
+			}))
+		})
+	})
+
 	Context("when executing fails", func() {
 		BeforeEach(func() {
 			executor.ExecuteReturns(errors.New("boom"))
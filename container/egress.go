@@ -0,0 +1,186 @@
+package container
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+// EgressChain is the filter chain in a sandbox that rejects traffic to the
+// denied destinations before it leaves for the host.
+const EgressChain = "ducati-egress"
+
+// Egress gives containers a path to networks outside the overlay. Each
+// sandbox is joined to the host by a veth pair addressed from a /30 of
+// LinkSubnet that the egress link registry hands out; traffic leaving the
+// sandbox is masqueraded to its end of the link and masqueraded again by
+// the host. Enabled and Deny apply to every network; an entry in Networks
+// overrides Enabled and adds to Deny. Only IPv4 traffic is routed.
+type Egress struct {
+	Enabled    bool
+	LinkSubnet *net.IPNet
+	Deny       []net.IPNet
+	Networks   map[string]NetworkEgress
+}
+
+type NetworkEgress struct {
+	Enabled bool
+	Deny    []net.IPNet
+}
+
+func (e Egress) forNetwork(networkID string) (bool, []net.IPNet) {
+	n, ok := e.Networks[networkID]
+	if !ok {
+		return e.Enabled, e.Deny
+	}
+
+	deny := append(append([]net.IPNet{}, e.Deny...), n.Deny...)
+	return n.Enabled, deny
+}
+
+// egressLinks hands out the /30s of LinkSubnet to the sandboxes of a host.
+type egressLinks interface {
+	Acquire(sandboxName string, hostForwarding bool) (int, error)
+	Release(sandboxName string) (bool, bool, error)
+}
+
+// LinkCount is the number of /30s in LinkSubnet, and so the number of
+// sandboxes on a host that can have egress at once.
+func (e Egress) LinkCount() int {
+	ones, bits := e.LinkSubnet.Mask.Size()
+	return 1 << uint(bits-ones-2)
+}
+
+// linkAddresses returns the host and sandbox ends of the egress link with
+// the given index.
+func (e Egress) linkAddresses(link int) (net.IPNet, net.IPNet) {
+	base := binary.BigEndian.Uint32(e.LinkSubnet.IP.To4()) + uint32(link)*4
+
+	mask := net.CIDRMask(30, 32)
+	return net.IPNet{IP: uint32ToIP(base + 1), Mask: mask},
+		net.IPNet{IP: uint32ToIP(base + 2), Mask: mask}
+}
+
+func uint32ToIP(n uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
+
+// hostMasqueradeRule is the host's nat rule for traffic from every egress
+// link on the host. It is added with the first link and removed with the
+// last.
+func (e Egress) hostMasqueradeRule() []string {
+	return []string{"-s", e.LinkSubnet.String(), "!", "-o", "egh+", "-j", "MASQUERADE"}
+}
+
+// denyRules returns the rules of the sandbox's egress chain. When egress
+// has been turned off for a network that already has a link, everything
+// leaving through the link is rejected.
+func (e Egress) denyRules(networkID, sandboxLinkName string) [][]string {
+	enabled, deny := e.forNetwork(networkID)
+	if !enabled {
+		return [][]string{{"-o", sandboxLinkName, "-j", "REJECT"}}
+	}
+
+	var rules [][]string
+	for _, d := range deny {
+		rules = append(rules, []string{"-o", sandboxLinkName, "-d", d.String(), "-j", "REJECT"})
+	}
+
+	return rules
+}
+
+// NameEgressLinks returns the names of the host and sandbox ends of the
+// egress link of a sandbox.
+func NameEgressLinks(sandboxName string) (string, string) {
+	suffix := strings.TrimPrefix(sandboxName, "vni-")
+	return "egh" + suffix, "egs" + suffix
+}
+
+type sandboxContainerLister interface {
+	AllBySandbox(sandboxName string) ([]models.Container, error)
+}
+
+type linkChecker interface {
+	Exists(name string) bool
+}
+
+type chainReplacer interface {
+	ReplaceChain(table, chain, parent string, rules [][]string) error
+}
+
+// EgressReloader reapplies the deny list of a sandbox's network when the
+// daemon restarts, so that configuration changes reach sandboxes that were
+// linked to the host before. Sandboxes without an egress link are left
+// alone.
+type EgressReloader struct {
+	Egress     Egress
+	Containers sandboxContainerLister
+	Links      linkChecker
+	Firewall   chainReplacer
+}
+
+func (r *EgressReloader) Reload(ns namespace.Namespace) error {
+	sandboxName := path.Base(ns.Name())
+	_, sandboxLinkName := NameEgressLinks(sandboxName)
+
+	containers, err := r.Containers.AllBySandbox(sandboxName)
+	if err != nil {
+		return fmt.Errorf("listing containers: %s", err)
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	rules := r.Egress.denyRules(containers[0].NetworkID, sandboxLinkName)
+
+	return ns.Execute(func(*os.File) error {
+		if !r.Links.Exists(sandboxLinkName) {
+			return nil
+		}
+
+		err := r.Firewall.ReplaceChain("filter", EgressChain, "FORWARD", rules)
+		if err != nil {
+			return fmt.Errorf("replacing deny rules: %s", err)
+		}
+
+		return nil
+	})
+}
+
+type egressLinkIndex interface {
+	Links() (map[string]int, error)
+}
+
+// EgressLinkLister lists the egress links of the sandboxes on this host.
+type EgressLinkLister struct {
+	Egress   Egress
+	Registry egressLinkIndex
+}
+
+func (l *EgressLinkLister) List() ([]models.EgressLink, error) {
+	links, err := l.Registry.Links()
+	if err != nil {
+		return nil, err
+	}
+
+	egressLinks := []models.EgressLink{}
+	for sandboxName, link := range links {
+		hostLinkName, _ := NameEgressLinks(sandboxName)
+		_, sandboxAddress := l.Egress.linkAddresses(link)
+		egressLinks = append(egressLinks, models.EgressLink{
+			SandboxName:    sandboxName,
+			HostInterface:  hostLinkName,
+			SandboxAddress: sandboxAddress.IP,
+		})
+	}
+
+	return egressLinks, nil
+}
@@ -0,0 +1,174 @@
+package container_test
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/container"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressReloader", func() {
+	var (
+		reloader    *container.EgressReloader
+		datastore   *fakes.Store
+		linkFactory *fakes.LinkFactory
+		firewall    *fakes.Firewall
+		sandboxNS   *fakes.Namespace
+	)
+
+	BeforeEach(func() {
+		datastore = &fakes.Store{}
+		datastore.AllBySandboxReturns([]models.Container{{ID: "some-container", NetworkID: "some-network"}}, nil)
+
+		linkFactory = &fakes.LinkFactory{}
+		linkFactory.ExistsReturns(true)
+
+		firewall = &fakes.Firewall{}
+
+		sandboxNS = &fakes.Namespace{}
+		sandboxNS.NameReturns("/some/sbox/path/vni-65")
+		sandboxNS.ExecuteStub = func(callback func(*os.File) error) error {
+			Expect(firewall.ReplaceChainCallCount()).To(Equal(0))
+			return callback(nil)
+		}
+
+		_, linkSubnet, _ := net.ParseCIDR("169.254.0.0/17")
+		_, denied, _ := net.ParseCIDR("10.0.0.0/8")
+		_, networkDenied, _ := net.ParseCIDR("192.168.0.0/16")
+
+		reloader = &container.EgressReloader{
+			Egress: container.Egress{
+				Enabled:    true,
+				LinkSubnet: linkSubnet,
+				Deny:       []net.IPNet{*denied},
+				Networks: map[string]container.NetworkEgress{
+					"some-network":          {Enabled: true, Deny: []net.IPNet{*networkDenied}},
+					"some-disabled-network": {Enabled: false},
+				},
+			},
+			Containers: datastore,
+			Links:      linkFactory,
+			Firewall:   firewall,
+		}
+	})
+
+	It("replaces the egress chain of the sandbox with the network's deny list", func() {
+		err := reloader.Reload(sandboxNS)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(datastore.AllBySandboxCallCount()).To(Equal(1))
+		Expect(datastore.AllBySandboxArgsForCall(0)).To(Equal("vni-65"))
+
+		Expect(linkFactory.ExistsCallCount()).To(Equal(1))
+		Expect(linkFactory.ExistsArgsForCall(0)).To(Equal("egs65"))
+
+		Expect(sandboxNS.ExecuteCallCount()).To(Equal(1))
+		Expect(firewall.ReplaceChainCallCount()).To(Equal(1))
+		table, chain, parent, rules := firewall.ReplaceChainArgsForCall(0)
+		Expect(table).To(Equal("filter"))
+		Expect(chain).To(Equal(container.EgressChain))
+		Expect(parent).To(Equal("FORWARD"))
+		Expect(rules).To(Equal([][]string{
+			{"-o", "egs65", "-d", "10.0.0.0/8", "-j", "REJECT"},
+			{"-o", "egs65", "-d", "192.168.0.0/16", "-j", "REJECT"},
+		}))
+	})
+
+	Context("when egress has been turned off for the network", func() {
+		BeforeEach(func() {
+			datastore.AllBySandboxReturns([]models.Container{{ID: "some-container", NetworkID: "some-disabled-network"}}, nil)
+		})
+
+		It("rejects everything leaving through the link", func() {
+			err := reloader.Reload(sandboxNS)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, _, rules := firewall.ReplaceChainArgsForCall(0)
+			Expect(rules).To(Equal([][]string{{"-o", "egs65", "-j", "REJECT"}}))
+		})
+	})
+
+	Context("when the sandbox has no egress link", func() {
+		BeforeEach(func() {
+			linkFactory.ExistsReturns(false)
+		})
+
+		It("leaves the sandbox alone", func() {
+			err := reloader.Reload(sandboxNS)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(firewall.ReplaceChainCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the sandbox has no containers", func() {
+		BeforeEach(func() {
+			datastore.AllBySandboxReturns([]models.Container{}, nil)
+		})
+
+		It("leaves the sandbox alone", func() {
+			err := reloader.Reload(sandboxNS)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sandboxNS.ExecuteCallCount()).To(Equal(0))
+			Expect(firewall.ReplaceChainCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			datastore.AllBySandboxReturns(nil, errors.New("potato"))
+		})
+
+		It("wraps and returns the error", func() {
+			err := reloader.Reload(sandboxNS)
+			Expect(err).To(MatchError("listing containers: potato"))
+		})
+	})
+
+	Context("when replacing the chain fails", func() {
+		BeforeEach(func() {
+			firewall.ReplaceChainReturns(errors.New("no table"))
+		})
+
+		It("wraps and returns the error", func() {
+			err := reloader.Reload(sandboxNS)
+			Expect(err).To(MatchError("replacing deny rules: no table"))
+		})
+	})
+})
+
+var _ = Describe("EgressLinkLister", func() {
+	It("lists the host end and sandbox address of each egress link", func() {
+		registry := &fakes.EgressLinkRegistry{}
+		registry.LinksReturns(map[string]int{"vni-65": 3}, nil)
+
+		_, linkSubnet, _ := net.ParseCIDR("169.254.0.0/17")
+		lister := &container.EgressLinkLister{
+			Egress:   container.Egress{LinkSubnet: linkSubnet},
+			Registry: registry,
+		}
+
+		links, err := lister.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(links).To(Equal([]models.EgressLink{{
+			SandboxName:    "vni-65",
+			HostInterface:  "egh65",
+			SandboxAddress: net.ParseIP("169.254.0.14").To4(),
+		}}))
+	})
+
+	It("returns the error of the registry", func() {
+		registry := &fakes.EgressLinkRegistry{}
+		registry.LinksReturns(nil, errors.New("potato"))
+
+		_, err := (&container.EgressLinkLister{Registry: registry}).List()
+		Expect(err).To(MatchError("potato"))
+	})
+})
@@ -0,0 +1,20 @@
+package distributor
+
+import "github.com/cloudfoundry-incubator/ducati-daemon/models"
+
+// Chain hands each event to every distributor in turn. A failing
+// distributor does not keep the event from the ones after it; the first
+// error is returned.
+type Chain []distributor
+
+func (c Chain) Distribute(event models.ContainerEvent) error {
+	var firstErr error
+	for _, d := range c {
+		err := d.Distribute(event)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
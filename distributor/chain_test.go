@@ -0,0 +1,51 @@
+package distributor_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/distributor"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chain", func() {
+	var (
+		first, second *fakes.Distributor
+		chain         distributor.Chain
+		event         models.ContainerEvent
+	)
+
+	BeforeEach(func() {
+		first = &fakes.Distributor{}
+		second = &fakes.Distributor{}
+		chain = distributor.Chain{first, second}
+		event = models.ContainerEvent{
+			Action:    models.ContainerCreated,
+			Container: models.Container{ID: "some-container-id"},
+		}
+	})
+
+	It("hands the event to every distributor", func() {
+		Expect(chain.Distribute(event)).To(Succeed())
+
+		Expect(first.DistributeCallCount()).To(Equal(1))
+		Expect(first.DistributeArgsForCall(0)).To(Equal(event))
+		Expect(second.DistributeCallCount()).To(Equal(1))
+		Expect(second.DistributeArgsForCall(0)).To(Equal(event))
+	})
+
+	Context("when distributors fail", func() {
+		BeforeEach(func() {
+			first.DistributeReturns(errors.New("apple"))
+			second.DistributeReturns(errors.New("banana"))
+		})
+
+		It("still hands the event to the rest and returns the first error", func() {
+			Expect(chain.Distribute(event)).To(MatchError("apple"))
+			Expect(second.DistributeCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package distributor
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter -o ../fakes/neighbor_manager.go --fake-name NeighborManager . neighborManager
+type neighborManager interface {
+	AddNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error
+	RemoveNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error
+}
+
+type sandboxGetter interface {
+	Get(sandboxName string) (sandbox.Sandbox, error)
+}
+
+type cacheInvalidator interface {
+	Invalidate(sandboxName, ip string)
+}
+
+// Distributor programs the neighbor entries for containers on other hosts
+// into the matching local sandbox as soon as they are created, and removes
+// them when the container goes away.
+type Distributor struct {
+	Logger        lager.Logger
+	HostIP        net.IP
+	NetworkMapper network.NetworkMapper
+	SandboxRepo   sandboxGetter
+	Neighbors     neighborManager
+	ResolverCache cacheInvalidator
+}
+
+func (d *Distributor) Distribute(event models.ContainerEvent) error {
+	logger := d.Logger.Session("distribute", lager.Data{"event": event})
+
+	container := event.Container
+	if container.HostIP == d.HostIP.String() {
+		return nil
+	}
+
+	d.ResolverCache.Invalidate(container.SandboxName, container.IP)
+	if container.IP6 != "" {
+		d.ResolverCache.Invalidate(container.SandboxName, container.IP6)
+	}
+
+	vni, err := d.NetworkMapper.GetVNI(container.NetworkID)
+	if err != nil {
+		return fmt.Errorf("get vni: %s", err)
+	}
+
+	sandboxName := fmt.Sprintf("vni-%d", vni)
+	vxlanDeviceName := fmt.Sprintf("vxlan%d", vni)
+
+	sbox, err := d.SandboxRepo.Get(sandboxName)
+	if err == sandbox.NotFoundError {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get sandbox: %s", err)
+	}
+
+	neighbors, err := toNeighbors(sandboxName, container)
+	if err != nil {
+		return err
+	}
+
+	sbox.Lock()
+	defer sbox.Unlock()
+
+	for _, neighbor := range neighbors {
+		switch event.Action {
+		case models.ContainerCreated:
+			err = d.Neighbors.AddNeighbor(sbox.Namespace(), vxlanDeviceName, neighbor)
+			if err != nil {
+				return fmt.Errorf("add neighbor: %s", err)
+			}
+		case models.ContainerDeleted:
+			err = d.Neighbors.RemoveNeighbor(sbox.Namespace(), vxlanDeviceName, neighbor)
+			if err != nil {
+				return fmt.Errorf("remove neighbor: %s", err)
+			}
+		default:
+			return fmt.Errorf("unknown action %q", event.Action)
+		}
+	}
+
+	logger.Info("distributed")
+
+	return nil
+}
+
+// toNeighbors returns the neighbor entry for the container's IPv4 address
+// and, on a dual-stack network, its IPv6 address.
+func toNeighbors(sandboxName string, container models.Container) ([]watcher.Neighbor, error) {
+	ip := net.ParseIP(container.IP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %q", container.IP)
+	}
+
+	mac, err := net.ParseMAC(container.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("parse mac: %s", err)
+	}
+
+	vtep := net.ParseIP(container.HostIP)
+	if vtep == nil {
+		return nil, fmt.Errorf("invalid host ip %q", container.HostIP)
+	}
+
+	neighbors := []watcher.Neighbor{{
+		SandboxName: sandboxName,
+		VTEP:        vtep,
+		Neigh: watcher.Neigh{
+			Family:       nl.FAMILY_V4,
+			IP:           ip,
+			HardwareAddr: mac,
+		},
+	}}
+
+	if container.IP6 != "" {
+		ip6 := net.ParseIP(container.IP6)
+		if ip6 == nil {
+			return nil, fmt.Errorf("invalid ip6 %q", container.IP6)
+		}
+
+		neighbors = append(neighbors, watcher.Neighbor{
+			SandboxName: sandboxName,
+			VTEP:        vtep,
+			Neigh: watcher.Neigh{
+				Family:       nl.FAMILY_V6,
+				IP:           ip6,
+				HardwareAddr: mac,
+			},
+		})
+	}
+
+	return neighbors, nil
+}
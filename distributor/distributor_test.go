@@ -0,0 +1,266 @@
+package distributor_test
+
+import (
+	"errors"
+	"net"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/distributor"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Distributor", func() {
+	var (
+		networkMapper *fakes.NetworkMapper
+		sandboxRepo   *fakes.SandboxRepository
+		sbox          *fakes.Sandbox
+		ns            *fakes.Namespace
+		neighbors     *fakes.NeighborManager
+		resolverCache *fakes.CacheInvalidator
+		dist          *distributor.Distributor
+		event         models.ContainerEvent
+		expected      watcher.Neighbor
+	)
+
+	BeforeEach(func() {
+		networkMapper = &fakes.NetworkMapper{}
+		sandboxRepo = &fakes.SandboxRepository{}
+		sbox = &fakes.Sandbox{}
+		ns = &fakes.Namespace{}
+		neighbors = &fakes.NeighborManager{}
+		resolverCache = &fakes.CacheInvalidator{}
+
+		networkMapper.GetVNIReturns(42, nil)
+		sandboxRepo.GetReturns(sbox, nil)
+		sbox.NamespaceReturns(ns)
+
+		dist = &distributor.Distributor{
+			Logger:        lagertest.NewTestLogger("test"),
+			HostIP:        net.ParseIP("10.0.0.1"),
+			NetworkMapper: networkMapper,
+			SandboxRepo:   sandboxRepo,
+			Neighbors:     neighbors,
+			ResolverCache: resolverCache,
+		}
+
+		event = models.ContainerEvent{
+			Action: models.ContainerCreated,
+			Container: models.Container{
+				ID:          "some-container-id",
+				IP:          "192.168.1.2",
+				MAC:         "aa:bb:cc:dd:ee:ff",
+				HostIP:      "10.0.0.2",
+				NetworkID:   "some-network-id",
+				SandboxName: "vni-42",
+			},
+		}
+
+		mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+		expected = watcher.Neighbor{
+			SandboxName: "vni-42",
+			VTEP:        net.ParseIP("10.0.0.2"),
+			Neigh: watcher.Neigh{
+				Family:       nl.FAMILY_V4,
+				IP:           net.ParseIP("192.168.1.2"),
+				HardwareAddr: mac,
+			},
+		}
+	})
+
+	It("uses the network id to find the sandbox", func() {
+		Expect(dist.Distribute(event)).To(Succeed())
+
+		Expect(networkMapper.GetVNIArgsForCall(0)).To(Equal("some-network-id"))
+		Expect(sandboxRepo.GetArgsForCall(0)).To(Equal("vni-42"))
+	})
+
+	It("invalidates any cached resolution for the container", func() {
+		Expect(dist.Distribute(event)).To(Succeed())
+
+		Expect(resolverCache.InvalidateCallCount()).To(Equal(1))
+		sandboxName, ip := resolverCache.InvalidateArgsForCall(0)
+		Expect(sandboxName).To(Equal("vni-42"))
+		Expect(ip).To(Equal("192.168.1.2"))
+	})
+
+	Context("when a container is created", func() {
+		It("adds the neighbor to the sandbox while holding the sandbox lock", func() {
+			neighbors.AddNeighborStub = func(_ namespace.Namespace, _ string, _ watcher.Neighbor) error {
+				Expect(sbox.LockCallCount()).To(Equal(1))
+				Expect(sbox.UnlockCallCount()).To(Equal(0))
+				return nil
+			}
+
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(neighbors.AddNeighborCallCount()).To(Equal(1))
+			n, vxlanName, neighbor := neighbors.AddNeighborArgsForCall(0)
+			Expect(n).To(Equal(ns))
+			Expect(vxlanName).To(Equal("vxlan42"))
+			Expect(neighbor).To(Equal(expected))
+
+			Expect(sbox.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when adding the neighbor fails", func() {
+			BeforeEach(func() {
+				neighbors.AddNeighborReturns(errors.New("potato"))
+			})
+
+			It("returns a meaningful error", func() {
+				Expect(dist.Distribute(event)).To(MatchError("add neighbor: potato"))
+				Expect(sbox.UnlockCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("when the container has an IPv6 address", func() {
+		var expected6 watcher.Neighbor
+
+		BeforeEach(func() {
+			event.Container.IP6 = "fd00::2"
+
+			expected6 = expected
+			expected6.Neigh.Family = nl.FAMILY_V6
+			expected6.Neigh.IP = net.ParseIP("fd00::2")
+		})
+
+		It("invalidates the cached resolutions for both addresses", func() {
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(resolverCache.InvalidateCallCount()).To(Equal(2))
+			_, ip := resolverCache.InvalidateArgsForCall(1)
+			Expect(ip).To(Equal("fd00::2"))
+		})
+
+		It("adds a neighbor for each address", func() {
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(neighbors.AddNeighborCallCount()).To(Equal(2))
+			_, _, neighbor := neighbors.AddNeighborArgsForCall(0)
+			Expect(neighbor).To(Equal(expected))
+			_, _, neighbor = neighbors.AddNeighborArgsForCall(1)
+			Expect(neighbor).To(Equal(expected6))
+		})
+
+		It("removes a neighbor for each address", func() {
+			event.Action = models.ContainerDeleted
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(neighbors.RemoveNeighborCallCount()).To(Equal(2))
+			_, _, neighbor := neighbors.RemoveNeighborArgsForCall(1)
+			Expect(neighbor).To(Equal(expected6))
+		})
+
+		It("rejects an invalid IPv6 address", func() {
+			event.Container.IP6 = "potato"
+			Expect(dist.Distribute(event)).To(MatchError(`invalid ip6 "potato"`))
+		})
+	})
+
+	Context("when a container is deleted", func() {
+		BeforeEach(func() {
+			event.Action = models.ContainerDeleted
+		})
+
+		It("removes the neighbor from the sandbox", func() {
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(neighbors.AddNeighborCallCount()).To(Equal(0))
+			Expect(neighbors.RemoveNeighborCallCount()).To(Equal(1))
+			n, vxlanName, neighbor := neighbors.RemoveNeighborArgsForCall(0)
+			Expect(n).To(Equal(ns))
+			Expect(vxlanName).To(Equal("vxlan42"))
+			Expect(neighbor).To(Equal(expected))
+		})
+
+		Context("when removing the neighbor fails", func() {
+			BeforeEach(func() {
+				neighbors.RemoveNeighborReturns(errors.New("potato"))
+			})
+
+			It("returns a meaningful error", func() {
+				Expect(dist.Distribute(event)).To(MatchError("remove neighbor: potato"))
+			})
+		})
+	})
+
+	Context("when the container is on this host", func() {
+		BeforeEach(func() {
+			event.Container.HostIP = "10.0.0.1"
+		})
+
+		It("does nothing", func() {
+			Expect(dist.Distribute(event)).To(Succeed())
+
+			Expect(sandboxRepo.GetCallCount()).To(Equal(0))
+			Expect(neighbors.AddNeighborCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when there is no local sandbox for the network", func() {
+		BeforeEach(func() {
+			sandboxRepo.GetReturns(nil, sandbox.NotFoundError)
+		})
+
+		It("does nothing", func() {
+			Expect(dist.Distribute(event)).To(Succeed())
+			Expect(neighbors.AddNeighborCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the action is unknown", func() {
+		BeforeEach(func() {
+			event.Action = "update"
+		})
+
+		It("returns an error", func() {
+			Expect(dist.Distribute(event)).To(MatchError(`unknown action "update"`))
+		})
+	})
+
+	Context("when getting the VNI fails", func() {
+		BeforeEach(func() {
+			networkMapper.GetVNIReturns(0, errors.New("kiwi"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(dist.Distribute(event)).To(MatchError("get vni: kiwi"))
+		})
+	})
+
+	Context("when getting the sandbox fails", func() {
+		BeforeEach(func() {
+			sandboxRepo.GetReturns(nil, errors.New("lime"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(dist.Distribute(event)).To(MatchError("get sandbox: lime"))
+		})
+	})
+
+	Context("when the container record is malformed", func() {
+		It("rejects an invalid IP", func() {
+			event.Container.IP = "banana"
+			Expect(dist.Distribute(event)).To(MatchError(`invalid ip "banana"`))
+		})
+
+		It("rejects an invalid MAC", func() {
+			event.Container.MAC = "banana"
+			Expect(dist.Distribute(event)).To(MatchError(ContainSubstring("parse mac:")))
+		})
+
+		It("rejects an invalid host IP", func() {
+			event.Container.HostIP = "banana"
+			Expect(dist.Distribute(event)).To(MatchError(`invalid host ip "banana"`))
+		})
+	})
+})
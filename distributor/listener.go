@@ -0,0 +1,78 @@
+package distributor
+
+import (
+	"fmt"
+	"lib/marshal"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter -o ../fakes/pq_listener.go --fake-name PQListener . pqListener
+type pqListener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+//go:generate counterfeiter -o ../fakes/distributor.go --fake-name Distributor . distributor
+type distributor interface {
+	Distribute(event models.ContainerEvent) error
+}
+
+// Listener receives container events from the datastore and hands them to
+// the distributor. It is an ifrit runner.
+type Listener struct {
+	Logger      lager.Logger
+	PQListener  pqListener
+	Unmarshaler marshal.Unmarshaler
+	Distributor distributor
+}
+
+func (l *Listener) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := l.Logger.Session("container-event-listener")
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	err := l.PQListener.Listen(store.ContainerEventChannel)
+	if err != nil {
+		return fmt.Errorf("listen: %s", err)
+	}
+
+	close(ready)
+
+	notifications := l.PQListener.NotificationChannel()
+	for {
+		select {
+		case <-signals:
+			return l.PQListener.Close()
+
+		case notification, ok := <-notifications:
+			if !ok {
+				return fmt.Errorf("notification channel closed")
+			}
+
+			// a nil notification means the connection was re-established and
+			// events may have been missed; the miss watcher covers the gap
+			if notification == nil {
+				logger.Info("reconnected")
+				continue
+			}
+
+			var event models.ContainerEvent
+			err := l.Unmarshaler.Unmarshal([]byte(notification.Extra), &event)
+			if err != nil {
+				logger.Error("unmarshal-failed", err, lager.Data{"payload": notification.Extra})
+				continue
+			}
+
+			err = l.Distributor.Distribute(event)
+			if err != nil {
+				logger.Error("distribute-failed", err, lager.Data{"event": event})
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package distributor_test
+
+import (
+	"encoding/json"
+	"errors"
+	"lib/marshal"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/distributor"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Listener", func() {
+	var (
+		logger        *lagertest.TestLogger
+		pqListener    *fakes.PQListener
+		dist          *fakes.Distributor
+		notifications chan *pq.Notification
+		listener      *distributor.Listener
+		process       ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		pqListener = &fakes.PQListener{}
+		dist = &fakes.Distributor{}
+
+		notifications = make(chan *pq.Notification)
+		pqListener.NotificationChannelReturns(notifications)
+
+		listener = &distributor.Listener{
+			Logger:      logger,
+			PQListener:  pqListener,
+			Unmarshaler: marshal.UnmarshalFunc(json.Unmarshal),
+			Distributor: dist,
+		}
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Background(listener)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("listens on the container event channel before becoming ready", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		Expect(pqListener.ListenCallCount()).To(Equal(1))
+		Expect(pqListener.ListenArgsForCall(0)).To(Equal(store.ContainerEventChannel))
+	})
+
+	It("distributes each event it is notified of", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		notifications <- &pq.Notification{
+			Channel: store.ContainerEventChannel,
+			Extra:   `{"action": "create", "container": {"id": "some-id", "ip": "192.168.1.2"}}`,
+		}
+
+		Eventually(dist.DistributeCallCount).Should(Equal(1))
+		Expect(dist.DistributeArgsForCall(0)).To(Equal(models.ContainerEvent{
+			Action:    models.ContainerCreated,
+			Container: models.Container{ID: "some-id", IP: "192.168.1.2"},
+		}))
+	})
+
+	It("closes the pq listener when signaled", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(pqListener.CloseCallCount()).To(Equal(1))
+	})
+
+	Context("when the connection is re-established", func() {
+		It("logs and keeps listening", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			notifications <- nil
+			Eventually(logger).Should(gbytes.Say("reconnected"))
+
+			notifications <- &pq.Notification{Extra: `{"action": "delete"}`}
+			Eventually(dist.DistributeCallCount).Should(Equal(1))
+		})
+	})
+
+	Context("when the payload cannot be unmarshaled", func() {
+		It("logs the error and keeps listening", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			notifications <- &pq.Notification{Extra: `{{{`}
+			Eventually(logger).Should(gbytes.Say("unmarshal-failed"))
+			Expect(dist.DistributeCallCount()).To(Equal(0))
+
+			notifications <- &pq.Notification{Extra: `{"action": "delete"}`}
+			Eventually(dist.DistributeCallCount).Should(Equal(1))
+		})
+	})
+
+	Context("when distributing fails", func() {
+		BeforeEach(func() {
+			dist.DistributeReturns(errors.New("potato"))
+		})
+
+		It("logs the error and keeps listening", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			notifications <- &pq.Notification{Extra: `{"action": "create"}`}
+			Eventually(logger).Should(gbytes.Say("distribute-failed.*potato"))
+
+			notifications <- &pq.Notification{Extra: `{"action": "create"}`}
+			Eventually(dist.DistributeCallCount).Should(Equal(2))
+		})
+	})
+
+	Context("when listening fails", func() {
+		BeforeEach(func() {
+			pqListener.ListenReturns(errors.New("kiwi"))
+		})
+
+		It("exits with a meaningful error without becoming ready", func() {
+			Eventually(process.Wait()).Should(Receive(MatchError("listen: kiwi")))
+			Expect(process.Ready()).NotTo(BeClosed())
+		})
+	})
+
+	Context("when the notification channel is closed", func() {
+		It("exits with an error", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			close(notifications)
+			Eventually(process.Wait()).Should(Receive(MatchError("notification channel closed")))
+		})
+	})
+})
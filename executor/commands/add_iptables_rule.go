@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+// AddIPTablesRule appends a rule to a chain unless the chain already has it.
+type AddIPTablesRule struct {
+	Table string
+	Chain string
+	Rule  []string
+}
+
+func (a AddIPTablesRule) Execute(context executor.Context) error {
+	err := context.Firewall().EnsureRule(a.Table, a.Chain, a.Rule)
+	if err != nil {
+		return fmt.Errorf("add iptables rule: %s", err)
+	}
+
+	return nil
+}
+
+func (a AddIPTablesRule) String() string {
+	return fmt.Sprintf("iptables -t %s -A %s %s", a.Table, a.Chain, strings.Join(a.Rule, " "))
+}
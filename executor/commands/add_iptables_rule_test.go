@@ -0,0 +1,58 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AddIPTablesRule", func() {
+	var (
+		firewall *fakes.Firewall
+		context  *fakes.Context
+		addRule  commands.AddIPTablesRule
+	)
+
+	BeforeEach(func() {
+		context = &fakes.Context{}
+		firewall = &fakes.Firewall{}
+		context.FirewallReturns(firewall)
+
+		addRule = commands.AddIPTablesRule{
+			Table: "nat",
+			Chain: "POSTROUTING",
+			Rule:  []string{"-o", "some-link", "-j", "MASQUERADE"},
+		}
+	})
+
+	It("ensures the rule through the firewall", func() {
+		err := addRule.Execute(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(firewall.EnsureRuleCallCount()).To(Equal(1))
+		table, chain, rule := firewall.EnsureRuleArgsForCall(0)
+		Expect(table).To(Equal("nat"))
+		Expect(chain).To(Equal("POSTROUTING"))
+		Expect(rule).To(Equal([]string{"-o", "some-link", "-j", "MASQUERADE"}))
+	})
+
+	Context("when adding the rule fails", func() {
+		BeforeEach(func() {
+			firewall.EnsureRuleReturns(errors.New("no chain"))
+		})
+
+		It("wraps and propagates the error", func() {
+			err := addRule.Execute(context)
+			Expect(err).To(MatchError("add iptables rule: no chain"))
+		})
+	})
+
+	Describe("String", func() {
+		It("describes itself", func() {
+			Expect(addRule.String()).To(Equal("iptables -t nat -A POSTROUTING -o some-link -j MASQUERADE"))
+		})
+	})
+})
@@ -12,6 +12,15 @@ import (
 type CleanupSandbox struct {
 	SandboxName     string
 	VxlanDeviceName string
+
+	// EgressLinkName is the sandbox end of the link egress sets up to the
+	// host. It does not belong to a container, so it does not keep the
+	// sandbox alive; it is deleted with the vxlan device.
+	EgressLinkName string
+
+	// EgressTeardown runs in the host namespace once the sandbox is empty,
+	// before the sandbox is destroyed.
+	EgressTeardown executor.Command
 }
 
 func (c CleanupSandbox) Execute(context executor.Context) error {
@@ -39,6 +48,21 @@ func (c CleanupSandbox) Execute(context executor.Context) error {
 		return fmt.Errorf("counting veth devices: %s", err)
 	}
 
+	var egressLinkExists bool
+	if c.EgressLinkName != "" {
+		err = sbox.Namespace().Execute(func(*os.File) error {
+			egressLinkExists = context.LinkFactory().Exists(c.EgressLinkName)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("in namespace %s: %s", c.SandboxName, err)
+		}
+
+		if egressLinkExists {
+			vethLinkCount--
+		}
+	}
+
 	logger.Info("veth-links-remaining", lager.Data{"count": vethLinkCount})
 
 	if vethLinkCount == 0 {
@@ -49,12 +73,26 @@ func (c CleanupSandbox) Execute(context executor.Context) error {
 					return fmt.Errorf("destroying vxlan %s: %s", c.VxlanDeviceName, err)
 				}
 			}
+
+			if egressLinkExists {
+				err := context.LinkFactory().DeleteLinkByName(c.EgressLinkName)
+				if err != nil {
+					return fmt.Errorf("destroying egress link %s: %s", c.EgressLinkName, err)
+				}
+			}
 			return nil
 		})
 		if err != nil {
 			return fmt.Errorf("in namespace %s: %s", c.SandboxName, err)
 		}
 
+		if c.EgressTeardown != nil {
+			err = c.EgressTeardown.Execute(context)
+			if err != nil {
+				return fmt.Errorf("egress teardown: %s", err)
+			}
+		}
+
 		err = sandboxRepo.Destroy(c.SandboxName)
 		switch err {
 		case nil:
@@ -250,6 +250,93 @@ var _ = Describe("CleanupSandbox", func() {
 		})
 	})
 
+	Context("when the sandbox has an egress link", func() {
+		var egressTeardown *fakes.Command
+
+		BeforeEach(func() {
+			egressTeardown = &fakes.Command{}
+			cleanupSandboxCommand.EgressLinkName = "egs-some-sandbox"
+			cleanupSandboxCommand.EgressTeardown = egressTeardown
+
+			linkFactory.ExistsStub = func(name string) bool {
+				return name == "egs-some-sandbox"
+			}
+			sbox.VethDeviceCountReturns(1, nil)
+		})
+
+		It("does not count the egress link as a container", func() {
+			err := cleanupSandboxCommand.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sandboxRepo.DestroyCallCount()).To(Equal(1))
+		})
+
+		It("removes the egress link with the vxlan device", func() {
+			err := cleanupSandboxCommand.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(linkFactory.DeleteLinkByNameCallCount()).To(Equal(2))
+			Expect(linkFactory.DeleteLinkByNameArgsForCall(0)).To(Equal("some-vxlan"))
+			Expect(linkFactory.DeleteLinkByNameArgsForCall(1)).To(Equal("egs-some-sandbox"))
+		})
+
+		It("tears down egress before destroying the sandbox", func() {
+			sandboxRepo.DestroyStub = func(string) error {
+				Expect(egressTeardown.ExecuteCallCount()).To(Equal(1))
+				return nil
+			}
+
+			err := cleanupSandboxCommand.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(egressTeardown.ExecuteArgsForCall(0)).To(Equal(context))
+		})
+
+		Context("when a container is still attached", func() {
+			BeforeEach(func() {
+				sbox.VethDeviceCountReturns(2, nil)
+			})
+
+			It("leaves egress alone", func() {
+				err := cleanupSandboxCommand.Execute(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(linkFactory.DeleteLinkByNameCallCount()).To(Equal(0))
+				Expect(egressTeardown.ExecuteCallCount()).To(Equal(0))
+				Expect(sandboxRepo.DestroyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the egress link cannot be deleted", func() {
+			BeforeEach(func() {
+				linkFactory.DeleteLinkByNameStub = func(name string) error {
+					if name == "egs-some-sandbox" {
+						return errors.New("busy")
+					}
+					return nil
+				}
+			})
+
+			It("wraps and returns the error", func() {
+				err := cleanupSandboxCommand.Execute(context)
+				Expect(err).To(MatchError("in namespace sandbox-name: callback failed: destroying egress link egs-some-sandbox: busy"))
+				Expect(egressTeardown.ExecuteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the egress teardown fails", func() {
+			BeforeEach(func() {
+				egressTeardown.ExecuteReturns(errors.New("potato"))
+			})
+
+			It("wraps and returns the error", func() {
+				err := cleanupSandboxCommand.Execute(context)
+				Expect(err).To(MatchError("egress teardown: potato"))
+				Expect(sandboxRepo.DestroyCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("String", func() {
 		It("describes itself", func() {
 			Expect(cleanupSandboxCommand.String()).To(Equal("cleanup-sandbox sandbox-name"))
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+type EnableIPForwarding struct{}
+
+func (EnableIPForwarding) Execute(context executor.Context) error {
+	err := context.Firewall().EnableForwarding()
+	if err != nil {
+		return fmt.Errorf("enable ip forwarding: %s", err)
+	}
+
+	return nil
+}
+
+func (EnableIPForwarding) String() string {
+	return "sysctl -w net.ipv4.ip_forward=1"
+}
@@ -0,0 +1,50 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnableIPForwarding", func() {
+	var (
+		firewall *fakes.Firewall
+		context  *fakes.Context
+		enable   commands.EnableIPForwarding
+	)
+
+	BeforeEach(func() {
+		context = &fakes.Context{}
+		firewall = &fakes.Firewall{}
+		context.FirewallReturns(firewall)
+
+		enable = commands.EnableIPForwarding{}
+	})
+
+	It("enables forwarding through the firewall", func() {
+		err := enable.Execute(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(firewall.EnableForwardingCallCount()).To(Equal(1))
+	})
+
+	Context("when enabling forwarding fails", func() {
+		BeforeEach(func() {
+			firewall.EnableForwardingReturns(errors.New("read-only file system"))
+		})
+
+		It("wraps and propagates the error", func() {
+			err := enable.Execute(context)
+			Expect(err).To(MatchError("enable ip forwarding: read-only file system"))
+		})
+	})
+
+	Describe("String", func() {
+		It("describes itself", func() {
+			Expect(enable.String()).To(Equal("sysctl -w net.ipv4.ip_forward=1"))
+		})
+	})
+})
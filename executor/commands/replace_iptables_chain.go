@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+// ReplaceIPTablesChain replaces every rule of a chain and makes sure the
+// parent chain jumps to it.
+type ReplaceIPTablesChain struct {
+	Table  string
+	Chain  string
+	Parent string
+	Rules  [][]string
+}
+
+func (r ReplaceIPTablesChain) Execute(context executor.Context) error {
+	err := context.Firewall().ReplaceChain(r.Table, r.Chain, r.Parent, r.Rules)
+	if err != nil {
+		return fmt.Errorf("replace iptables chain: %s", err)
+	}
+
+	return nil
+}
+
+func (r ReplaceIPTablesChain) String() string {
+	return fmt.Sprintf("iptables-restore --noflush # %d rules in %s/%s from %s", len(r.Rules), r.Table, r.Chain, r.Parent)
+}
@@ -0,0 +1,62 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplaceIPTablesChain", func() {
+	var (
+		firewall     *fakes.Firewall
+		context      *fakes.Context
+		replaceChain commands.ReplaceIPTablesChain
+	)
+
+	BeforeEach(func() {
+		context = &fakes.Context{}
+		firewall = &fakes.Firewall{}
+		context.FirewallReturns(firewall)
+
+		replaceChain = commands.ReplaceIPTablesChain{
+			Table:  "filter",
+			Chain:  "some-chain",
+			Parent: "FORWARD",
+			Rules: [][]string{
+				{"-d", "10.0.0.0/8", "-j", "REJECT"},
+			},
+		}
+	})
+
+	It("replaces the chain through the firewall", func() {
+		err := replaceChain.Execute(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(firewall.ReplaceChainCallCount()).To(Equal(1))
+		table, chain, parent, rules := firewall.ReplaceChainArgsForCall(0)
+		Expect(table).To(Equal("filter"))
+		Expect(chain).To(Equal("some-chain"))
+		Expect(parent).To(Equal("FORWARD"))
+		Expect(rules).To(Equal([][]string{{"-d", "10.0.0.0/8", "-j", "REJECT"}}))
+	})
+
+	Context("when replacing the chain fails", func() {
+		BeforeEach(func() {
+			firewall.ReplaceChainReturns(errors.New("bad rule"))
+		})
+
+		It("wraps and propagates the error", func() {
+			err := replaceChain.Execute(context)
+			Expect(err).To(MatchError("replace iptables chain: bad rule"))
+		})
+	})
+
+	Describe("String", func() {
+		It("describes itself", func() {
+			Expect(replaceChain.String()).To(Equal("iptables-restore --noflush # 1 rules in filter/some-chain from FORWARD"))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+//go:generate counterfeiter -o ../../fakes/egress_link_releaser.go --fake-name EgressLinkReleaser . egressLinkReleaser
+type egressLinkReleaser interface {
+	Release(sandboxName string) (bool, bool, error)
+}
+
+// TeardownEgress gives back the egress link of a sandbox that is being
+// destroyed. When it was the last egress link on the host, the host's
+// masquerade rule is removed and forwarding is turned back off unless the
+// host was already forwarding before egress was set up.
+type TeardownEgress struct {
+	Links          egressLinkReleaser
+	SandboxName    string
+	MasqueradeRule []string
+}
+
+func (t TeardownEgress) Execute(context executor.Context) error {
+	last, hostForwarding, err := t.Links.Release(t.SandboxName)
+	if err != nil {
+		return fmt.Errorf("release egress link: %s", err)
+	}
+
+	if !last {
+		return nil
+	}
+
+	err = context.Firewall().DeleteRule("nat", "POSTROUTING", t.MasqueradeRule)
+	if err != nil {
+		return fmt.Errorf("delete masquerade rule: %s", err)
+	}
+
+	if hostForwarding {
+		return nil
+	}
+
+	err = context.Firewall().DisableForwarding()
+	if err != nil {
+		return fmt.Errorf("disable ip forwarding: %s", err)
+	}
+
+	return nil
+}
+
+func (t TeardownEgress) String() string {
+	return fmt.Sprintf("teardown-egress %s", t.SandboxName)
+}
@@ -0,0 +1,133 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/commands"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TeardownEgress", func() {
+	var (
+		firewall *fakes.Firewall
+		context  *fakes.Context
+		links    *fakes.EgressLinkReleaser
+		teardown commands.TeardownEgress
+	)
+
+	BeforeEach(func() {
+		context = &fakes.Context{}
+		firewall = &fakes.Firewall{}
+		context.FirewallReturns(firewall)
+		links = &fakes.EgressLinkReleaser{}
+
+		teardown = commands.TeardownEgress{
+			Links:          links,
+			SandboxName:    "vni-some-sandbox",
+			MasqueradeRule: []string{"-s", "169.254.0.0/17", "-j", "MASQUERADE"},
+		}
+	})
+
+	It("releases the egress link of the sandbox", func() {
+		err := teardown.Execute(context)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(links.ReleaseCallCount()).To(Equal(1))
+		Expect(links.ReleaseArgsForCall(0)).To(Equal("vni-some-sandbox"))
+	})
+
+	Context("when other egress links remain on the host", func() {
+		BeforeEach(func() {
+			links.ReleaseReturns(false, false, nil)
+		})
+
+		It("leaves the host firewall alone", func() {
+			err := teardown.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(firewall.DeleteRuleCallCount()).To(Equal(0))
+			Expect(firewall.DisableForwardingCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the last egress link on the host is released", func() {
+		BeforeEach(func() {
+			links.ReleaseReturns(true, false, nil)
+		})
+
+		It("deletes the masquerade rule", func() {
+			err := teardown.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(firewall.DeleteRuleCallCount()).To(Equal(1))
+			table, chain, rule := firewall.DeleteRuleArgsForCall(0)
+			Expect(table).To(Equal("nat"))
+			Expect(chain).To(Equal("POSTROUTING"))
+			Expect(rule).To(Equal([]string{"-s", "169.254.0.0/17", "-j", "MASQUERADE"}))
+		})
+
+		It("turns forwarding back off", func() {
+			err := teardown.Execute(context)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(firewall.DisableForwardingCallCount()).To(Equal(1))
+		})
+
+		Context("when the host was forwarding before egress", func() {
+			BeforeEach(func() {
+				links.ReleaseReturns(true, true, nil)
+			})
+
+			It("leaves forwarding on", func() {
+				err := teardown.Execute(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(firewall.DeleteRuleCallCount()).To(Equal(1))
+				Expect(firewall.DisableForwardingCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when deleting the masquerade rule fails", func() {
+			BeforeEach(func() {
+				firewall.DeleteRuleReturns(errors.New("no chain"))
+			})
+
+			It("wraps and propagates the error", func() {
+				err := teardown.Execute(context)
+				Expect(err).To(MatchError("delete masquerade rule: no chain"))
+				Expect(firewall.DisableForwardingCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when disabling forwarding fails", func() {
+			BeforeEach(func() {
+				firewall.DisableForwardingReturns(errors.New("read-only"))
+			})
+
+			It("wraps and propagates the error", func() {
+				err := teardown.Execute(context)
+				Expect(err).To(MatchError("disable ip forwarding: read-only"))
+			})
+		})
+	})
+
+	Context("when releasing the link fails", func() {
+		BeforeEach(func() {
+			links.ReleaseReturns(false, false, errors.New("potato"))
+		})
+
+		It("wraps and propagates the error", func() {
+			err := teardown.Execute(context)
+			Expect(err).To(MatchError("release egress link: potato"))
+			Expect(firewall.DeleteRuleCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("String", func() {
+		It("describes itself", func() {
+			Expect(teardown.String()).To(Equal("teardown-egress vni-some-sandbox"))
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package conditions
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+type Not struct {
+	Condition executor.Condition
+}
+
+func (n Not) Satisfied(context executor.Context) (bool, error) {
+	satisfied, err := n.Condition.Satisfied(context)
+	if err != nil {
+		return false, err
+	}
+	return !satisfied, nil
+}
+
+func (n Not) String() string {
+	return fmt.Sprintf("not %s", n.Condition)
+}
@@ -0,0 +1,56 @@
+package conditions_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor/conditions"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Not", func() {
+	var (
+		context   *fakes.Context
+		condition *fakes.Condition
+		not       conditions.Not
+	)
+
+	BeforeEach(func() {
+		context = &fakes.Context{}
+		condition = &fakes.Condition{}
+		condition.StringReturns(`check if link "my-interface" exists`)
+
+		not = conditions.Not{
+			Condition: condition,
+		}
+	})
+
+	It("negates the wrapped condition", func() {
+		condition.SatisfiedReturns(true, nil)
+		Expect(not.Satisfied(context)).To(BeFalse())
+
+		condition.SatisfiedReturns(false, nil)
+		Expect(not.Satisfied(context)).To(BeTrue())
+
+		Expect(condition.SatisfiedCallCount()).To(Equal(2))
+		Expect(condition.SatisfiedArgsForCall(0)).To(Equal(context))
+	})
+
+	Context("when the wrapped condition fails", func() {
+		BeforeEach(func() {
+			condition.SatisfiedReturns(false, errors.New("potato"))
+		})
+
+		It("returns the error", func() {
+			_, err := not.Satisfied(context)
+			Expect(err).To(MatchError("potato"))
+		})
+	})
+
+	Context("String", func() {
+		It("describes itself", func() {
+			Expect(not.String()).To(Equal(`not check if link "my-interface" exists`))
+		})
+	})
+})
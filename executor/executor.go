@@ -34,6 +34,15 @@ type LinkFactory interface {
 	VethDeviceCount() (int, error)
 }
 
+//go:generate counterfeiter -o ../fakes/firewall.go --fake-name Firewall . Firewall
+type Firewall interface {
+	EnableForwarding() error
+	DisableForwarding() error
+	EnsureRule(table, chain string, rulespec []string) error
+	DeleteRule(table, chain string, rulespec []string) error
+	ReplaceChain(table, chain, parent string, rules [][]string) error
+}
+
 //go:generate counterfeiter -o ../fakes/listener_factory.go --fake-name ListenerFactory . ListenerFactory
 type ListenerFactory interface {
 	ListenUDP(network string, address *net.UDPAddr) (*net.UDPConn, error)
@@ -84,6 +93,7 @@ type Context interface {
 	SandboxRepository() SandboxRepository
 	ListenerFactory() ListenerFactory
 	DNSServerFactory() DNSServerFactory
+	Firewall() Firewall
 }
 
 type executor struct {
@@ -99,6 +109,7 @@ func New(
 	sandboxRepository SandboxRepository,
 	listenerFactory ListenerFactory,
 	dnsServerFactory DNSServerFactory,
+	firewall Firewall,
 ) Executor {
 	return &executor{
 		context: context{
@@ -110,6 +121,7 @@ func New(
 			sandboxRepository:          sandboxRepository,
 			listenerFactory:            listenerFactory,
 			dnsServerFactory:           dnsServerFactory,
+			firewall:                   firewall,
 		},
 	}
 }
@@ -133,6 +145,7 @@ type context struct {
 	sandboxRepository          SandboxRepository
 	listenerFactory            ListenerFactory
 	dnsServerFactory           DNSServerFactory
+	firewall                   Firewall
 }
 
 func (e *context) AddressManager() AddressManager {
@@ -163,6 +176,10 @@ func (e *context) DNSServerFactory() DNSServerFactory {
 	return e.dnsServerFactory
 }
 
+func (e *context) Firewall() Firewall {
+	return e.firewall
+}
+
 func (e *context) Logger() lager.Logger {
 	return e.logger
 }
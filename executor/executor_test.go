@@ -18,6 +18,7 @@ var _ = Describe("Executor", func() {
 		sandboxRepository          *fakes.SandboxRepository
 		listenerFactory            *fakes.ListenerFactory
 		dnsServerFactory           *fakes.DNSServerFactory
+		firewall                   *fakes.Firewall
 		command                    *fakes.Command
 		ex                         executor.Executor
 	)
@@ -31,6 +32,7 @@ var _ = Describe("Executor", func() {
 		sandboxRepository = &fakes.SandboxRepository{}
 		listenerFactory = &fakes.ListenerFactory{}
 		dnsServerFactory = &fakes.DNSServerFactory{}
+		firewall = &fakes.Firewall{}
 
 		command = &fakes.Command{}
 
@@ -43,6 +45,7 @@ var _ = Describe("Executor", func() {
 			sandboxRepository,
 			listenerFactory,
 			dnsServerFactory,
+			firewall,
 		)
 	})
 
@@ -105,6 +108,12 @@ var _ = Describe("Executor", func() {
 			})
 		})
 
+		Describe("Firewall", func() {
+			It("returns the Firewall", func() {
+				Expect(context.Firewall()).To(Equal(firewall))
+			})
+		})
+
 		Describe("Logger", func() {
 			It("returns the Logger with a new session", func() {
 				Expect(context.Logger().SessionName()).NotTo(Equal(logger.SessionName()))
@@ -27,13 +27,31 @@ type AllocatorStore struct {
 	releaseByIDReturns struct {
 		result1 error
 	}
-	ContainsStub        func(id string) bool
+	ContainsStub        func(id string) (bool, error)
 	containsMutex       sync.RWMutex
 	containsArgsForCall []struct {
 		id string
 	}
 	containsReturns struct {
 		result1 bool
+		result2 error
+	}
+	ReserveNextStub        func(id string, pool *ipam.Pool) (net.IP, error)
+	reserveNextMutex       sync.RWMutex
+	reserveNextArgsForCall []struct {
+		id   string
+		pool *ipam.Pool
+	}
+	reserveNextReturns struct {
+		result1 net.IP
+		result2 error
+	}
+	ReservationsStub        func() ([]ipam.Reservation, error)
+	reservationsMutex       sync.RWMutex
+	reservationsArgsForCall []struct{}
+	reservationsReturns     struct {
+		result1 []ipam.Reservation
+		result2 error
 	}
 }
 
@@ -103,7 +121,7 @@ func (fake *AllocatorStore) ReleaseByIDReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *AllocatorStore) Contains(id string) bool {
+func (fake *AllocatorStore) Contains(id string) (bool, error) {
 	fake.containsMutex.Lock()
 	fake.containsArgsForCall = append(fake.containsArgsForCall, struct {
 		id string
@@ -112,7 +130,7 @@ func (fake *AllocatorStore) Contains(id string) bool {
 	if fake.ContainsStub != nil {
 		return fake.ContainsStub(id)
 	} else {
-		return fake.containsReturns.result1
+		return fake.containsReturns.result1, fake.containsReturns.result2
 	}
 }
 
@@ -128,11 +146,71 @@ func (fake *AllocatorStore) ContainsArgsForCall(i int) string {
 	return fake.containsArgsForCall[i].id
 }
 
-func (fake *AllocatorStore) ContainsReturns(result1 bool) {
+func (fake *AllocatorStore) ContainsReturns(result1 bool, result2 error) {
 	fake.ContainsStub = nil
 	fake.containsReturns = struct {
 		result1 bool
-	}{result1}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AllocatorStore) ReserveNext(id string, pool *ipam.Pool) (net.IP, error) {
+	fake.reserveNextMutex.Lock()
+	fake.reserveNextArgsForCall = append(fake.reserveNextArgsForCall, struct {
+		id   string
+		pool *ipam.Pool
+	}{id, pool})
+	fake.reserveNextMutex.Unlock()
+	if fake.ReserveNextStub != nil {
+		return fake.ReserveNextStub(id, pool)
+	} else {
+		return fake.reserveNextReturns.result1, fake.reserveNextReturns.result2
+	}
+}
+
+func (fake *AllocatorStore) ReserveNextCallCount() int {
+	fake.reserveNextMutex.RLock()
+	defer fake.reserveNextMutex.RUnlock()
+	return len(fake.reserveNextArgsForCall)
+}
+
+func (fake *AllocatorStore) ReserveNextArgsForCall(i int) (string, *ipam.Pool) {
+	fake.reserveNextMutex.RLock()
+	defer fake.reserveNextMutex.RUnlock()
+	return fake.reserveNextArgsForCall[i].id, fake.reserveNextArgsForCall[i].pool
+}
+
+func (fake *AllocatorStore) ReserveNextReturns(result1 net.IP, result2 error) {
+	fake.ReserveNextStub = nil
+	fake.reserveNextReturns = struct {
+		result1 net.IP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AllocatorStore) Reservations() ([]ipam.Reservation, error) {
+	fake.reservationsMutex.Lock()
+	fake.reservationsArgsForCall = append(fake.reservationsArgsForCall, struct{}{})
+	fake.reservationsMutex.Unlock()
+	if fake.ReservationsStub != nil {
+		return fake.ReservationsStub()
+	} else {
+		return fake.reservationsReturns.result1, fake.reservationsReturns.result2
+	}
+}
+
+func (fake *AllocatorStore) ReservationsCallCount() int {
+	fake.reservationsMutex.RLock()
+	defer fake.reservationsMutex.RUnlock()
+	return len(fake.reservationsArgsForCall)
+}
+
+func (fake *AllocatorStore) ReservationsReturns(result1 []ipam.Reservation, result2 error) {
+	fake.ReservationsStub = nil
+	fake.reservationsReturns = struct {
+		result1 []ipam.Reservation
+		result2 error
+	}{result1, result2}
 }
 
 var _ ipam.AllocatorStore = new(AllocatorStore)
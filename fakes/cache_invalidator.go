@@ -0,0 +1,37 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type CacheInvalidator struct {
+	InvalidateStub        func(sandboxName string, ip string)
+	invalidateMutex       sync.RWMutex
+	invalidateArgsForCall []struct {
+		sandboxName string
+		ip          string
+	}
+}
+
+func (fake *CacheInvalidator) Invalidate(sandboxName string, ip string) {
+	fake.invalidateMutex.Lock()
+	fake.invalidateArgsForCall = append(fake.invalidateArgsForCall, struct {
+		sandboxName string
+		ip          string
+	}{sandboxName, ip})
+	fake.invalidateMutex.Unlock()
+	if fake.InvalidateStub != nil {
+		fake.InvalidateStub(sandboxName, ip)
+	}
+}
+
+func (fake *CacheInvalidator) InvalidateCallCount() int {
+	fake.invalidateMutex.RLock()
+	defer fake.invalidateMutex.RUnlock()
+	return len(fake.invalidateArgsForCall)
+}
+
+func (fake *CacheInvalidator) InvalidateArgsForCall(i int) (string, string) {
+	fake.invalidateMutex.RLock()
+	defer fake.invalidateMutex.RUnlock()
+	return fake.invalidateArgsForCall[i].sandboxName, fake.invalidateArgsForCall[i].ip
+}
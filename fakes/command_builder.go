@@ -2,7 +2,6 @@
 package fakes
 
 import (
-	"net"
 	"sync"
 
 	"github.com/appc/cni/pkg/types"
@@ -41,13 +40,14 @@ type CommandBuilder struct {
 	addRoutesReturns struct {
 		result1 executor.Command
 	}
-	SetupVethStub        func(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, address net.IPNet, sandboxName string, routeCommand executor.Command) executor.Command
+	SetupVethStub        func(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, mtu int, ipamResult *types.Result, sandboxName string, routeCommand executor.Command) executor.Command
 	setupVethMutex       sync.RWMutex
 	setupVethArgsForCall []struct {
 		containerNS       namespace.Namespace
 		sandboxLinkName   string
 		containerLinkName string
-		address           net.IPNet
+		mtu               int
+		ipamResult        *types.Result
 		sandboxName       string
 		routeCommand      executor.Command
 	}
@@ -66,6 +66,20 @@ type CommandBuilder struct {
 	idempotentlySetupBridgeReturns struct {
 		result1 executor.Command
 	}
+	SetupEgressStub        func(networkID string, link int, sandboxName string, sandboxNS namespace.Namespace, containerNS namespace.Namespace, containerLinkName string, ipamResult *types.Result) executor.Command
+	setupEgressMutex       sync.RWMutex
+	setupEgressArgsForCall []struct {
+		networkID         string
+		link              int
+		sandboxName       string
+		sandboxNS         namespace.Namespace
+		containerNS       namespace.Namespace
+		containerLinkName string
+		ipamResult        *types.Result
+	}
+	setupEgressReturns struct {
+		result1 executor.Command
+	}
 }
 
 func (fake *CommandBuilder) IdempotentlyCreateSandbox(sandboxName string, vxlanName string, vni int, dnsAddress string) executor.Command {
@@ -170,19 +184,20 @@ func (fake *CommandBuilder) AddRoutesReturns(result1 executor.Command) {
 	}{result1}
 }
 
-func (fake *CommandBuilder) SetupVeth(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, address net.IPNet, sandboxName string, routeCommand executor.Command) executor.Command {
+func (fake *CommandBuilder) SetupVeth(containerNS namespace.Namespace, sandboxLinkName string, containerLinkName string, mtu int, ipamResult *types.Result, sandboxName string, routeCommand executor.Command) executor.Command {
 	fake.setupVethMutex.Lock()
 	fake.setupVethArgsForCall = append(fake.setupVethArgsForCall, struct {
 		containerNS       namespace.Namespace
 		sandboxLinkName   string
 		containerLinkName string
-		address           net.IPNet
+		mtu               int
+		ipamResult        *types.Result
 		sandboxName       string
 		routeCommand      executor.Command
-	}{containerNS, sandboxLinkName, containerLinkName, address, sandboxName, routeCommand})
+	}{containerNS, sandboxLinkName, containerLinkName, mtu, ipamResult, sandboxName, routeCommand})
 	fake.setupVethMutex.Unlock()
 	if fake.SetupVethStub != nil {
-		return fake.SetupVethStub(containerNS, sandboxLinkName, containerLinkName, address, sandboxName, routeCommand)
+		return fake.SetupVethStub(containerNS, sandboxLinkName, containerLinkName, mtu, ipamResult, sandboxName, routeCommand)
 	} else {
 		return fake.setupVethReturns.result1
 	}
@@ -194,10 +209,10 @@ func (fake *CommandBuilder) SetupVethCallCount() int {
 	return len(fake.setupVethArgsForCall)
 }
 
-func (fake *CommandBuilder) SetupVethArgsForCall(i int) (namespace.Namespace, string, string, net.IPNet, string, executor.Command) {
+func (fake *CommandBuilder) SetupVethArgsForCall(i int) (namespace.Namespace, string, string, int, *types.Result, string, executor.Command) {
 	fake.setupVethMutex.RLock()
 	defer fake.setupVethMutex.RUnlock()
-	return fake.setupVethArgsForCall[i].containerNS, fake.setupVethArgsForCall[i].sandboxLinkName, fake.setupVethArgsForCall[i].containerLinkName, fake.setupVethArgsForCall[i].address, fake.setupVethArgsForCall[i].sandboxName, fake.setupVethArgsForCall[i].routeCommand
+	return fake.setupVethArgsForCall[i].containerNS, fake.setupVethArgsForCall[i].sandboxLinkName, fake.setupVethArgsForCall[i].containerLinkName, fake.setupVethArgsForCall[i].mtu, fake.setupVethArgsForCall[i].ipamResult, fake.setupVethArgsForCall[i].sandboxName, fake.setupVethArgsForCall[i].routeCommand
 }
 
 func (fake *CommandBuilder) SetupVethReturns(result1 executor.Command) {
@@ -242,3 +257,41 @@ func (fake *CommandBuilder) IdempotentlySetupBridgeReturns(result1 executor.Comm
 		result1 executor.Command
 	}{result1}
 }
+
+func (fake *CommandBuilder) SetupEgress(networkID string, link int, sandboxName string, sandboxNS namespace.Namespace, containerNS namespace.Namespace, containerLinkName string, ipamResult *types.Result) executor.Command {
+	fake.setupEgressMutex.Lock()
+	fake.setupEgressArgsForCall = append(fake.setupEgressArgsForCall, struct {
+		networkID         string
+		link              int
+		sandboxName       string
+		sandboxNS         namespace.Namespace
+		containerNS       namespace.Namespace
+		containerLinkName string
+		ipamResult        *types.Result
+	}{networkID, link, sandboxName, sandboxNS, containerNS, containerLinkName, ipamResult})
+	fake.setupEgressMutex.Unlock()
+	if fake.SetupEgressStub != nil {
+		return fake.SetupEgressStub(networkID, link, sandboxName, sandboxNS, containerNS, containerLinkName, ipamResult)
+	} else {
+		return fake.setupEgressReturns.result1
+	}
+}
+
+func (fake *CommandBuilder) SetupEgressCallCount() int {
+	fake.setupEgressMutex.RLock()
+	defer fake.setupEgressMutex.RUnlock()
+	return len(fake.setupEgressArgsForCall)
+}
+
+func (fake *CommandBuilder) SetupEgressArgsForCall(i int) (string, int, string, namespace.Namespace, namespace.Namespace, string, *types.Result) {
+	fake.setupEgressMutex.RLock()
+	defer fake.setupEgressMutex.RUnlock()
+	return fake.setupEgressArgsForCall[i].networkID, fake.setupEgressArgsForCall[i].link, fake.setupEgressArgsForCall[i].sandboxName, fake.setupEgressArgsForCall[i].sandboxNS, fake.setupEgressArgsForCall[i].containerNS, fake.setupEgressArgsForCall[i].containerLinkName, fake.setupEgressArgsForCall[i].ipamResult
+}
+
+func (fake *CommandBuilder) SetupEgressReturns(result1 executor.Command) {
+	fake.SetupEgressStub = nil
+	fake.setupEgressReturns = struct {
+		result1 executor.Command
+	}{result1}
+}
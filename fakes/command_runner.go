@@ -0,0 +1,56 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+)
+
+type CommandRunner struct {
+	RunStub        func(path string, stdin []byte, timeout time.Duration) ([]byte, error)
+	runMutex       sync.RWMutex
+	runArgsForCall []struct {
+		path    string
+		stdin   []byte
+		timeout time.Duration
+	}
+	runReturns struct {
+		result1 []byte
+		result2 error
+	}
+}
+
+func (fake *CommandRunner) Run(path string, stdin []byte, timeout time.Duration) ([]byte, error) {
+	fake.runMutex.Lock()
+	fake.runArgsForCall = append(fake.runArgsForCall, struct {
+		path    string
+		stdin   []byte
+		timeout time.Duration
+	}{path, stdin, timeout})
+	fake.runMutex.Unlock()
+	if fake.RunStub != nil {
+		return fake.RunStub(path, stdin, timeout)
+	} else {
+		return fake.runReturns.result1, fake.runReturns.result2
+	}
+}
+
+func (fake *CommandRunner) RunCallCount() int {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return len(fake.runArgsForCall)
+}
+
+func (fake *CommandRunner) RunArgsForCall(i int) (string, []byte, time.Duration) {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return fake.runArgsForCall[i].path, fake.runArgsForCall[i].stdin, fake.runArgsForCall[i].timeout
+}
+
+func (fake *CommandRunner) RunReturns(result1 []byte, result2 error) {
+	fake.RunStub = nil
+	fake.runReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
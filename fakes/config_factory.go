@@ -4,22 +4,31 @@ package fakes
 import (
 	"sync"
 
-	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
 )
 
 type ConfigFactory struct {
-	CreateStub        func(networkID string) (types.IPConfig, error)
+	CreateStub        func(networkID string) (ipam.Config, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
 		networkID string
 	}
 	createReturns struct {
-		result1 types.IPConfig
+		result1 ipam.Config
+		result2 error
+	}
+	DefinedStub        func(networkID string) (bool, error)
+	definedMutex       sync.RWMutex
+	definedArgsForCall []struct {
+		networkID string
+	}
+	definedReturns struct {
+		result1 bool
 		result2 error
 	}
 }
 
-func (fake *ConfigFactory) Create(networkID string) (types.IPConfig, error) {
+func (fake *ConfigFactory) Create(networkID string) (ipam.Config, error) {
 	fake.createMutex.Lock()
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
 		networkID string
@@ -44,10 +53,43 @@ func (fake *ConfigFactory) CreateArgsForCall(i int) string {
 	return fake.createArgsForCall[i].networkID
 }
 
-func (fake *ConfigFactory) CreateReturns(result1 types.IPConfig, result2 error) {
+func (fake *ConfigFactory) CreateReturns(result1 ipam.Config, result2 error) {
 	fake.CreateStub = nil
 	fake.createReturns = struct {
-		result1 types.IPConfig
+		result1 ipam.Config
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigFactory) Defined(networkID string) (bool, error) {
+	fake.definedMutex.Lock()
+	fake.definedArgsForCall = append(fake.definedArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.definedMutex.Unlock()
+	if fake.DefinedStub != nil {
+		return fake.DefinedStub(networkID)
+	} else {
+		return fake.definedReturns.result1, fake.definedReturns.result2
+	}
+}
+
+func (fake *ConfigFactory) DefinedCallCount() int {
+	fake.definedMutex.RLock()
+	defer fake.definedMutex.RUnlock()
+	return len(fake.definedArgsForCall)
+}
+
+func (fake *ConfigFactory) DefinedArgsForCall(i int) string {
+	fake.definedMutex.RLock()
+	defer fake.definedMutex.RUnlock()
+	return fake.definedArgsForCall[i].networkID
+}
+
+func (fake *ConfigFactory) DefinedReturns(result1 bool, result2 error) {
+	fake.DefinedStub = nil
+	fake.definedReturns = struct {
+		result1 bool
 		result2 error
 	}{result1, result2}
 }
@@ -0,0 +1,75 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type ContainerLocker struct {
+	LockStub        func(containerID string) error
+	lockMutex       sync.RWMutex
+	lockArgsForCall []struct {
+		containerID string
+	}
+	lockReturns struct {
+		result1 error
+	}
+	UnlockStub        func(containerID string)
+	unlockMutex       sync.RWMutex
+	unlockArgsForCall []struct {
+		containerID string
+	}
+}
+
+func (fake *ContainerLocker) Lock(containerID string) error {
+	fake.lockMutex.Lock()
+	fake.lockArgsForCall = append(fake.lockArgsForCall, struct {
+		containerID string
+	}{containerID})
+	fake.lockMutex.Unlock()
+	if fake.LockStub != nil {
+		return fake.LockStub(containerID)
+	} else {
+		return fake.lockReturns.result1
+	}
+}
+
+func (fake *ContainerLocker) LockCallCount() int {
+	fake.lockMutex.RLock()
+	defer fake.lockMutex.RUnlock()
+	return len(fake.lockArgsForCall)
+}
+
+func (fake *ContainerLocker) LockArgsForCall(i int) string {
+	fake.lockMutex.RLock()
+	defer fake.lockMutex.RUnlock()
+	return fake.lockArgsForCall[i].containerID
+}
+
+func (fake *ContainerLocker) LockReturns(result1 error) {
+	fake.LockStub = nil
+	fake.lockReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *ContainerLocker) Unlock(containerID string) {
+	fake.unlockMutex.Lock()
+	fake.unlockArgsForCall = append(fake.unlockArgsForCall, struct {
+		containerID string
+	}{containerID})
+	fake.unlockMutex.Unlock()
+	if fake.UnlockStub != nil {
+		fake.UnlockStub(containerID)
+	}
+}
+
+func (fake *ContainerLocker) UnlockCallCount() int {
+	fake.unlockMutex.RLock()
+	defer fake.unlockMutex.RUnlock()
+	return len(fake.unlockArgsForCall)
+}
+
+func (fake *ContainerLocker) UnlockArgsForCall(i int) string {
+	fake.unlockMutex.RLock()
+	defer fake.unlockMutex.RUnlock()
+	return fake.unlockArgsForCall[i].containerID
+}
@@ -58,6 +58,12 @@ type Context struct {
 	dNSServerFactoryReturns     struct {
 		result1 executor.DNSServerFactory
 	}
+	FirewallStub        func() executor.Firewall
+	firewallMutex       sync.RWMutex
+	firewallArgsForCall []struct{}
+	firewallReturns     struct {
+		result1 executor.Firewall
+	}
 }
 
 func (fake *Context) Logger() lager.Logger {
@@ -252,4 +258,28 @@ func (fake *Context) DNSServerFactoryReturns(result1 executor.DNSServerFactory)
 	}{result1}
 }
 
+func (fake *Context) Firewall() executor.Firewall {
+	fake.firewallMutex.Lock()
+	fake.firewallArgsForCall = append(fake.firewallArgsForCall, struct{}{})
+	fake.firewallMutex.Unlock()
+	if fake.FirewallStub != nil {
+		return fake.FirewallStub()
+	} else {
+		return fake.firewallReturns.result1
+	}
+}
+
+func (fake *Context) FirewallCallCount() int {
+	fake.firewallMutex.RLock()
+	defer fake.firewallMutex.RUnlock()
+	return len(fake.firewallArgsForCall)
+}
+
+func (fake *Context) FirewallReturns(result1 executor.Firewall) {
+	fake.FirewallStub = nil
+	fake.firewallReturns = struct {
+		result1 executor.Firewall
+	}{result1}
+}
+
 var _ executor.Context = new(Context)
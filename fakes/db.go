@@ -4,6 +4,8 @@ package fakes
 import (
 	"database/sql"
 	"sync"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type Db struct {
@@ -47,6 +49,13 @@ type Db struct {
 	selectReturns struct {
 		result1 error
 	}
+	BeginxStub        func() (*sqlx.Tx, error)
+	beginxMutex       sync.RWMutex
+	beginxArgsForCall []struct{}
+	beginxReturns     struct {
+		result1 *sqlx.Tx
+		result2 error
+	}
 }
 
 func (fake *Db) Exec(query string, args ...interface{}) (sql.Result, error) {
@@ -184,3 +193,28 @@ func (fake *Db) SelectReturns(result1 error) {
 		result1 error
 	}{result1}
 }
+
+func (fake *Db) Beginx() (*sqlx.Tx, error) {
+	fake.beginxMutex.Lock()
+	fake.beginxArgsForCall = append(fake.beginxArgsForCall, struct{}{})
+	fake.beginxMutex.Unlock()
+	if fake.BeginxStub != nil {
+		return fake.BeginxStub()
+	} else {
+		return fake.beginxReturns.result1, fake.beginxReturns.result2
+	}
+}
+
+func (fake *Db) BeginxCallCount() int {
+	fake.beginxMutex.RLock()
+	defer fake.beginxMutex.RUnlock()
+	return len(fake.beginxArgsForCall)
+}
+
+func (fake *Db) BeginxReturns(result1 *sqlx.Tx, result2 error) {
+	fake.BeginxStub = nil
+	fake.beginxReturns = struct {
+		result1 *sqlx.Tx
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+type Distributor struct {
+	DistributeStub        func(event models.ContainerEvent) error
+	distributeMutex       sync.RWMutex
+	distributeArgsForCall []struct {
+		event models.ContainerEvent
+	}
+	distributeReturns struct {
+		result1 error
+	}
+}
+
+func (fake *Distributor) Distribute(event models.ContainerEvent) error {
+	fake.distributeMutex.Lock()
+	fake.distributeArgsForCall = append(fake.distributeArgsForCall, struct {
+		event models.ContainerEvent
+	}{event})
+	fake.distributeMutex.Unlock()
+	if fake.DistributeStub != nil {
+		return fake.DistributeStub(event)
+	} else {
+		return fake.distributeReturns.result1
+	}
+}
+
+func (fake *Distributor) DistributeCallCount() int {
+	fake.distributeMutex.RLock()
+	defer fake.distributeMutex.RUnlock()
+	return len(fake.distributeArgsForCall)
+}
+
+func (fake *Distributor) DistributeArgsForCall(i int) models.ContainerEvent {
+	fake.distributeMutex.RLock()
+	defer fake.distributeMutex.RUnlock()
+	return fake.distributeArgsForCall[i].event
+}
+
+func (fake *Distributor) DistributeReturns(result1 error) {
+	fake.DistributeStub = nil
+	fake.distributeReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,43 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+type EgressLinkLister struct {
+	ListStub        func() ([]models.EgressLink, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct{}
+	listReturns     struct {
+		result1 []models.EgressLink
+		result2 error
+	}
+}
+
+func (fake *EgressLinkLister) List() ([]models.EgressLink, error) {
+	fake.listMutex.Lock()
+	fake.listArgsForCall = append(fake.listArgsForCall, struct{}{})
+	fake.listMutex.Unlock()
+	if fake.ListStub != nil {
+		return fake.ListStub()
+	} else {
+		return fake.listReturns.result1, fake.listReturns.result2
+	}
+}
+
+func (fake *EgressLinkLister) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *EgressLinkLister) ListReturns(result1 []models.EgressLink, result2 error) {
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []models.EgressLink
+		result2 error
+	}{result1, result2}
+}
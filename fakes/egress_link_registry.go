@@ -0,0 +1,133 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+type EgressLinkRegistry struct {
+	AcquireStub        func(sandboxName string, hostForwarding bool) (int, error)
+	acquireMutex       sync.RWMutex
+	acquireArgsForCall []struct {
+		sandboxName    string
+		hostForwarding bool
+	}
+	acquireReturns struct {
+		result1 int
+		result2 error
+	}
+	ReleaseStub        func(sandboxName string) (bool, bool, error)
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		sandboxName string
+	}
+	releaseReturns struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}
+	LinksStub        func() (map[string]int, error)
+	linksMutex       sync.RWMutex
+	linksArgsForCall []struct{}
+	linksReturns     struct {
+		result1 map[string]int
+		result2 error
+	}
+}
+
+func (fake *EgressLinkRegistry) Acquire(sandboxName string, hostForwarding bool) (int, error) {
+	fake.acquireMutex.Lock()
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		sandboxName    string
+		hostForwarding bool
+	}{sandboxName, hostForwarding})
+	fake.acquireMutex.Unlock()
+	if fake.AcquireStub != nil {
+		return fake.AcquireStub(sandboxName, hostForwarding)
+	} else {
+		return fake.acquireReturns.result1, fake.acquireReturns.result2
+	}
+}
+
+func (fake *EgressLinkRegistry) AcquireCallCount() int {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return len(fake.acquireArgsForCall)
+}
+
+func (fake *EgressLinkRegistry) AcquireArgsForCall(i int) (string, bool) {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return fake.acquireArgsForCall[i].sandboxName, fake.acquireArgsForCall[i].hostForwarding
+}
+
+func (fake *EgressLinkRegistry) AcquireReturns(result1 int, result2 error) {
+	fake.AcquireStub = nil
+	fake.acquireReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressLinkRegistry) Release(sandboxName string) (bool, bool, error) {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		sandboxName string
+	}{sandboxName})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		return fake.ReleaseStub(sandboxName)
+	} else {
+		return fake.releaseReturns.result1, fake.releaseReturns.result2, fake.releaseReturns.result3
+	}
+}
+
+func (fake *EgressLinkRegistry) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *EgressLinkRegistry) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].sandboxName
+}
+
+func (fake *EgressLinkRegistry) ReleaseReturns(result1 bool, result2 bool, result3 error) {
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *EgressLinkRegistry) Links() (map[string]int, error) {
+	fake.linksMutex.Lock()
+	fake.linksArgsForCall = append(fake.linksArgsForCall, struct{}{})
+	fake.linksMutex.Unlock()
+	if fake.LinksStub != nil {
+		return fake.LinksStub()
+	} else {
+		return fake.linksReturns.result1, fake.linksReturns.result2
+	}
+}
+
+func (fake *EgressLinkRegistry) LinksCallCount() int {
+	fake.linksMutex.RLock()
+	defer fake.linksMutex.RUnlock()
+	return len(fake.linksArgsForCall)
+}
+
+func (fake *EgressLinkRegistry) LinksReturns(result1 map[string]int, result2 error) {
+	fake.LinksStub = nil
+	fake.linksReturns = struct {
+		result1 map[string]int
+		result2 error
+	}{result1, result2}
+}
+
+var _ store.EgressLinkRegistry = new(EgressLinkRegistry)
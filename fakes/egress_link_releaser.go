@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type EgressLinkReleaser struct {
+	ReleaseStub        func(sandboxName string) (bool, bool, error)
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		sandboxName string
+	}
+	releaseReturns struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}
+}
+
+func (fake *EgressLinkReleaser) Release(sandboxName string) (bool, bool, error) {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		sandboxName string
+	}{sandboxName})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		return fake.ReleaseStub(sandboxName)
+	} else {
+		return fake.releaseReturns.result1, fake.releaseReturns.result2, fake.releaseReturns.result3
+	}
+}
+
+func (fake *EgressLinkReleaser) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *EgressLinkReleaser) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].sandboxName
+}
+
+func (fake *EgressLinkReleaser) ReleaseReturns(result1 bool, result2 bool, result3 error) {
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
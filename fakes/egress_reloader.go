@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+)
+
+type EgressReloader struct {
+	ReloadStub        func(ns namespace.Namespace) error
+	reloadMutex       sync.RWMutex
+	reloadArgsForCall []struct {
+		ns namespace.Namespace
+	}
+	reloadReturns struct {
+		result1 error
+	}
+}
+
+func (fake *EgressReloader) Reload(ns namespace.Namespace) error {
+	fake.reloadMutex.Lock()
+	fake.reloadArgsForCall = append(fake.reloadArgsForCall, struct {
+		ns namespace.Namespace
+	}{ns})
+	fake.reloadMutex.Unlock()
+	if fake.ReloadStub != nil {
+		return fake.ReloadStub(ns)
+	} else {
+		return fake.reloadReturns.result1
+	}
+}
+
+func (fake *EgressReloader) ReloadCallCount() int {
+	fake.reloadMutex.RLock()
+	defer fake.reloadMutex.RUnlock()
+	return len(fake.reloadArgsForCall)
+}
+
+func (fake *EgressReloader) ReloadArgsForCall(i int) namespace.Namespace {
+	fake.reloadMutex.RLock()
+	defer fake.reloadMutex.RUnlock()
+	return fake.reloadArgsForCall[i].ns
+}
+
+func (fake *EgressReloader) ReloadReturns(result1 error) {
+	fake.ReloadStub = nil
+	fake.reloadReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,207 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/executor"
+)
+
+type Firewall struct {
+	EnableForwardingStub        func() error
+	enableForwardingMutex       sync.RWMutex
+	enableForwardingArgsForCall []struct{}
+	enableForwardingReturns     struct {
+		result1 error
+	}
+	EnsureRuleStub        func(table string, chain string, rulespec []string) error
+	ensureRuleMutex       sync.RWMutex
+	ensureRuleArgsForCall []struct {
+		table    string
+		chain    string
+		rulespec []string
+	}
+	ensureRuleReturns struct {
+		result1 error
+	}
+	ReplaceChainStub        func(table string, chain string, parent string, rules [][]string) error
+	replaceChainMutex       sync.RWMutex
+	replaceChainArgsForCall []struct {
+		table  string
+		chain  string
+		parent string
+		rules  [][]string
+	}
+	replaceChainReturns struct {
+		result1 error
+	}
+	DisableForwardingStub        func() error
+	disableForwardingMutex       sync.RWMutex
+	disableForwardingArgsForCall []struct{}
+	disableForwardingReturns     struct {
+		result1 error
+	}
+	DeleteRuleStub        func(table string, chain string, rulespec []string) error
+	deleteRuleMutex       sync.RWMutex
+	deleteRuleArgsForCall []struct {
+		table    string
+		chain    string
+		rulespec []string
+	}
+	deleteRuleReturns struct {
+		result1 error
+	}
+}
+
+func (fake *Firewall) EnableForwarding() error {
+	fake.enableForwardingMutex.Lock()
+	fake.enableForwardingArgsForCall = append(fake.enableForwardingArgsForCall, struct{}{})
+	fake.enableForwardingMutex.Unlock()
+	if fake.EnableForwardingStub != nil {
+		return fake.EnableForwardingStub()
+	} else {
+		return fake.enableForwardingReturns.result1
+	}
+}
+
+func (fake *Firewall) EnableForwardingCallCount() int {
+	fake.enableForwardingMutex.RLock()
+	defer fake.enableForwardingMutex.RUnlock()
+	return len(fake.enableForwardingArgsForCall)
+}
+
+func (fake *Firewall) EnableForwardingReturns(result1 error) {
+	fake.EnableForwardingStub = nil
+	fake.enableForwardingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Firewall) EnsureRule(table string, chain string, rulespec []string) error {
+	fake.ensureRuleMutex.Lock()
+	fake.ensureRuleArgsForCall = append(fake.ensureRuleArgsForCall, struct {
+		table    string
+		chain    string
+		rulespec []string
+	}{table, chain, rulespec})
+	fake.ensureRuleMutex.Unlock()
+	if fake.EnsureRuleStub != nil {
+		return fake.EnsureRuleStub(table, chain, rulespec)
+	} else {
+		return fake.ensureRuleReturns.result1
+	}
+}
+
+func (fake *Firewall) EnsureRuleCallCount() int {
+	fake.ensureRuleMutex.RLock()
+	defer fake.ensureRuleMutex.RUnlock()
+	return len(fake.ensureRuleArgsForCall)
+}
+
+func (fake *Firewall) EnsureRuleArgsForCall(i int) (string, string, []string) {
+	fake.ensureRuleMutex.RLock()
+	defer fake.ensureRuleMutex.RUnlock()
+	return fake.ensureRuleArgsForCall[i].table, fake.ensureRuleArgsForCall[i].chain, fake.ensureRuleArgsForCall[i].rulespec
+}
+
+func (fake *Firewall) EnsureRuleReturns(result1 error) {
+	fake.EnsureRuleStub = nil
+	fake.ensureRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Firewall) ReplaceChain(table string, chain string, parent string, rules [][]string) error {
+	fake.replaceChainMutex.Lock()
+	fake.replaceChainArgsForCall = append(fake.replaceChainArgsForCall, struct {
+		table  string
+		chain  string
+		parent string
+		rules  [][]string
+	}{table, chain, parent, rules})
+	fake.replaceChainMutex.Unlock()
+	if fake.ReplaceChainStub != nil {
+		return fake.ReplaceChainStub(table, chain, parent, rules)
+	} else {
+		return fake.replaceChainReturns.result1
+	}
+}
+
+func (fake *Firewall) ReplaceChainCallCount() int {
+	fake.replaceChainMutex.RLock()
+	defer fake.replaceChainMutex.RUnlock()
+	return len(fake.replaceChainArgsForCall)
+}
+
+func (fake *Firewall) ReplaceChainArgsForCall(i int) (string, string, string, [][]string) {
+	fake.replaceChainMutex.RLock()
+	defer fake.replaceChainMutex.RUnlock()
+	return fake.replaceChainArgsForCall[i].table, fake.replaceChainArgsForCall[i].chain, fake.replaceChainArgsForCall[i].parent, fake.replaceChainArgsForCall[i].rules
+}
+
+func (fake *Firewall) ReplaceChainReturns(result1 error) {
+	fake.ReplaceChainStub = nil
+	fake.replaceChainReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Firewall) DisableForwarding() error {
+	fake.disableForwardingMutex.Lock()
+	fake.disableForwardingArgsForCall = append(fake.disableForwardingArgsForCall, struct{}{})
+	fake.disableForwardingMutex.Unlock()
+	if fake.DisableForwardingStub != nil {
+		return fake.DisableForwardingStub()
+	} else {
+		return fake.disableForwardingReturns.result1
+	}
+}
+
+func (fake *Firewall) DisableForwardingCallCount() int {
+	fake.disableForwardingMutex.RLock()
+	defer fake.disableForwardingMutex.RUnlock()
+	return len(fake.disableForwardingArgsForCall)
+}
+
+func (fake *Firewall) DisableForwardingReturns(result1 error) {
+	fake.DisableForwardingStub = nil
+	fake.disableForwardingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Firewall) DeleteRule(table string, chain string, rulespec []string) error {
+	fake.deleteRuleMutex.Lock()
+	fake.deleteRuleArgsForCall = append(fake.deleteRuleArgsForCall, struct {
+		table    string
+		chain    string
+		rulespec []string
+	}{table, chain, rulespec})
+	fake.deleteRuleMutex.Unlock()
+	if fake.DeleteRuleStub != nil {
+		return fake.DeleteRuleStub(table, chain, rulespec)
+	} else {
+		return fake.deleteRuleReturns.result1
+	}
+}
+
+func (fake *Firewall) DeleteRuleCallCount() int {
+	fake.deleteRuleMutex.RLock()
+	defer fake.deleteRuleMutex.RUnlock()
+	return len(fake.deleteRuleArgsForCall)
+}
+
+func (fake *Firewall) DeleteRuleArgsForCall(i int) (string, string, []string) {
+	fake.deleteRuleMutex.RLock()
+	defer fake.deleteRuleMutex.RUnlock()
+	return fake.deleteRuleArgsForCall[i].table, fake.deleteRuleArgsForCall[i].chain, fake.deleteRuleArgsForCall[i].rulespec
+}
+
+func (fake *Firewall) DeleteRuleReturns(result1 error) {
+	fake.DeleteRuleStub = nil
+	fake.deleteRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ executor.Firewall = new(Firewall)
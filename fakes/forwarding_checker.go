@@ -0,0 +1,39 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type ForwardingChecker struct {
+	ForwardingEnabledStub        func() (bool, error)
+	forwardingEnabledMutex       sync.RWMutex
+	forwardingEnabledArgsForCall []struct{}
+	forwardingEnabledReturns     struct {
+		result1 bool
+		result2 error
+	}
+}
+
+func (fake *ForwardingChecker) ForwardingEnabled() (bool, error) {
+	fake.forwardingEnabledMutex.Lock()
+	fake.forwardingEnabledArgsForCall = append(fake.forwardingEnabledArgsForCall, struct{}{})
+	fake.forwardingEnabledMutex.Unlock()
+	if fake.ForwardingEnabledStub != nil {
+		return fake.ForwardingEnabledStub()
+	} else {
+		return fake.forwardingEnabledReturns.result1, fake.forwardingEnabledReturns.result2
+	}
+}
+
+func (fake *ForwardingChecker) ForwardingEnabledCallCount() int {
+	fake.forwardingEnabledMutex.RLock()
+	defer fake.forwardingEnabledMutex.RUnlock()
+	return len(fake.forwardingEnabledArgsForCall)
+}
+
+func (fake *ForwardingChecker) ForwardingEnabledReturns(result1 bool, result2 error) {
+	fake.ForwardingEnabledStub = nil
+	fake.forwardingEnabledReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
@@ -2,10 +2,12 @@
 package fakes
 
 import (
+	"net"
 	"sync"
 
 	"github.com/appc/cni/pkg/types"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 )
 
 type IPAllocator struct {
@@ -28,6 +30,31 @@ type IPAllocator struct {
 	releaseIPReturns struct {
 		result1 error
 	}
+	AllocateStaticIPStub        func(networkID string, containerID string, ip net.IP) (*types.Result, error)
+	allocateStaticIPMutex       sync.RWMutex
+	allocateStaticIPArgsForCall []struct {
+		networkID   string
+		containerID string
+		ip          net.IP
+	}
+	allocateStaticIPReturns struct {
+		result1 *types.Result
+		result2 error
+	}
+	UsageStub        func(networkID string) (models.IPAMUsage, error)
+	usageMutex       sync.RWMutex
+	usageArgsForCall []struct {
+		networkID string
+	}
+	usageReturns struct {
+		result1 models.IPAMUsage
+		result2 error
+	}
+	ForgetStub        func(networkID string)
+	forgetMutex       sync.RWMutex
+	forgetArgsForCall []struct {
+		networkID string
+	}
 }
 
 func (fake *IPAllocator) AllocateIP(networkID string, containerID string) (*types.Result, error) {
@@ -97,4 +124,95 @@ func (fake *IPAllocator) ReleaseIPReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *IPAllocator) AllocateStaticIP(networkID string, containerID string, ip net.IP) (*types.Result, error) {
+	fake.allocateStaticIPMutex.Lock()
+	fake.allocateStaticIPArgsForCall = append(fake.allocateStaticIPArgsForCall, struct {
+		networkID   string
+		containerID string
+		ip          net.IP
+	}{networkID, containerID, ip})
+	fake.allocateStaticIPMutex.Unlock()
+	if fake.AllocateStaticIPStub != nil {
+		return fake.AllocateStaticIPStub(networkID, containerID, ip)
+	} else {
+		return fake.allocateStaticIPReturns.result1, fake.allocateStaticIPReturns.result2
+	}
+}
+
+func (fake *IPAllocator) AllocateStaticIPCallCount() int {
+	fake.allocateStaticIPMutex.RLock()
+	defer fake.allocateStaticIPMutex.RUnlock()
+	return len(fake.allocateStaticIPArgsForCall)
+}
+
+func (fake *IPAllocator) AllocateStaticIPArgsForCall(i int) (string, string, net.IP) {
+	fake.allocateStaticIPMutex.RLock()
+	defer fake.allocateStaticIPMutex.RUnlock()
+	return fake.allocateStaticIPArgsForCall[i].networkID, fake.allocateStaticIPArgsForCall[i].containerID, fake.allocateStaticIPArgsForCall[i].ip
+}
+
+func (fake *IPAllocator) AllocateStaticIPReturns(result1 *types.Result, result2 error) {
+	fake.AllocateStaticIPStub = nil
+	fake.allocateStaticIPReturns = struct {
+		result1 *types.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *IPAllocator) Usage(networkID string) (models.IPAMUsage, error) {
+	fake.usageMutex.Lock()
+	fake.usageArgsForCall = append(fake.usageArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.usageMutex.Unlock()
+	if fake.UsageStub != nil {
+		return fake.UsageStub(networkID)
+	} else {
+		return fake.usageReturns.result1, fake.usageReturns.result2
+	}
+}
+
+func (fake *IPAllocator) UsageCallCount() int {
+	fake.usageMutex.RLock()
+	defer fake.usageMutex.RUnlock()
+	return len(fake.usageArgsForCall)
+}
+
+func (fake *IPAllocator) UsageArgsForCall(i int) string {
+	fake.usageMutex.RLock()
+	defer fake.usageMutex.RUnlock()
+	return fake.usageArgsForCall[i].networkID
+}
+
+func (fake *IPAllocator) UsageReturns(result1 models.IPAMUsage, result2 error) {
+	fake.UsageStub = nil
+	fake.usageReturns = struct {
+		result1 models.IPAMUsage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *IPAllocator) Forget(networkID string) {
+	fake.forgetMutex.Lock()
+	fake.forgetArgsForCall = append(fake.forgetArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.forgetMutex.Unlock()
+	if fake.ForgetStub != nil {
+		fake.ForgetStub(networkID)
+	}
+}
+
+func (fake *IPAllocator) ForgetCallCount() int {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return len(fake.forgetArgsForCall)
+}
+
+func (fake *IPAllocator) ForgetArgsForCall(i int) string {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.forgetArgsForCall[i].networkID
+}
+
 var _ ipam.IPAllocator = new(IPAllocator)
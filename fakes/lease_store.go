@@ -0,0 +1,102 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+type LeaseStore struct {
+	RenewStub        func(networkID string, containerID string, at time.Time) error
+	renewMutex       sync.RWMutex
+	renewArgsForCall []struct {
+		networkID   string
+		containerID string
+		at          time.Time
+	}
+	renewReturns struct {
+		result1 error
+	}
+	RenewedAtStub        func(networkID string, containerID string) (time.Time, error)
+	renewedAtMutex       sync.RWMutex
+	renewedAtArgsForCall []struct {
+		networkID   string
+		containerID string
+	}
+	renewedAtReturns struct {
+		result1 time.Time
+		result2 error
+	}
+}
+
+func (fake *LeaseStore) Renew(networkID string, containerID string, at time.Time) error {
+	fake.renewMutex.Lock()
+	fake.renewArgsForCall = append(fake.renewArgsForCall, struct {
+		networkID   string
+		containerID string
+		at          time.Time
+	}{networkID, containerID, at})
+	fake.renewMutex.Unlock()
+	if fake.RenewStub != nil {
+		return fake.RenewStub(networkID, containerID, at)
+	} else {
+		return fake.renewReturns.result1
+	}
+}
+
+func (fake *LeaseStore) RenewCallCount() int {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	return len(fake.renewArgsForCall)
+}
+
+func (fake *LeaseStore) RenewArgsForCall(i int) (string, string, time.Time) {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	return fake.renewArgsForCall[i].networkID, fake.renewArgsForCall[i].containerID, fake.renewArgsForCall[i].at
+}
+
+func (fake *LeaseStore) RenewReturns(result1 error) {
+	fake.RenewStub = nil
+	fake.renewReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *LeaseStore) RenewedAt(networkID string, containerID string) (time.Time, error) {
+	fake.renewedAtMutex.Lock()
+	fake.renewedAtArgsForCall = append(fake.renewedAtArgsForCall, struct {
+		networkID   string
+		containerID string
+	}{networkID, containerID})
+	fake.renewedAtMutex.Unlock()
+	if fake.RenewedAtStub != nil {
+		return fake.RenewedAtStub(networkID, containerID)
+	} else {
+		return fake.renewedAtReturns.result1, fake.renewedAtReturns.result2
+	}
+}
+
+func (fake *LeaseStore) RenewedAtCallCount() int {
+	fake.renewedAtMutex.RLock()
+	defer fake.renewedAtMutex.RUnlock()
+	return len(fake.renewedAtArgsForCall)
+}
+
+func (fake *LeaseStore) RenewedAtArgsForCall(i int) (string, string) {
+	fake.renewedAtMutex.RLock()
+	defer fake.renewedAtMutex.RUnlock()
+	return fake.renewedAtArgsForCall[i].networkID, fake.renewedAtArgsForCall[i].containerID
+}
+
+func (fake *LeaseStore) RenewedAtReturns(result1 time.Time, result2 error) {
+	fake.RenewedAtStub = nil
+	fake.renewedAtReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+var _ store.LeaseStore = new(LeaseStore)
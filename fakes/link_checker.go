@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type LinkChecker struct {
+	SandboxLinkExistsStub        func(sandboxName string, linkName string) (bool, error)
+	sandboxLinkExistsMutex       sync.RWMutex
+	sandboxLinkExistsArgsForCall []struct {
+		sandboxName string
+		linkName    string
+	}
+	sandboxLinkExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+}
+
+func (fake *LinkChecker) SandboxLinkExists(sandboxName string, linkName string) (bool, error) {
+	fake.sandboxLinkExistsMutex.Lock()
+	fake.sandboxLinkExistsArgsForCall = append(fake.sandboxLinkExistsArgsForCall, struct {
+		sandboxName string
+		linkName    string
+	}{sandboxName, linkName})
+	fake.sandboxLinkExistsMutex.Unlock()
+	if fake.SandboxLinkExistsStub != nil {
+		return fake.SandboxLinkExistsStub(sandboxName, linkName)
+	} else {
+		return fake.sandboxLinkExistsReturns.result1, fake.sandboxLinkExistsReturns.result2
+	}
+}
+
+func (fake *LinkChecker) SandboxLinkExistsCallCount() int {
+	fake.sandboxLinkExistsMutex.RLock()
+	defer fake.sandboxLinkExistsMutex.RUnlock()
+	return len(fake.sandboxLinkExistsArgsForCall)
+}
+
+func (fake *LinkChecker) SandboxLinkExistsArgsForCall(i int) (string, string) {
+	fake.sandboxLinkExistsMutex.RLock()
+	defer fake.sandboxLinkExistsMutex.RUnlock()
+	return fake.sandboxLinkExistsArgsForCall[i].sandboxName, fake.sandboxLinkExistsArgsForCall[i].linkName
+}
+
+func (fake *LinkChecker) SandboxLinkExistsReturns(result1 bool, result2 error) {
+	fake.SandboxLinkExistsStub = nil
+	fake.sandboxLinkExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type MappingStore struct {
+	LookupStub        func(kind string, key string) (string, error)
+	lookupMutex       sync.RWMutex
+	lookupArgsForCall []struct {
+		kind string
+		key  string
+	}
+	lookupReturns struct {
+		result1 string
+		result2 error
+	}
+}
+
+func (fake *MappingStore) Lookup(kind string, key string) (string, error) {
+	fake.lookupMutex.Lock()
+	fake.lookupArgsForCall = append(fake.lookupArgsForCall, struct {
+		kind string
+		key  string
+	}{kind, key})
+	fake.lookupMutex.Unlock()
+	if fake.LookupStub != nil {
+		return fake.LookupStub(kind, key)
+	} else {
+		return fake.lookupReturns.result1, fake.lookupReturns.result2
+	}
+}
+
+func (fake *MappingStore) LookupCallCount() int {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return len(fake.lookupArgsForCall)
+}
+
+func (fake *MappingStore) LookupArgsForCall(i int) (string, string) {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return fake.lookupArgsForCall[i].kind, fake.lookupArgsForCall[i].key
+}
+
+func (fake *MappingStore) LookupReturns(result1 string, result2 error) {
+	fake.LookupStub = nil
+	fake.lookupReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,100 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
+)
+
+type NeighborManager struct {
+	AddNeighborStub        func(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error
+	addNeighborMutex       sync.RWMutex
+	addNeighborArgsForCall []struct {
+		ns              namespace.Namespace
+		vxlanDeviceName string
+		neighbor        watcher.Neighbor
+	}
+	addNeighborReturns struct {
+		result1 error
+	}
+	RemoveNeighborStub        func(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error
+	removeNeighborMutex       sync.RWMutex
+	removeNeighborArgsForCall []struct {
+		ns              namespace.Namespace
+		vxlanDeviceName string
+		neighbor        watcher.Neighbor
+	}
+	removeNeighborReturns struct {
+		result1 error
+	}
+}
+
+func (fake *NeighborManager) AddNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error {
+	fake.addNeighborMutex.Lock()
+	fake.addNeighborArgsForCall = append(fake.addNeighborArgsForCall, struct {
+		ns              namespace.Namespace
+		vxlanDeviceName string
+		neighbor        watcher.Neighbor
+	}{ns, vxlanDeviceName, neighbor})
+	fake.addNeighborMutex.Unlock()
+	if fake.AddNeighborStub != nil {
+		return fake.AddNeighborStub(ns, vxlanDeviceName, neighbor)
+	} else {
+		return fake.addNeighborReturns.result1
+	}
+}
+
+func (fake *NeighborManager) AddNeighborCallCount() int {
+	fake.addNeighborMutex.RLock()
+	defer fake.addNeighborMutex.RUnlock()
+	return len(fake.addNeighborArgsForCall)
+}
+
+func (fake *NeighborManager) AddNeighborArgsForCall(i int) (namespace.Namespace, string, watcher.Neighbor) {
+	fake.addNeighborMutex.RLock()
+	defer fake.addNeighborMutex.RUnlock()
+	return fake.addNeighborArgsForCall[i].ns, fake.addNeighborArgsForCall[i].vxlanDeviceName, fake.addNeighborArgsForCall[i].neighbor
+}
+
+func (fake *NeighborManager) AddNeighborReturns(result1 error) {
+	fake.AddNeighborStub = nil
+	fake.addNeighborReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *NeighborManager) RemoveNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error {
+	fake.removeNeighborMutex.Lock()
+	fake.removeNeighborArgsForCall = append(fake.removeNeighborArgsForCall, struct {
+		ns              namespace.Namespace
+		vxlanDeviceName string
+		neighbor        watcher.Neighbor
+	}{ns, vxlanDeviceName, neighbor})
+	fake.removeNeighborMutex.Unlock()
+	if fake.RemoveNeighborStub != nil {
+		return fake.RemoveNeighborStub(ns, vxlanDeviceName, neighbor)
+	} else {
+		return fake.removeNeighborReturns.result1
+	}
+}
+
+func (fake *NeighborManager) RemoveNeighborCallCount() int {
+	fake.removeNeighborMutex.RLock()
+	defer fake.removeNeighborMutex.RUnlock()
+	return len(fake.removeNeighborArgsForCall)
+}
+
+func (fake *NeighborManager) RemoveNeighborArgsForCall(i int) (namespace.Namespace, string, watcher.Neighbor) {
+	fake.removeNeighborMutex.RLock()
+	defer fake.removeNeighborMutex.RUnlock()
+	return fake.removeNeighborArgsForCall[i].ns, fake.removeNeighborArgsForCall[i].vxlanDeviceName, fake.removeNeighborArgsForCall[i].neighbor
+}
+
+func (fake *NeighborManager) RemoveNeighborReturns(result1 error) {
+	fake.RemoveNeighborStub = nil
+	fake.removeNeighborReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+type NetworkDefinitions struct {
+	GetStub        func(id string) (models.Network, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		id string
+	}
+	getReturns struct {
+		result1 models.Network
+		result2 error
+	}
+}
+
+func (fake *NetworkDefinitions) Get(id string) (models.Network, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		id string
+	}{id})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(id)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2
+	}
+}
+
+func (fake *NetworkDefinitions) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *NetworkDefinitions) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].id
+}
+
+func (fake *NetworkDefinitions) GetReturns(result1 models.Network, result2 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 models.Network
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,35 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type NetworkForgetter struct {
+	ForgetStub        func(networkID string)
+	forgetMutex       sync.RWMutex
+	forgetArgsForCall []struct {
+		networkID string
+	}
+}
+
+func (fake *NetworkForgetter) Forget(networkID string) {
+	fake.forgetMutex.Lock()
+	fake.forgetArgsForCall = append(fake.forgetArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.forgetMutex.Unlock()
+	if fake.ForgetStub != nil {
+		fake.ForgetStub(networkID)
+	}
+}
+
+func (fake *NetworkForgetter) ForgetCallCount() int {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return len(fake.forgetArgsForCall)
+}
+
+func (fake *NetworkForgetter) ForgetArgsForCall(i int) string {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.forgetArgsForCall[i].networkID
+}
@@ -0,0 +1,168 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+type NetworkStore struct {
+	CreateStub        func(network models.Network) error
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		network models.Network
+	}
+	createReturns struct {
+		result1 error
+	}
+	GetStub        func(id string) (models.Network, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		id string
+	}
+	getReturns struct {
+		result1 models.Network
+		result2 error
+	}
+	AllStub        func() ([]models.Network, error)
+	allMutex       sync.RWMutex
+	allArgsForCall []struct{}
+	allReturns     struct {
+		result1 []models.Network
+		result2 error
+	}
+	DeleteStub        func(id string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		id string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+}
+
+func (fake *NetworkStore) Create(network models.Network) error {
+	fake.createMutex.Lock()
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		network models.Network
+	}{network})
+	fake.createMutex.Unlock()
+	if fake.CreateStub != nil {
+		return fake.CreateStub(network)
+	} else {
+		return fake.createReturns.result1
+	}
+}
+
+func (fake *NetworkStore) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *NetworkStore) CreateArgsForCall(i int) models.Network {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return fake.createArgsForCall[i].network
+}
+
+func (fake *NetworkStore) CreateReturns(result1 error) {
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *NetworkStore) Get(id string) (models.Network, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		id string
+	}{id})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(id)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2
+	}
+}
+
+func (fake *NetworkStore) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *NetworkStore) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].id
+}
+
+func (fake *NetworkStore) GetReturns(result1 models.Network, result2 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 models.Network
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *NetworkStore) All() ([]models.Network, error) {
+	fake.allMutex.Lock()
+	fake.allArgsForCall = append(fake.allArgsForCall, struct{}{})
+	fake.allMutex.Unlock()
+	if fake.AllStub != nil {
+		return fake.AllStub()
+	} else {
+		return fake.allReturns.result1, fake.allReturns.result2
+	}
+}
+
+func (fake *NetworkStore) AllCallCount() int {
+	fake.allMutex.RLock()
+	defer fake.allMutex.RUnlock()
+	return len(fake.allArgsForCall)
+}
+
+func (fake *NetworkStore) AllReturns(result1 []models.Network, result2 error) {
+	fake.AllStub = nil
+	fake.allReturns = struct {
+		result1 []models.Network
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *NetworkStore) Delete(id string) error {
+	fake.deleteMutex.Lock()
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		id string
+	}{id})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(id)
+	} else {
+		return fake.deleteReturns.result1
+	}
+}
+
+func (fake *NetworkStore) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *NetworkStore) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return fake.deleteArgsForCall[i].id
+}
+
+func (fake *NetworkStore) DeleteReturns(result1 error) {
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ store.NetworkStore = new(NetworkStore)
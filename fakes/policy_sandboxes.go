@@ -0,0 +1,93 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+)
+
+type PolicySandboxes struct {
+	ForEachStub        func(arg1 sandbox.SandboxCallback) error
+	forEachMutex       sync.RWMutex
+	forEachArgsForCall []struct {
+		arg1 sandbox.SandboxCallback
+	}
+	forEachReturns struct {
+		result1 error
+	}
+	GetStub        func(sandboxName string) (sandbox.Sandbox, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		sandboxName string
+	}
+	getReturns struct {
+		result1 sandbox.Sandbox
+		result2 error
+	}
+}
+
+func (fake *PolicySandboxes) ForEach(arg1 sandbox.SandboxCallback) error {
+	fake.forEachMutex.Lock()
+	fake.forEachArgsForCall = append(fake.forEachArgsForCall, struct {
+		arg1 sandbox.SandboxCallback
+	}{arg1})
+	fake.forEachMutex.Unlock()
+	if fake.ForEachStub != nil {
+		return fake.ForEachStub(arg1)
+	} else {
+		return fake.forEachReturns.result1
+	}
+}
+
+func (fake *PolicySandboxes) ForEachCallCount() int {
+	fake.forEachMutex.RLock()
+	defer fake.forEachMutex.RUnlock()
+	return len(fake.forEachArgsForCall)
+}
+
+func (fake *PolicySandboxes) ForEachArgsForCall(i int) sandbox.SandboxCallback {
+	fake.forEachMutex.RLock()
+	defer fake.forEachMutex.RUnlock()
+	return fake.forEachArgsForCall[i].arg1
+}
+
+func (fake *PolicySandboxes) ForEachReturns(result1 error) {
+	fake.ForEachStub = nil
+	fake.forEachReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PolicySandboxes) Get(sandboxName string) (sandbox.Sandbox, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		sandboxName string
+	}{sandboxName})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(sandboxName)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2
+	}
+}
+
+func (fake *PolicySandboxes) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *PolicySandboxes) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].sandboxName
+}
+
+func (fake *PolicySandboxes) GetReturns(result1 sandbox.Sandbox, result2 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 sandbox.Sandbox
+		result2 error
+	}{result1, result2}
+}
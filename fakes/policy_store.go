@@ -0,0 +1,126 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+type PolicyStore struct {
+	CreateStub        func(policy models.Policy) error
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		policy models.Policy
+	}
+	createReturns struct {
+		result1 error
+	}
+	AllStub        func() ([]models.Policy, error)
+	allMutex       sync.RWMutex
+	allArgsForCall []struct{}
+	allReturns     struct {
+		result1 []models.Policy
+		result2 error
+	}
+	DeleteStub        func(id string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		id string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+}
+
+func (fake *PolicyStore) Create(policy models.Policy) error {
+	fake.createMutex.Lock()
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		policy models.Policy
+	}{policy})
+	fake.createMutex.Unlock()
+	if fake.CreateStub != nil {
+		return fake.CreateStub(policy)
+	} else {
+		return fake.createReturns.result1
+	}
+}
+
+func (fake *PolicyStore) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *PolicyStore) CreateArgsForCall(i int) models.Policy {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return fake.createArgsForCall[i].policy
+}
+
+func (fake *PolicyStore) CreateReturns(result1 error) {
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PolicyStore) All() ([]models.Policy, error) {
+	fake.allMutex.Lock()
+	fake.allArgsForCall = append(fake.allArgsForCall, struct{}{})
+	fake.allMutex.Unlock()
+	if fake.AllStub != nil {
+		return fake.AllStub()
+	} else {
+		return fake.allReturns.result1, fake.allReturns.result2
+	}
+}
+
+func (fake *PolicyStore) AllCallCount() int {
+	fake.allMutex.RLock()
+	defer fake.allMutex.RUnlock()
+	return len(fake.allArgsForCall)
+}
+
+func (fake *PolicyStore) AllReturns(result1 []models.Policy, result2 error) {
+	fake.AllStub = nil
+	fake.allReturns = struct {
+		result1 []models.Policy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PolicyStore) Delete(id string) error {
+	fake.deleteMutex.Lock()
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		id string
+	}{id})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(id)
+	} else {
+		return fake.deleteReturns.result1
+	}
+}
+
+func (fake *PolicyStore) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *PolicyStore) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return fake.deleteArgsForCall[i].id
+}
+
+func (fake *PolicyStore) DeleteReturns(result1 error) {
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ store.PolicyStore = new(PolicyStore)
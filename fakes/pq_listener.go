@@ -0,0 +1,111 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+type PQListener struct {
+	ListenStub        func(channel string) error
+	listenMutex       sync.RWMutex
+	listenArgsForCall []struct {
+		channel string
+	}
+	listenReturns struct {
+		result1 error
+	}
+	NotificationChannelStub        func() <-chan *pq.Notification
+	notificationChannelMutex       sync.RWMutex
+	notificationChannelArgsForCall []struct{}
+	notificationChannelReturns     struct {
+		result1 <-chan *pq.Notification
+	}
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct{}
+	closeReturns     struct {
+		result1 error
+	}
+}
+
+func (fake *PQListener) Listen(channel string) error {
+	fake.listenMutex.Lock()
+	fake.listenArgsForCall = append(fake.listenArgsForCall, struct {
+		channel string
+	}{channel})
+	fake.listenMutex.Unlock()
+	if fake.ListenStub != nil {
+		return fake.ListenStub(channel)
+	} else {
+		return fake.listenReturns.result1
+	}
+}
+
+func (fake *PQListener) ListenCallCount() int {
+	fake.listenMutex.RLock()
+	defer fake.listenMutex.RUnlock()
+	return len(fake.listenArgsForCall)
+}
+
+func (fake *PQListener) ListenArgsForCall(i int) string {
+	fake.listenMutex.RLock()
+	defer fake.listenMutex.RUnlock()
+	return fake.listenArgsForCall[i].channel
+}
+
+func (fake *PQListener) ListenReturns(result1 error) {
+	fake.ListenStub = nil
+	fake.listenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *PQListener) NotificationChannel() <-chan *pq.Notification {
+	fake.notificationChannelMutex.Lock()
+	fake.notificationChannelArgsForCall = append(fake.notificationChannelArgsForCall, struct{}{})
+	fake.notificationChannelMutex.Unlock()
+	if fake.NotificationChannelStub != nil {
+		return fake.NotificationChannelStub()
+	} else {
+		return fake.notificationChannelReturns.result1
+	}
+}
+
+func (fake *PQListener) NotificationChannelCallCount() int {
+	fake.notificationChannelMutex.RLock()
+	defer fake.notificationChannelMutex.RUnlock()
+	return len(fake.notificationChannelArgsForCall)
+}
+
+func (fake *PQListener) NotificationChannelReturns(result1 <-chan *pq.Notification) {
+	fake.NotificationChannelStub = nil
+	fake.notificationChannelReturns = struct {
+		result1 <-chan *pq.Notification
+	}{result1}
+}
+
+func (fake *PQListener) Close() error {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct{}{})
+	fake.closeMutex.Unlock()
+	if fake.CloseStub != nil {
+		return fake.CloseStub()
+	} else {
+		return fake.closeReturns.result1
+	}
+}
+
+func (fake *PQListener) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *PQListener) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,146 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+type ReservationIndex struct {
+	NetworksStub        func(containerID string) ([]string, error)
+	networksMutex       sync.RWMutex
+	networksArgsForCall []struct {
+		containerID string
+	}
+	networksReturns struct {
+		result1 []string
+		result2 error
+	}
+	PruneOrphansStub        func(hostIP string, renewedBefore time.Time) (int64, error)
+	pruneOrphansMutex       sync.RWMutex
+	pruneOrphansArgsForCall []struct {
+		hostIP        string
+		renewedBefore time.Time
+	}
+	pruneOrphansReturns struct {
+		result1 int64
+		result2 error
+	}
+	QuarantineOrphansStub        func(hostIP string, renewedBefore time.Time, quarantinedUntil time.Time) (int64, error)
+	quarantineOrphansMutex       sync.RWMutex
+	quarantineOrphansArgsForCall []struct {
+		hostIP           string
+		renewedBefore    time.Time
+		quarantinedUntil time.Time
+	}
+	quarantineOrphansReturns struct {
+		result1 int64
+		result2 error
+	}
+}
+
+func (fake *ReservationIndex) Networks(containerID string) ([]string, error) {
+	fake.networksMutex.Lock()
+	fake.networksArgsForCall = append(fake.networksArgsForCall, struct {
+		containerID string
+	}{containerID})
+	fake.networksMutex.Unlock()
+	if fake.NetworksStub != nil {
+		return fake.NetworksStub(containerID)
+	} else {
+		return fake.networksReturns.result1, fake.networksReturns.result2
+	}
+}
+
+func (fake *ReservationIndex) NetworksCallCount() int {
+	fake.networksMutex.RLock()
+	defer fake.networksMutex.RUnlock()
+	return len(fake.networksArgsForCall)
+}
+
+func (fake *ReservationIndex) NetworksArgsForCall(i int) string {
+	fake.networksMutex.RLock()
+	defer fake.networksMutex.RUnlock()
+	return fake.networksArgsForCall[i].containerID
+}
+
+func (fake *ReservationIndex) NetworksReturns(result1 []string, result2 error) {
+	fake.NetworksStub = nil
+	fake.networksReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ReservationIndex) PruneOrphans(hostIP string, renewedBefore time.Time) (int64, error) {
+	fake.pruneOrphansMutex.Lock()
+	fake.pruneOrphansArgsForCall = append(fake.pruneOrphansArgsForCall, struct {
+		hostIP        string
+		renewedBefore time.Time
+	}{hostIP, renewedBefore})
+	fake.pruneOrphansMutex.Unlock()
+	if fake.PruneOrphansStub != nil {
+		return fake.PruneOrphansStub(hostIP, renewedBefore)
+	} else {
+		return fake.pruneOrphansReturns.result1, fake.pruneOrphansReturns.result2
+	}
+}
+
+func (fake *ReservationIndex) PruneOrphansCallCount() int {
+	fake.pruneOrphansMutex.RLock()
+	defer fake.pruneOrphansMutex.RUnlock()
+	return len(fake.pruneOrphansArgsForCall)
+}
+
+func (fake *ReservationIndex) PruneOrphansArgsForCall(i int) (string, time.Time) {
+	fake.pruneOrphansMutex.RLock()
+	defer fake.pruneOrphansMutex.RUnlock()
+	return fake.pruneOrphansArgsForCall[i].hostIP, fake.pruneOrphansArgsForCall[i].renewedBefore
+}
+
+func (fake *ReservationIndex) PruneOrphansReturns(result1 int64, result2 error) {
+	fake.PruneOrphansStub = nil
+	fake.pruneOrphansReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ReservationIndex) QuarantineOrphans(hostIP string, renewedBefore time.Time, quarantinedUntil time.Time) (int64, error) {
+	fake.quarantineOrphansMutex.Lock()
+	fake.quarantineOrphansArgsForCall = append(fake.quarantineOrphansArgsForCall, struct {
+		hostIP           string
+		renewedBefore    time.Time
+		quarantinedUntil time.Time
+	}{hostIP, renewedBefore, quarantinedUntil})
+	fake.quarantineOrphansMutex.Unlock()
+	if fake.QuarantineOrphansStub != nil {
+		return fake.QuarantineOrphansStub(hostIP, renewedBefore, quarantinedUntil)
+	} else {
+		return fake.quarantineOrphansReturns.result1, fake.quarantineOrphansReturns.result2
+	}
+}
+
+func (fake *ReservationIndex) QuarantineOrphansCallCount() int {
+	fake.quarantineOrphansMutex.RLock()
+	defer fake.quarantineOrphansMutex.RUnlock()
+	return len(fake.quarantineOrphansArgsForCall)
+}
+
+func (fake *ReservationIndex) QuarantineOrphansArgsForCall(i int) (string, time.Time, time.Time) {
+	fake.quarantineOrphansMutex.RLock()
+	defer fake.quarantineOrphansMutex.RUnlock()
+	return fake.quarantineOrphansArgsForCall[i].hostIP, fake.quarantineOrphansArgsForCall[i].renewedBefore, fake.quarantineOrphansArgsForCall[i].quarantinedUntil
+}
+
+func (fake *ReservationIndex) QuarantineOrphansReturns(result1 int64, result2 error) {
+	fake.QuarantineOrphansStub = nil
+	fake.quarantineOrphansReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+var _ store.ReservationIndex = new(ReservationIndex)
@@ -0,0 +1,54 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"net"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+)
+
+type RouteProgrammer struct {
+	ProgramStub        func(routes []policy.Route, subnets []net.IPNet) error
+	programMutex       sync.RWMutex
+	programArgsForCall []struct {
+		routes  []policy.Route
+		subnets []net.IPNet
+	}
+	programReturns struct {
+		result1 error
+	}
+}
+
+func (fake *RouteProgrammer) Program(routes []policy.Route, subnets []net.IPNet) error {
+	fake.programMutex.Lock()
+	fake.programArgsForCall = append(fake.programArgsForCall, struct {
+		routes  []policy.Route
+		subnets []net.IPNet
+	}{routes, subnets})
+	fake.programMutex.Unlock()
+	if fake.ProgramStub != nil {
+		return fake.ProgramStub(routes, subnets)
+	} else {
+		return fake.programReturns.result1
+	}
+}
+
+func (fake *RouteProgrammer) ProgramCallCount() int {
+	fake.programMutex.RLock()
+	defer fake.programMutex.RUnlock()
+	return len(fake.programArgsForCall)
+}
+
+func (fake *RouteProgrammer) ProgramArgsForCall(i int) ([]policy.Route, []net.IPNet) {
+	fake.programMutex.RLock()
+	defer fake.programMutex.RUnlock()
+	return fake.programArgsForCall[i].routes, fake.programArgsForCall[i].subnets
+}
+
+func (fake *RouteProgrammer) ProgramReturns(result1 error) {
+	fake.ProgramStub = nil
+	fake.programReturns = struct {
+		result1 error
+	}{result1}
+}
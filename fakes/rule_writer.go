@@ -0,0 +1,91 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type RuleWriter struct {
+	WriteStub        func(chain string, rules [][]string) error
+	writeMutex       sync.RWMutex
+	writeArgsForCall []struct {
+		chain string
+		rules [][]string
+	}
+	writeReturns struct {
+		result1 error
+	}
+	WriteNATStub        func(chain string, rules [][]string) error
+	writeNATMutex       sync.RWMutex
+	writeNATArgsForCall []struct {
+		chain string
+		rules [][]string
+	}
+	writeNATReturns struct {
+		result1 error
+	}
+}
+
+func (fake *RuleWriter) Write(chain string, rules [][]string) error {
+	fake.writeMutex.Lock()
+	fake.writeArgsForCall = append(fake.writeArgsForCall, struct {
+		chain string
+		rules [][]string
+	}{chain, rules})
+	fake.writeMutex.Unlock()
+	if fake.WriteStub != nil {
+		return fake.WriteStub(chain, rules)
+	} else {
+		return fake.writeReturns.result1
+	}
+}
+
+func (fake *RuleWriter) WriteCallCount() int {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return len(fake.writeArgsForCall)
+}
+
+func (fake *RuleWriter) WriteArgsForCall(i int) (string, [][]string) {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return fake.writeArgsForCall[i].chain, fake.writeArgsForCall[i].rules
+}
+
+func (fake *RuleWriter) WriteReturns(result1 error) {
+	fake.WriteStub = nil
+	fake.writeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *RuleWriter) WriteNAT(chain string, rules [][]string) error {
+	fake.writeNATMutex.Lock()
+	fake.writeNATArgsForCall = append(fake.writeNATArgsForCall, struct {
+		chain string
+		rules [][]string
+	}{chain, rules})
+	fake.writeNATMutex.Unlock()
+	if fake.WriteNATStub != nil {
+		return fake.WriteNATStub(chain, rules)
+	} else {
+		return fake.writeNATReturns.result1
+	}
+}
+
+func (fake *RuleWriter) WriteNATCallCount() int {
+	fake.writeNATMutex.RLock()
+	defer fake.writeNATMutex.RUnlock()
+	return len(fake.writeNATArgsForCall)
+}
+
+func (fake *RuleWriter) WriteNATArgsForCall(i int) (string, [][]string) {
+	fake.writeNATMutex.RLock()
+	defer fake.writeNATMutex.RUnlock()
+	return fake.writeNATArgsForCall[i].chain, fake.writeNATArgsForCall[i].rules
+}
+
+func (fake *RuleWriter) WriteNATReturns(result1 error) {
+	fake.WriteNATStub = nil
+	fake.writeNATReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+)
+
+type SandboxIterator struct {
+	ForEachStub        func(sandbox.SandboxCallback) error
+	forEachMutex       sync.RWMutex
+	forEachArgsForCall []struct {
+		arg1 sandbox.SandboxCallback
+	}
+	forEachReturns struct {
+		result1 error
+	}
+}
+
+func (fake *SandboxIterator) ForEach(arg1 sandbox.SandboxCallback) error {
+	fake.forEachMutex.Lock()
+	fake.forEachArgsForCall = append(fake.forEachArgsForCall, struct {
+		arg1 sandbox.SandboxCallback
+	}{arg1})
+	fake.forEachMutex.Unlock()
+	if fake.ForEachStub != nil {
+		return fake.ForEachStub(arg1)
+	} else {
+		return fake.forEachReturns.result1
+	}
+}
+
+func (fake *SandboxIterator) ForEachCallCount() int {
+	fake.forEachMutex.RLock()
+	defer fake.forEachMutex.RUnlock()
+	return len(fake.forEachArgsForCall)
+}
+
+func (fake *SandboxIterator) ForEachArgsForCall(i int) sandbox.SandboxCallback {
+	fake.forEachMutex.RLock()
+	defer fake.forEachMutex.RUnlock()
+	return fake.forEachArgsForCall[i].arg1
+}
+
+func (fake *SandboxIterator) ForEachReturns(result1 error) {
+	fake.ForEachStub = nil
+	fake.forEachReturns = struct {
+		result1 error
+	}{result1}
+}
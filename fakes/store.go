@@ -26,6 +26,16 @@ type Store struct {
 		result1 models.Container
 		result2 error
 	}
+	GetBySandboxAndIPStub        func(sandboxName string, ip string) (models.Container, error)
+	getBySandboxAndIPMutex       sync.RWMutex
+	getBySandboxAndIPArgsForCall []struct {
+		sandboxName string
+		ip          string
+	}
+	getBySandboxAndIPReturns struct {
+		result1 models.Container
+		result2 error
+	}
 	AllStub        func() ([]models.Container, error)
 	allMutex       sync.RWMutex
 	allArgsForCall []struct{}
@@ -41,6 +51,25 @@ type Store struct {
 	deleteReturns struct {
 		result1 error
 	}
+	AllBySandboxStub        func(sandboxName string) ([]models.Container, error)
+	allBySandboxMutex       sync.RWMutex
+	allBySandboxArgsForCall []struct {
+		sandboxName string
+	}
+	allBySandboxReturns struct {
+		result1 []models.Container
+		result2 error
+	}
+	GetBySandboxAndMACStub        func(sandboxName string, mac string) (models.Container, error)
+	getBySandboxAndMACMutex       sync.RWMutex
+	getBySandboxAndMACArgsForCall []struct {
+		sandboxName string
+		mac         string
+	}
+	getBySandboxAndMACReturns struct {
+		result1 models.Container
+		result2 error
+	}
 }
 
 func (fake *Store) Create(container models.Container) error {
@@ -108,6 +137,40 @@ func (fake *Store) GetReturns(result1 models.Container, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *Store) GetBySandboxAndIP(sandboxName string, ip string) (models.Container, error) {
+	fake.getBySandboxAndIPMutex.Lock()
+	fake.getBySandboxAndIPArgsForCall = append(fake.getBySandboxAndIPArgsForCall, struct {
+		sandboxName string
+		ip          string
+	}{sandboxName, ip})
+	fake.getBySandboxAndIPMutex.Unlock()
+	if fake.GetBySandboxAndIPStub != nil {
+		return fake.GetBySandboxAndIPStub(sandboxName, ip)
+	} else {
+		return fake.getBySandboxAndIPReturns.result1, fake.getBySandboxAndIPReturns.result2
+	}
+}
+
+func (fake *Store) GetBySandboxAndIPCallCount() int {
+	fake.getBySandboxAndIPMutex.RLock()
+	defer fake.getBySandboxAndIPMutex.RUnlock()
+	return len(fake.getBySandboxAndIPArgsForCall)
+}
+
+func (fake *Store) GetBySandboxAndIPArgsForCall(i int) (string, string) {
+	fake.getBySandboxAndIPMutex.RLock()
+	defer fake.getBySandboxAndIPMutex.RUnlock()
+	return fake.getBySandboxAndIPArgsForCall[i].sandboxName, fake.getBySandboxAndIPArgsForCall[i].ip
+}
+
+func (fake *Store) GetBySandboxAndIPReturns(result1 models.Container, result2 error) {
+	fake.GetBySandboxAndIPStub = nil
+	fake.getBySandboxAndIPReturns = struct {
+		result1 models.Container
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Store) All() ([]models.Container, error) {
 	fake.allMutex.Lock()
 	fake.allArgsForCall = append(fake.allArgsForCall, struct{}{})
@@ -165,4 +228,71 @@ func (fake *Store) DeleteReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *Store) AllBySandbox(sandboxName string) ([]models.Container, error) {
+	fake.allBySandboxMutex.Lock()
+	fake.allBySandboxArgsForCall = append(fake.allBySandboxArgsForCall, struct {
+		sandboxName string
+	}{sandboxName})
+	fake.allBySandboxMutex.Unlock()
+	if fake.AllBySandboxStub != nil {
+		return fake.AllBySandboxStub(sandboxName)
+	} else {
+		return fake.allBySandboxReturns.result1, fake.allBySandboxReturns.result2
+	}
+}
+
+func (fake *Store) AllBySandboxCallCount() int {
+	fake.allBySandboxMutex.RLock()
+	defer fake.allBySandboxMutex.RUnlock()
+	return len(fake.allBySandboxArgsForCall)
+}
+
+func (fake *Store) AllBySandboxArgsForCall(i int) string {
+	fake.allBySandboxMutex.RLock()
+	defer fake.allBySandboxMutex.RUnlock()
+	return fake.allBySandboxArgsForCall[i].sandboxName
+}
+
+func (fake *Store) AllBySandboxReturns(result1 []models.Container, result2 error) {
+	fake.AllBySandboxStub = nil
+	fake.allBySandboxReturns = struct {
+		result1 []models.Container
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Store) GetBySandboxAndMAC(sandboxName string, mac string) (models.Container, error) {
+	fake.getBySandboxAndMACMutex.Lock()
+	fake.getBySandboxAndMACArgsForCall = append(fake.getBySandboxAndMACArgsForCall, struct {
+		sandboxName string
+		mac         string
+	}{sandboxName, mac})
+	fake.getBySandboxAndMACMutex.Unlock()
+	if fake.GetBySandboxAndMACStub != nil {
+		return fake.GetBySandboxAndMACStub(sandboxName, mac)
+	} else {
+		return fake.getBySandboxAndMACReturns.result1, fake.getBySandboxAndMACReturns.result2
+	}
+}
+
+func (fake *Store) GetBySandboxAndMACCallCount() int {
+	fake.getBySandboxAndMACMutex.RLock()
+	defer fake.getBySandboxAndMACMutex.RUnlock()
+	return len(fake.getBySandboxAndMACArgsForCall)
+}
+
+func (fake *Store) GetBySandboxAndMACArgsForCall(i int) (string, string) {
+	fake.getBySandboxAndMACMutex.RLock()
+	defer fake.getBySandboxAndMACMutex.RUnlock()
+	return fake.getBySandboxAndMACArgsForCall[i].sandboxName, fake.getBySandboxAndMACArgsForCall[i].mac
+}
+
+func (fake *Store) GetBySandboxAndMACReturns(result1 models.Container, result2 error) {
+	fake.GetBySandboxAndMACStub = nil
+	fake.getBySandboxAndMACReturns = struct {
+		result1 models.Container
+		result2 error
+	}{result1, result2}
+}
+
 var _ store.Store = new(Store)
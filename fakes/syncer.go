@@ -0,0 +1,37 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type Syncer struct {
+	SyncStub        func() error
+	syncMutex       sync.RWMutex
+	syncArgsForCall []struct{}
+	syncReturns     struct {
+		result1 error
+	}
+}
+
+func (fake *Syncer) Sync() error {
+	fake.syncMutex.Lock()
+	fake.syncArgsForCall = append(fake.syncArgsForCall, struct{}{})
+	fake.syncMutex.Unlock()
+	if fake.SyncStub != nil {
+		return fake.SyncStub()
+	} else {
+		return fake.syncReturns.result1
+	}
+}
+
+func (fake *Syncer) SyncCallCount() int {
+	fake.syncMutex.RLock()
+	defer fake.syncMutex.RUnlock()
+	return len(fake.syncArgsForCall)
+}
+
+func (fake *Syncer) SyncReturns(result1 error) {
+	fake.SyncStub = nil
+	fake.syncReturns = struct {
+		result1 error
+	}{result1}
+}
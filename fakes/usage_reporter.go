@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+type UsageReporter struct {
+	UsageStub        func(networkID string) (models.IPAMUsage, error)
+	usageMutex       sync.RWMutex
+	usageArgsForCall []struct {
+		networkID string
+	}
+	usageReturns struct {
+		result1 models.IPAMUsage
+		result2 error
+	}
+}
+
+func (fake *UsageReporter) Usage(networkID string) (models.IPAMUsage, error) {
+	fake.usageMutex.Lock()
+	fake.usageArgsForCall = append(fake.usageArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.usageMutex.Unlock()
+	if fake.UsageStub != nil {
+		return fake.UsageStub(networkID)
+	} else {
+		return fake.usageReturns.result1, fake.usageReturns.result2
+	}
+}
+
+func (fake *UsageReporter) UsageCallCount() int {
+	fake.usageMutex.RLock()
+	defer fake.usageMutex.RUnlock()
+	return len(fake.usageArgsForCall)
+}
+
+func (fake *UsageReporter) UsageArgsForCall(i int) string {
+	fake.usageMutex.RLock()
+	defer fake.usageMutex.RUnlock()
+	return fake.usageArgsForCall[i].networkID
+}
+
+func (fake *UsageReporter) UsageReturns(result1 models.IPAMUsage, result2 error) {
+	fake.UsageStub = nil
+	fake.usageReturns = struct {
+		result1 models.IPAMUsage
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,49 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type VNIMapper struct {
+	GetVNIStub        func(networkID string) (int, error)
+	getVNIMutex       sync.RWMutex
+	getVNIArgsForCall []struct {
+		networkID string
+	}
+	getVNIReturns struct {
+		result1 int
+		result2 error
+	}
+}
+
+func (fake *VNIMapper) GetVNI(networkID string) (int, error) {
+	fake.getVNIMutex.Lock()
+	fake.getVNIArgsForCall = append(fake.getVNIArgsForCall, struct {
+		networkID string
+	}{networkID})
+	fake.getVNIMutex.Unlock()
+	if fake.GetVNIStub != nil {
+		return fake.GetVNIStub(networkID)
+	} else {
+		return fake.getVNIReturns.result1, fake.getVNIReturns.result2
+	}
+}
+
+func (fake *VNIMapper) GetVNICallCount() int {
+	fake.getVNIMutex.RLock()
+	defer fake.getVNIMutex.RUnlock()
+	return len(fake.getVNIArgsForCall)
+}
+
+func (fake *VNIMapper) GetVNIArgsForCall(i int) string {
+	fake.getVNIMutex.RLock()
+	defer fake.getVNIMutex.RUnlock()
+	return fake.getVNIArgsForCall[i].networkID
+}
+
+func (fake *VNIMapper) GetVNIReturns(result1 int, result2 error) {
+	fake.GetVNIStub = nil
+	fake.getVNIReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
@@ -0,0 +1,49 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type VNIRegisterer struct {
+	RegisterStub        func(networkID string, vni int) error
+	registerMutex       sync.RWMutex
+	registerArgsForCall []struct {
+		networkID string
+		vni       int
+	}
+	registerReturns struct {
+		result1 error
+	}
+}
+
+func (fake *VNIRegisterer) Register(networkID string, vni int) error {
+	fake.registerMutex.Lock()
+	fake.registerArgsForCall = append(fake.registerArgsForCall, struct {
+		networkID string
+		vni       int
+	}{networkID, vni})
+	fake.registerMutex.Unlock()
+	if fake.RegisterStub != nil {
+		return fake.RegisterStub(networkID, vni)
+	} else {
+		return fake.registerReturns.result1
+	}
+}
+
+func (fake *VNIRegisterer) RegisterCallCount() int {
+	fake.registerMutex.RLock()
+	defer fake.registerMutex.RUnlock()
+	return len(fake.registerArgsForCall)
+}
+
+func (fake *VNIRegisterer) RegisterArgsForCall(i int) (string, int) {
+	fake.registerMutex.RLock()
+	defer fake.registerMutex.RUnlock()
+	return fake.registerArgsForCall[i].networkID, fake.registerArgsForCall[i].vni
+}
+
+func (fake *VNIRegisterer) RegisterReturns(result1 error) {
+	fake.RegisterStub = nil
+	fake.registerReturns = struct {
+		result1 error
+	}{result1}
+}
@@ -0,0 +1,51 @@
+// This file was generated by counterfeiter
+package fakes
+
+import "sync"
+
+type VNIRegistry struct {
+	AssignStub        func(networkID string, hint int) (int, error)
+	assignMutex       sync.RWMutex
+	assignArgsForCall []struct {
+		networkID string
+		hint      int
+	}
+	assignReturns struct {
+		result1 int
+		result2 error
+	}
+}
+
+func (fake *VNIRegistry) Assign(networkID string, hint int) (int, error) {
+	fake.assignMutex.Lock()
+	fake.assignArgsForCall = append(fake.assignArgsForCall, struct {
+		networkID string
+		hint      int
+	}{networkID, hint})
+	fake.assignMutex.Unlock()
+	if fake.AssignStub != nil {
+		return fake.AssignStub(networkID, hint)
+	} else {
+		return fake.assignReturns.result1, fake.assignReturns.result2
+	}
+}
+
+func (fake *VNIRegistry) AssignCallCount() int {
+	fake.assignMutex.RLock()
+	defer fake.assignMutex.RUnlock()
+	return len(fake.assignArgsForCall)
+}
+
+func (fake *VNIRegistry) AssignArgsForCall(i int) (string, int) {
+	fake.assignMutex.RLock()
+	defer fake.assignMutex.RUnlock()
+	return fake.assignArgsForCall[i].networkID, fake.assignArgsForCall[i].hint
+}
+
+func (fake *VNIRegistry) AssignReturns(result1 int, result2 error) {
+	fake.AssignStub = nil
+	fake.assignReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
@@ -9,6 +9,7 @@ import (
 	"lib/marshal"
 
 	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 	"github.com/pivotal-golang/lager"
@@ -70,10 +71,14 @@ func (h *CNIAdd) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		logger.Error("controller-add", err)
 		switch err {
-		case ipam.AlreadyOnNetworkError:
+		case ipam.AlreadyOnNetworkError, ipam.AddressNotInSubnetError, cni.InvalidArgsError, cni.UndefinedNetworkError:
 			resp.WriteHeader(http.StatusBadRequest)
-		case ipam.NoMoreAddressesError:
+		case ipam.NoMoreAddressesError, ipam.AddressTakenError:
 			resp.WriteHeader(http.StatusConflict)
+		case cni.ContainerConflictError:
+			resp.WriteHeader(http.StatusUnprocessableEntity)
+		case cni.LockTimeoutError:
+			resp.WriteHeader(http.StatusServiceUnavailable)
 		default:
 			resp.WriteHeader(http.StatusInternalServerError)
 		}
@@ -19,6 +19,7 @@ import (
 	"github.com/onsi/gomega/gbytes"
 
 	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
@@ -198,6 +199,94 @@ var _ = Describe("CNIAdd", func() {
 		})
 	})
 
+	Context("when the requested static IP is outside the subnet", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, ipam.AddressNotInSubnetError)
+		})
+
+		It("should log and return a 400 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "requested address is not in the network's subnet" }`))
+			Expect(logger).To(gbytes.Say(`cni-add.controller-add.*requested address is not in the network's subnet`))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the requested static IP is already taken", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, ipam.AddressTakenError)
+		})
+
+		It("should log and return a 409 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "requested address is already in use" }`))
+			Expect(logger).To(gbytes.Say(`cni-add.controller-add.*requested address is already in use`))
+			Expect(resp.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Context("when the CNI args are malformed", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, cni.InvalidArgsError)
+		})
+
+		It("should log and return a 400 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "invalid CNI args" }`))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the network is not defined in strict mode", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, cni.UndefinedNetworkError)
+		})
+
+		It("should log and return a 400 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "network is not defined" }`))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the controller returns a cni.ContainerConflictError", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, cni.ContainerConflictError)
+		})
+
+		It("should log and return a 422 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "container already attached with a different namespace or interface" }`))
+			Expect(logger).To(gbytes.Say(`cni-add.controller-add.*container already attached`))
+			Expect(resp.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+
+	Context("when the controller times out waiting for the container lock", func() {
+		BeforeEach(func() {
+			controller.AddReturns(nil, cni.LockTimeoutError)
+		})
+
+		It("should log and return a 503 status with JSON body encoding the error message", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "timed out waiting for another operation on this container" }`))
+			Expect(logger).To(gbytes.Say(`cni-add.controller-add.*timed out waiting`))
+			Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
 	Context("when the controller returns any other error", func() {
 		BeforeEach(func() {
 			controller.AddReturns(nil, errors.New("tomato"))
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 
+	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 	"github.com/pivotal-golang/lager"
 	"lib/marshal"
@@ -64,7 +65,12 @@ func (h *CNIDel) ServeHTTP(resp http.ResponseWriter, request *http.Request) {
 	err = h.Controller.Del(payload)
 	if err != nil {
 		logger.Error("controller-del", err)
-		resp.WriteHeader(http.StatusInternalServerError)
+		switch err {
+		case cni.LockTimeoutError:
+			resp.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			resp.WriteHeader(http.StatusInternalServerError)
+		}
 
 		err = marshalError(resp, h.Marshaler, err)
 		if err != nil {
@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 
+	"github.com/cloudfoundry-incubator/ducati-daemon/cni"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
@@ -133,6 +134,21 @@ var _ = Describe("CNIDel", func() {
 		Expect(resp.Code).To(Equal(http.StatusNoContent))
 	})
 
+	Context("when the controller times out waiting for the container lock", func() {
+		BeforeEach(func() {
+			controller.DelReturns(cni.LockTimeoutError)
+		})
+
+		It("should respond with code 503 and log the error", func() {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(logger).To(gbytes.Say("cni-del.controller-del.*timed out waiting"))
+			Expect(resp.Body.String()).To(MatchJSON(`{ "error": "timed out waiting for another operation on this container" }`))
+			Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
 	Context("when the controller returns an error", func() {
 		BeforeEach(func() {
 			controller.DelReturns(errors.New("tomato"))
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"lib/marshal"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/links"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+)
+
+// minimumMTU is the smallest MTU an IPv4 link may have.
+const minimumMTU = 68
+
+//go:generate counterfeiter -o ../fakes/vni_mapper.go --fake-name VNIMapper . vniMapper
+type vniMapper interface {
+	GetVNI(networkID string) (int, error)
+}
+
+//go:generate counterfeiter -o ../fakes/network_forgetter.go --fake-name NetworkForgetter . networkForgetter
+type networkForgetter interface {
+	Forget(networkID string)
+}
+
+type CreateNetwork struct {
+	Unmarshaler   marshal.Unmarshaler
+	Marshaler     marshal.Marshaler
+	Logger        lager.Logger
+	Networks      store.NetworkStore
+	NetworkMapper vniMapper
+	IPAllocator   networkForgetter
+}
+
+func (h *CreateNetwork) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("create-network")
+
+	bodyBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Error("body-read-failed", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var network models.Network
+	err = h.Unmarshaler.Unmarshal(bodyBytes, &network)
+	if err != nil {
+		logger.Error("unmarshal-failed", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	logger = logger.WithData(lager.Data{"network": network})
+
+	err = validateNetwork(network)
+	if err != nil {
+		logger.Error("bad-request", err)
+		h.writeError(logger, resp, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.Networks.Create(network)
+	if err != nil {
+		logger.Error("create-failed", err)
+		switch err {
+		case store.RecordExistsError, store.VNIConflictError:
+			h.writeError(logger, resp, http.StatusConflict, err)
+		default:
+			resp.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.IPAllocator.Forget(network.ID)
+
+	if network.VNI == 0 {
+		network.VNI, err = h.NetworkMapper.GetVNI(network.ID)
+		if err != nil {
+			logger.Error("get-vni-failed", err)
+			resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	payload, err := h.Marshaler.Marshal(network)
+	if err != nil {
+		logger.Error("marshal-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	resp.Write(payload)
+}
+
+func (h *CreateNetwork) writeError(logger lager.Logger, resp http.ResponseWriter, status int, err error) {
+	resp.WriteHeader(status)
+
+	err = marshalError(resp, h.Marshaler, err)
+	if err != nil {
+		logger.Error("marshal-error", err)
+	}
+}
+
+func validateNetwork(network models.Network) error {
+	if network.ID == "" {
+		return errors.New(`missing required field "id"`)
+	}
+
+	if network.VNI < 0 || network.VNI >= store.MaxVNI {
+		return fmt.Errorf(`"vni" must be less than %d`, store.MaxVNI)
+	}
+
+	if network.Subnet != "" {
+		_, subnet, err := net.ParseCIDR(network.Subnet)
+		if err != nil {
+			return fmt.Errorf(`"subnet": %s`, err)
+		}
+		if subnet.IP.To4() == nil {
+			return errors.New(`"subnet": not an IPv4 subnet`)
+		}
+	}
+
+	if network.MTU != 0 && (network.MTU < minimumMTU || network.MTU > links.VxlanVethMTU) {
+		return fmt.Errorf(`"mtu" must be between %d and %d`, minimumMTU, links.VxlanVethMTU)
+	}
+
+	for _, nameserver := range network.DNS.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			return fmt.Errorf(`"dns": nameserver %s is not an IP address`, nameserver)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	lfakes "lib/fakes"
+	"lib/testsupport"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("POST /networks", func() {
+	var (
+		handler       http.Handler
+		request       *http.Request
+		resp          *httptest.ResponseRecorder
+		marshaler     *lfakes.Marshaler
+		unmarshaler   *lfakes.Unmarshaler
+		logger        *lagertest.TestLogger
+		networks      *fakes.NetworkStore
+		networkMapper *fakes.VNIMapper
+		ipAllocator   *fakes.NetworkForgetter
+		network       models.Network
+	)
+
+	var setPayload = func() {
+		payloadBytes, err := json.Marshal(network)
+		Expect(err).NotTo(HaveOccurred())
+		request.Body = ioutil.NopCloser(bytes.NewBuffer(payloadBytes))
+	}
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		unmarshaler = &lfakes.Unmarshaler{}
+		unmarshaler.UnmarshalStub = json.Unmarshal
+		networks = &fakes.NetworkStore{}
+		networkMapper = &fakes.VNIMapper{}
+		networkMapper.GetVNIReturns(99, nil)
+		ipAllocator = &fakes.NetworkForgetter{}
+		logger = lagertest.NewTestLogger("test")
+
+		createHandler := &handlers.CreateNetwork{
+			Unmarshaler:   unmarshaler,
+			Marshaler:     marshaler,
+			Logger:        logger,
+			Networks:      networks,
+			NetworkMapper: networkMapper,
+			IPAllocator:   ipAllocator,
+		}
+		handler, request = rataWrap(createHandler, "POST", "/networks", rata.Params{})
+		resp = httptest.NewRecorder()
+
+		network = models.Network{
+			ID:     "some-network-id",
+			Name:   "some-name",
+			VNI:    42,
+			Subnet: "10.10.0.0/16",
+			MTU:    1400,
+			DNS: models.NetworkDNS{DNS: types.DNS{
+				Nameservers: []string{"10.10.0.53"},
+			}},
+		}
+		setPayload()
+	})
+
+	It("creates the network and responds with its definition", func() {
+		handler.ServeHTTP(resp, request)
+
+		Expect(resp.Code).To(Equal(http.StatusCreated))
+		Expect(networks.CreateCallCount()).To(Equal(1))
+		Expect(networks.CreateArgsForCall(0)).To(Equal(network))
+
+		var received models.Network
+		Expect(json.Unmarshal(resp.Body.Bytes(), &received)).To(Succeed())
+		Expect(received).To(Equal(network))
+
+		Expect(networkMapper.GetVNICallCount()).To(Equal(0))
+	})
+
+	It("makes the allocator forget what it cached for the network", func() {
+		handler.ServeHTTP(resp, request)
+
+		Expect(ipAllocator.ForgetCallCount()).To(Equal(1))
+		Expect(ipAllocator.ForgetArgsForCall(0)).To(Equal("some-network-id"))
+	})
+
+	Context("when the definition has no VNI", func() {
+		BeforeEach(func() {
+			network.VNI = 0
+			setPayload()
+		})
+
+		It("assigns one and responds with it", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusCreated))
+			Expect(networkMapper.GetVNIArgsForCall(0)).To(Equal("some-network-id"))
+
+			var received models.Network
+			Expect(json.Unmarshal(resp.Body.Bytes(), &received)).To(Succeed())
+			Expect(received.VNI).To(Equal(99))
+		})
+
+		Context("when assigning the VNI fails", func() {
+			BeforeEach(func() {
+				networkMapper.GetVNIReturns(0, errors.New("fig"))
+			})
+
+			It("responds with a 500", func() {
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(logger).To(gbytes.Say("create-network.*get-vni-failed.*fig"))
+			})
+		})
+	})
+
+	DescribeTable("invalid definitions",
+		func(expectedError string, corrupter func()) {
+			corrupter()
+			setPayload()
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": ` + expectedError + `}`))
+			Expect(networks.CreateCallCount()).To(Equal(0))
+		},
+		Entry("missing id", `"missing required field \"id\""`, func() { network.ID = "" }),
+		Entry("negative vni", `"\"vni\" must be less than 16777216"`, func() { network.VNI = -1 }),
+		Entry("vni too large", `"\"vni\" must be less than 16777216"`, func() { network.VNI = 1 << 24 }),
+		Entry("unparsable subnet", `"\"subnet\": invalid CIDR address: foo"`, func() { network.Subnet = "foo" }),
+		Entry("IPv6 subnet", `"\"subnet\": not an IPv4 subnet"`, func() { network.Subnet = "fd00::/64" }),
+		Entry("mtu too small", `"\"mtu\" must be between 68 and 1450"`, func() { network.MTU = 10 }),
+		Entry("mtu too large", `"\"mtu\" must be between 68 and 1450"`, func() { network.MTU = 9000 }),
+		Entry("bad nameserver", `"\"dns\": nameserver bar is not an IP address"`, func() { network.DNS.Nameservers = []string{"bar"} }),
+	)
+
+	Context("when the request body cannot be read", func() {
+		BeforeEach(func() {
+			request.Body = ioutil.NopCloser(&testsupport.BadReader{})
+		})
+
+		It("responds with a 400", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(logger).To(gbytes.Say("create-network.*body-read-failed"))
+		})
+	})
+
+	Context("when the request body is not a network", func() {
+		BeforeEach(func() {
+			request.Body = ioutil.NopCloser(bytes.NewBufferString("{{{"))
+		})
+
+		It("responds with a 400", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(logger).To(gbytes.Say("create-network.*unmarshal-failed"))
+		})
+	})
+
+	DescribeTable("conflicts",
+		func(storeErr error) {
+			networks.CreateReturns(storeErr)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusConflict))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "` + storeErr.Error() + `"}`))
+		},
+		Entry("the network exists", store.RecordExistsError),
+		Entry("the vni is taken", store.VNIConflictError),
+	)
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			networks.CreateReturns(errors.New("kiwi"))
+		})
+
+		It("responds with a 500", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("create-network.*create-failed.*kiwi"))
+		})
+	})
+})
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"lib/marshal"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+)
+
+// maximumPort is the largest tcp or udp port.
+const maximumPort = 65535
+
+type policySyncer interface {
+	Sync() error
+}
+
+// CreatePolicy records a policy and reprograms the local sandboxes. Other
+// daemons are notified of the new policy through the datastore.
+type CreatePolicy struct {
+	Unmarshaler marshal.Unmarshaler
+	Marshaler   marshal.Marshaler
+	Logger      lager.Logger
+	Policies    store.PolicyStore
+	Enforcer    policySyncer
+}
+
+func (h *CreatePolicy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("create-policy")
+
+	bodyBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		logger.Error("body-read-failed", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var policy models.Policy
+	err = h.Unmarshaler.Unmarshal(bodyBytes, &policy)
+	if err != nil {
+		logger.Error("unmarshal-failed", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	logger = logger.WithData(lager.Data{"policy": policy})
+
+	err = validatePolicy(policy)
+	if err != nil {
+		logger.Error("bad-request", err)
+		h.writeError(logger, resp, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.Policies.Create(policy)
+	if err != nil {
+		logger.Error("create-failed", err)
+		if err == store.RecordExistsError {
+			h.writeError(logger, resp, http.StatusConflict, err)
+			return
+		}
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Enforcer.Sync()
+	if err != nil {
+		logger.Error("sync-failed", err)
+	}
+
+	payload, err := h.Marshaler.Marshal(policy)
+	if err != nil {
+		logger.Error("marshal-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	resp.Write(payload)
+}
+
+func (h *CreatePolicy) writeError(logger lager.Logger, resp http.ResponseWriter, status int, err error) {
+	resp.WriteHeader(status)
+
+	err = marshalError(resp, h.Marshaler, err)
+	if err != nil {
+		logger.Error("marshal-error", err)
+	}
+}
+
+func validatePolicy(policy models.Policy) error {
+	if policy.ID == "" {
+		return errors.New(`missing required field "id"`)
+	}
+
+	if policy.Source == "" {
+		return errors.New(`missing required field "source_app"`)
+	}
+
+	if policy.Destination == "" {
+		return errors.New(`missing required field "destination_app"`)
+	}
+
+	switch policy.Protocol {
+	case "", "icmp":
+		if policy.Port != 0 {
+			return errors.New(`"port" needs the "tcp" or "udp" protocol`)
+		}
+	case "tcp", "udp":
+		if policy.Port < 0 || policy.Port > maximumPort {
+			return errors.New(`"port" must be between 0 and 65535`)
+		}
+	default:
+		return errors.New(`"protocol" must be "tcp", "udp" or "icmp"`)
+	}
+
+	return nil
+}
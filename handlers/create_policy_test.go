@@ -0,0 +1,186 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	lfakes "lib/fakes"
+	"lib/testsupport"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("POST /policies", func() {
+	var (
+		handler     http.Handler
+		request     *http.Request
+		resp        *httptest.ResponseRecorder
+		marshaler   *lfakes.Marshaler
+		unmarshaler *lfakes.Unmarshaler
+		logger      *lagertest.TestLogger
+		policies    *fakes.PolicyStore
+		enforcer    *fakes.Syncer
+		policy      models.Policy
+	)
+
+	var setPayload = func() {
+		payloadBytes, err := json.Marshal(policy)
+		Expect(err).NotTo(HaveOccurred())
+		request.Body = ioutil.NopCloser(bytes.NewBuffer(payloadBytes))
+	}
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		unmarshaler = &lfakes.Unmarshaler{}
+		unmarshaler.UnmarshalStub = json.Unmarshal
+		policies = &fakes.PolicyStore{}
+		enforcer = &fakes.Syncer{}
+		logger = lagertest.NewTestLogger("test")
+
+		createHandler := &handlers.CreatePolicy{
+			Unmarshaler: unmarshaler,
+			Marshaler:   marshaler,
+			Logger:      logger,
+			Policies:    policies,
+			Enforcer:    enforcer,
+		}
+		handler, request = rataWrap(createHandler, "POST", "/policies", rata.Params{})
+		resp = httptest.NewRecorder()
+
+		policy = models.Policy{
+			ID:          "some-policy-id",
+			Source:      "some-app-guid",
+			Destination: "some-other-app-guid",
+			Protocol:    "tcp",
+			Port:        8080,
+		}
+		setPayload()
+	})
+
+	It("creates the policy, syncs the sandboxes and responds with the policy", func() {
+		handler.ServeHTTP(resp, request)
+
+		Expect(resp.Code).To(Equal(http.StatusCreated))
+		Expect(policies.CreateCallCount()).To(Equal(1))
+		Expect(policies.CreateArgsForCall(0)).To(Equal(policy))
+		Expect(enforcer.SyncCallCount()).To(Equal(1))
+
+		var received models.Policy
+		Expect(json.Unmarshal(resp.Body.Bytes(), &received)).To(Succeed())
+		Expect(received).To(Equal(policy))
+	})
+
+	Context("when the sync fails", func() {
+		BeforeEach(func() {
+			enforcer.SyncReturns(errors.New("mango"))
+		})
+
+		It("logs the error and still responds with a 201", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusCreated))
+			Expect(logger).To(gbytes.Say("create-policy.*sync-failed.*mango"))
+		})
+	})
+
+	DescribeTable("valid policies",
+		func(modifier func()) {
+			modifier()
+			setPayload()
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusCreated))
+		},
+		Entry("any protocol", func() { policy.Protocol = ""; policy.Port = 0 }),
+		Entry("any udp port", func() { policy.Protocol = "udp"; policy.Port = 0 }),
+		Entry("icmp", func() { policy.Protocol = "icmp"; policy.Port = 0 }),
+	)
+
+	DescribeTable("invalid policies",
+		func(expectedError string, corrupter func()) {
+			corrupter()
+			setPayload()
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": ` + expectedError + `}`))
+			Expect(policies.CreateCallCount()).To(Equal(0))
+		},
+		Entry("missing id", `"missing required field \"id\""`, func() { policy.ID = "" }),
+		Entry("missing source", `"missing required field \"source_app\""`, func() { policy.Source = "" }),
+		Entry("missing destination", `"missing required field \"destination_app\""`, func() { policy.Destination = "" }),
+		Entry("unknown protocol", `"\"protocol\" must be \"tcp\", \"udp\" or \"icmp\""`, func() { policy.Protocol = "sctp" }),
+		Entry("port without protocol", `"\"port\" needs the \"tcp\" or \"udp\" protocol"`, func() { policy.Protocol = "" }),
+		Entry("port with icmp", `"\"port\" needs the \"tcp\" or \"udp\" protocol"`, func() { policy.Protocol = "icmp" }),
+		Entry("negative port", `"\"port\" must be between 0 and 65535"`, func() { policy.Port = -1 }),
+		Entry("port too large", `"\"port\" must be between 0 and 65535"`, func() { policy.Port = 65536 }),
+	)
+
+	Context("when the request body cannot be read", func() {
+		BeforeEach(func() {
+			request.Body = ioutil.NopCloser(&testsupport.BadReader{})
+		})
+
+		It("responds with a 400", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(logger).To(gbytes.Say("create-policy.*body-read-failed"))
+		})
+	})
+
+	Context("when the request body is not a policy", func() {
+		BeforeEach(func() {
+			request.Body = ioutil.NopCloser(bytes.NewBufferString("{{{"))
+		})
+
+		It("responds with a 400", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(logger).To(gbytes.Say("create-policy.*unmarshal-failed"))
+		})
+	})
+
+	Context("when the policy exists", func() {
+		BeforeEach(func() {
+			policies.CreateReturns(store.RecordExistsError)
+		})
+
+		It("responds with a 409 and does not sync", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusConflict))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "record already exists"}`))
+			Expect(enforcer.SyncCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			policies.CreateReturns(errors.New("kiwi"))
+		})
+
+		It("responds with a 500", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("create-policy.*create-failed.*kiwi"))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+	"lib/marshal"
+)
+
+type DeleteNetwork struct {
+	Marshaler   marshal.Marshaler
+	Logger      lager.Logger
+	Networks    store.NetworkStore
+	IPAllocator networkForgetter
+}
+
+func (h *DeleteNetwork) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("delete-network")
+	id := rata.Param(req, "network_id")
+
+	err := h.Networks.Delete(id)
+	if err != nil {
+		if err == store.NetworkInUseError {
+			logger.Error("network-in-use", err, lager.Data{"network_id": id})
+			resp.WriteHeader(http.StatusConflict)
+
+			err = marshalError(resp, h.Marshaler, err)
+			if err != nil {
+				logger.Error("marshal-error", err)
+			}
+			return
+		}
+		if err == store.RecordNotFoundError {
+			logger.Error("record-not-found", err)
+			resp.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error("delete-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h.IPAllocator.Forget(id)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	lfakes "lib/fakes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("DELETE /networks/:network_id", func() {
+	var (
+		deleteHandler *handlers.DeleteNetwork
+		marshaler     *lfakes.Marshaler
+		logger        *lagertest.TestLogger
+		networks      *fakes.NetworkStore
+		ipAllocator   *fakes.NetworkForgetter
+		resp          *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		networks = &fakes.NetworkStore{}
+		ipAllocator = &fakes.NetworkForgetter{}
+		logger = lagertest.NewTestLogger("test")
+		deleteHandler = &handlers.DeleteNetwork{
+			Marshaler:   marshaler,
+			Logger:      logger,
+			Networks:    networks,
+			IPAllocator: ipAllocator,
+		}
+		resp = httptest.NewRecorder()
+	})
+
+	serve := func() {
+		handler, request := rataWrap(deleteHandler, "DELETE", "/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+		handler.ServeHTTP(resp, request)
+	}
+
+	It("deletes the definition", func() {
+		serve()
+
+		Expect(resp.Code).To(Equal(http.StatusNoContent))
+		Expect(networks.DeleteCallCount()).To(Equal(1))
+		Expect(networks.DeleteArgsForCall(0)).To(Equal("network-id-1"))
+
+		Expect(ipAllocator.ForgetCallCount()).To(Equal(1))
+		Expect(ipAllocator.ForgetArgsForCall(0)).To(Equal("network-id-1"))
+	})
+
+	Context("when containers are attached to the network", func() {
+		BeforeEach(func() {
+			networks.DeleteReturns(store.NetworkInUseError)
+		})
+
+		It("responds with a 409", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusConflict))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "network has containers attached"}`))
+			Expect(logger).To(gbytes.Say("delete-network.*network-in-use.*network-id-1"))
+			Expect(ipAllocator.ForgetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the network is not defined", func() {
+		BeforeEach(func() {
+			networks.DeleteReturns(store.RecordNotFoundError)
+		})
+
+		It("responds with a 404", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusNotFound))
+			Expect(logger).To(gbytes.Say("delete-network.*record-not-found"))
+		})
+	})
+
+	Context("when deleting fails", func() {
+		BeforeEach(func() {
+			networks.DeleteReturns(errors.New("bang"))
+		})
+
+		It("responds with a 500", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("delete-network.*delete-failed.*bang"))
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+)
+
+type DeletePolicy struct {
+	Logger   lager.Logger
+	Policies store.PolicyStore
+	Enforcer policySyncer
+}
+
+func (h *DeletePolicy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("delete-policy")
+	id := rata.Param(req, "policy_id")
+
+	err := h.Policies.Delete(id)
+	if err != nil {
+		if err == store.RecordNotFoundError {
+			logger.Error("record-not-found", err)
+			resp.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error("delete-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Enforcer.Sync()
+	if err != nil {
+		logger.Error("sync-failed", err)
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("DELETE /policies/:policy_id", func() {
+	var (
+		deleteHandler *handlers.DeletePolicy
+		logger        *lagertest.TestLogger
+		policies      *fakes.PolicyStore
+		enforcer      *fakes.Syncer
+		resp          *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		policies = &fakes.PolicyStore{}
+		enforcer = &fakes.Syncer{}
+		logger = lagertest.NewTestLogger("test")
+		deleteHandler = &handlers.DeletePolicy{
+			Logger:   logger,
+			Policies: policies,
+			Enforcer: enforcer,
+		}
+		resp = httptest.NewRecorder()
+	})
+
+	serve := func() {
+		handler, request := rataWrap(deleteHandler, "DELETE", "/policies/:policy_id", rata.Params{"policy_id": "policy-id-1"})
+		handler.ServeHTTP(resp, request)
+	}
+
+	It("deletes the policy and syncs the sandboxes", func() {
+		serve()
+
+		Expect(resp.Code).To(Equal(http.StatusNoContent))
+		Expect(policies.DeleteCallCount()).To(Equal(1))
+		Expect(policies.DeleteArgsForCall(0)).To(Equal("policy-id-1"))
+		Expect(enforcer.SyncCallCount()).To(Equal(1))
+	})
+
+	Context("when the sync fails", func() {
+		BeforeEach(func() {
+			enforcer.SyncReturns(errors.New("mango"))
+		})
+
+		It("logs the error and still responds with a 204", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusNoContent))
+			Expect(logger).To(gbytes.Say("delete-policy.*sync-failed.*mango"))
+		})
+	})
+
+	Context("when the policy does not exist", func() {
+		BeforeEach(func() {
+			policies.DeleteReturns(store.RecordNotFoundError)
+		})
+
+		It("responds with a 404 and does not sync", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusNotFound))
+			Expect(enforcer.SyncCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			policies.DeleteReturns(errors.New("kiwi"))
+		})
+
+		It("responds with a 500", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("delete-policy.*delete-failed.*kiwi"))
+		})
+	})
+})
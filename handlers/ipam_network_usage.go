@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/tedsuo/rata"
+	"lib/marshal"
+)
+
+//go:generate counterfeiter -o ../fakes/usage_reporter.go --fake-name UsageReporter . usageReporter
+type usageReporter interface {
+	Usage(networkID string) (models.IPAMUsage, error)
+}
+
+type IPAMNetworkUsage struct {
+	Marshaler   marshal.Marshaler
+	Logger      lager.Logger
+	IPAllocator usageReporter
+}
+
+func (h *IPAMNetworkUsage) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("ipam-network-usage")
+	id := rata.Param(req, "network_id")
+
+	usage, err := h.IPAllocator.Usage(id)
+	if err == ipam.UnknownNetworkError {
+		logger.Error("unknown-network", err, lager.Data{"network_id": id})
+		resp.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("usage-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := h.Marshaler.Marshal(usage)
+	if err != nil {
+		logger.Error("marshal-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(payload)
+}
@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	lfakes "lib/fakes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("GET /ipam/networks/:network_id", func() {
+	var (
+		usageHandler *handlers.IPAMNetworkUsage
+		marshaler    *lfakes.Marshaler
+		logger       *lagertest.TestLogger
+		ipAllocator  *fakes.UsageReporter
+		usage        models.IPAMUsage
+	)
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		ipAllocator = &fakes.UsageReporter{}
+		logger = lagertest.NewTestLogger("test")
+		usageHandler = &handlers.IPAMNetworkUsage{
+			Marshaler:   marshaler,
+			Logger:      logger,
+			IPAllocator: ipAllocator,
+		}
+
+		usage = models.IPAMUsage{
+			NetworkID: "network-id-1",
+			Pools: []models.PoolUsage{{
+				Subnet:    "192.168.9.0/24",
+				Gateway:   "192.168.9.1",
+				Size:      255,
+				Allocated: 1,
+				Free:      252,
+				Allocations: map[string]string{
+					"container-id-1": "192.168.9.2",
+				},
+			}},
+		}
+		ipAllocator.UsageReturns(usage, nil)
+	})
+
+	It("returns the usage of the network as json", func() {
+		handler, request := rataWrap(usageHandler, "GET", "/ipam/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, request)
+
+		Expect(ipAllocator.UsageCallCount()).To(Equal(1))
+		Expect(ipAllocator.UsageArgsForCall(0)).To(Equal("network-id-1"))
+
+		var received models.IPAMUsage
+		Expect(json.Unmarshal(resp.Body.Bytes(), &received)).To(Succeed())
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(received).To(Equal(usage))
+	})
+
+	Context("when the allocator fails", func() {
+		BeforeEach(func() {
+			ipAllocator.UsageReturns(models.IPAMUsage{}, errors.New("nothing for you"))
+		})
+
+		It("responds with a 500", func() {
+			handler, request := rataWrap(usageHandler, "GET", "/ipam/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("ipam-network-usage.*usage-failed.*nothing for you"))
+		})
+	})
+
+	Context("when the network is unknown", func() {
+		BeforeEach(func() {
+			ipAllocator.UsageReturns(models.IPAMUsage{}, ipam.UnknownNetworkError)
+		})
+
+		It("responds with a 404", func() {
+			handler, request := rataWrap(usageHandler, "GET", "/ipam/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusNotFound))
+			Expect(logger).To(gbytes.Say("ipam-network-usage.*unknown-network.*network-id-1"))
+		})
+	})
+
+	Context("when marshaling fails", func() {
+		BeforeEach(func() {
+			marshaler.MarshalReturns([]byte("some-junk"), errors.New("bang"))
+		})
+
+		It("responds with a 500", func() {
+			handler, request := rataWrap(usageHandler, "GET", "/ipam/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("ipam-network-usage.*marshal-failed.*bang"))
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+	"lib/marshal"
+)
+
+type ListNetworks struct {
+	Marshaler marshal.Marshaler
+	Logger    lager.Logger
+	Networks  store.NetworkStore
+}
+
+func (h *ListNetworks) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("list-networks")
+
+	networks, err := h.Networks.All()
+	if err != nil {
+		logger.Error("networks-all-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := h.Marshaler.Marshal(networks)
+	if err != nil {
+		logger.Error("marshal-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(payload)
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	lfakes "lib/fakes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("GET /networks", func() {
+	var (
+		listHandler *handlers.ListNetworks
+		marshaler   *lfakes.Marshaler
+		logger      *lagertest.TestLogger
+		networks    *fakes.NetworkStore
+	)
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		networks = &fakes.NetworkStore{}
+		logger = lagertest.NewTestLogger("test")
+		listHandler = &handlers.ListNetworks{
+			Marshaler: marshaler,
+			Logger:    logger,
+			Networks:  networks,
+		}
+
+		networks.AllReturns([]models.Network{
+			{ID: "network-id-1", VNI: 1},
+			{ID: "network-id-2", VNI: 2, MTU: 1400},
+		}, nil)
+	})
+
+	It("returns all network definitions as json", func() {
+		handler, request := rataWrap(listHandler, "GET", "/networks", rata.Params{})
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, request)
+
+		var received []models.Network
+		Expect(json.Unmarshal(resp.Body.Bytes(), &received)).To(Succeed())
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(received).To(Equal([]models.Network{
+			{ID: "network-id-1", VNI: 1},
+			{ID: "network-id-2", VNI: 2, MTU: 1400},
+		}))
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			networks.AllReturns(nil, errors.New("nothing for you"))
+		})
+
+		It("responds with a 500", func() {
+			handler, request := rataWrap(listHandler, "GET", "/networks", rata.Params{})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("list-networks.*networks-all-failed.*nothing for you"))
+		})
+	})
+
+	Context("when marshaling fails", func() {
+		BeforeEach(func() {
+			marshaler.MarshalReturns(nil, errors.New("bang"))
+		})
+
+		It("responds with a 500", func() {
+			handler, request := rataWrap(listHandler, "GET", "/networks", rata.Params{})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("list-networks.*marshal-failed.*bang"))
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/lager"
+	"lib/marshal"
+)
+
+type ListPolicies struct {
+	Marshaler marshal.Marshaler
+	Logger    lager.Logger
+	Policies  store.PolicyStore
+}
+
+func (h *ListPolicies) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	logger := h.Logger.Session("list-policies")
+
+	policies, err := h.Policies.All()
+	if err != nil {
+		logger.Error("policies-all-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := h.Marshaler.Marshal(policies)
+	if err != nil {
+		logger.Error("marshal-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(payload)
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	lfakes "lib/fakes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("GET /policies", func() {
+	var (
+		listHandler *handlers.ListPolicies
+		marshaler   *lfakes.Marshaler
+		logger      *lagertest.TestLogger
+		policies    *fakes.PolicyStore
+		resp        *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		marshaler = &lfakes.Marshaler{}
+		marshaler.MarshalStub = json.Marshal
+		policies = &fakes.PolicyStore{}
+		logger = lagertest.NewTestLogger("test")
+		listHandler = &handlers.ListPolicies{
+			Marshaler: marshaler,
+			Logger:    logger,
+			Policies:  policies,
+		}
+		resp = httptest.NewRecorder()
+
+		policies.AllReturns([]models.Policy{
+			{ID: "policy-id-1", Source: "app-1", Destination: "app-2"},
+			{ID: "policy-id-2", Source: "app-2", Destination: "app-3", Protocol: "tcp", Port: 443},
+		}, nil)
+	})
+
+	serve := func() {
+		handler, request := rataWrap(listHandler, "GET", "/policies", rata.Params{})
+		handler.ServeHTTP(resp, request)
+	}
+
+	It("returns all policies as json", func() {
+		serve()
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(resp.Body.String()).To(MatchJSON(`[
+			{"id": "policy-id-1", "source_app": "app-1", "destination_app": "app-2", "protocol": "", "port": 0},
+			{"id": "policy-id-2", "source_app": "app-2", "destination_app": "app-3", "protocol": "tcp", "port": 443}
+		]`))
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			policies.AllReturns(nil, errors.New("nothing for you"))
+		})
+
+		It("responds with a 500", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("list-policies.*policies-all-failed.*nothing for you"))
+		})
+	})
+
+	Context("when marshaling fails", func() {
+		BeforeEach(func() {
+			marshaler.MarshalReturns(nil, errors.New("bang"))
+		})
+
+		It("responds with a 500", func() {
+			serve()
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("list-policies.*marshal-failed.*bang"))
+		})
+	})
+})
@@ -10,10 +10,13 @@ import (
 	"lib/marshal"
 )
 
+// NetworksListContainers responds with the definition of the network, when
+// there is one, and the containers attached to it.
 type NetworksListContainers struct {
 	Marshaler marshal.Marshaler
 	Logger    lager.Logger
 	Datastore store.Store
+	Networks  store.NetworkStore
 }
 
 func (h *NetworksListContainers) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
@@ -34,7 +37,20 @@ func (h *NetworksListContainers) ServeHTTP(resp http.ResponseWriter, req *http.R
 		}
 	}
 
-	payload, err := h.Marshaler.Marshal(containers)
+	details := models.NetworkDetails{Containers: containers}
+
+	network, err := h.Networks.Get(id)
+	switch err {
+	case nil:
+		details.Network = &network
+	case store.RecordNotFoundError:
+	default:
+		logger.Error("networks-get-failed", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := h.Marshaler.Marshal(details)
 	if err != nil {
 		logger.Error("marshal-failed", err)
 		resp.WriteHeader(http.StatusInternalServerError)
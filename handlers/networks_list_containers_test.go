@@ -10,6 +10,7 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/handlers"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
@@ -23,17 +24,21 @@ var _ = Describe("GET /networks/:network_id", func() {
 		marshaler  *lfakes.Marshaler
 		logger     *lagertest.TestLogger
 		datastore  *fakes.Store
+		networks   *fakes.NetworkStore
 	)
 
 	BeforeEach(func() {
 		marshaler = &lfakes.Marshaler{}
 		marshaler.MarshalStub = json.Marshal
 		datastore = &fakes.Store{}
+		networks = &fakes.NetworkStore{}
+		networks.GetReturns(models.Network{}, store.RecordNotFoundError)
 		logger = lagertest.NewTestLogger("test")
 		getHandler = &handlers.NetworksListContainers{
 			Marshaler: marshaler,
 			Logger:    logger,
 			Datastore: datastore,
+			Networks:  networks,
 		}
 
 		datastore.AllReturns([]models.Container{
@@ -49,17 +54,53 @@ var _ = Describe("GET /networks/:network_id", func() {
 		resp := httptest.NewRecorder()
 		handler.ServeHTTP(resp, request)
 
-		var receivedContainers []models.Container
-		err := json.Unmarshal(resp.Body.Bytes(), &receivedContainers)
+		var details models.NetworkDetails
+		err := json.Unmarshal(resp.Body.Bytes(), &details)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(resp.Code).To(Equal(http.StatusOK))
-		Expect(receivedContainers).To(Equal([]models.Container{
+		Expect(details.Network).To(BeNil())
+		Expect(details.Containers).To(Equal([]models.Container{
 			{ID: "container-id-1", IP: "192.168.0.1", NetworkID: "network-id-1"},
 			{ID: "container-id-2", IP: "192.168.0.2", NetworkID: "network-id-1"},
 		}))
 	})
 
+	Context("when the network is defined", func() {
+		BeforeEach(func() {
+			networks.GetReturns(models.Network{ID: "network-id-1", Name: "some-name", VNI: 42}, nil)
+		})
+
+		It("includes the definition", func() {
+			handler, request := rataWrap(getHandler, "GET", "/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			var details models.NetworkDetails
+			Expect(json.Unmarshal(resp.Body.Bytes(), &details)).To(Succeed())
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(details.Network).To(Equal(&models.Network{ID: "network-id-1", Name: "some-name", VNI: 42}))
+			Expect(details.Containers).To(HaveLen(2))
+			Expect(networks.GetArgsForCall(0)).To(Equal("network-id-1"))
+		})
+	})
+
+	Context("when getting the definition fails", func() {
+		BeforeEach(func() {
+			networks.GetReturns(models.Network{}, errors.New("kaboom"))
+		})
+
+		It("responds with a 500", func() {
+			handler, request := rataWrap(getHandler, "GET", "/networks/:network_id", rata.Params{"network_id": "network-id-1"})
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(logger).To(gbytes.Say("networks-list-containers.*networks-get-failed.*kaboom"))
+		})
+	})
+
 	Context("when the datastore fails", func() {
 		BeforeEach(func() {
 			datastore.AllReturns(nil, errors.New("nothing for you"))
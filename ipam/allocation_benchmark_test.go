@@ -0,0 +1,208 @@
+package ipam_test
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+)
+
+// These benchmarks run without a database. They compare allocating from a
+// nearly full /16 the way the allocator used to, walking the subnet from its
+// start and asking the store about every address, with the next-fit bitmap
+// the allocator and stores use now.
+
+const benchmarkFree = 16
+
+var benchmarkSubnet = net.IPNet{
+	IP:   net.ParseIP("10.255.0.0").To4(),
+	Mask: net.CIDRMask(16, 32),
+}
+
+func benchmarkConfig() *types.IPConfig {
+	return &types.IPConfig{IP: benchmarkSubnet}
+}
+
+// scanStore is the in-memory store as it was before the bitmap: one map from
+// address to id, so that releasing or looking up an id visits every
+// reservation.
+type scanStore struct {
+	allocated map[string]string
+}
+
+func (s *scanStore) Reserve(id string, ip net.IP) bool {
+	if _, ok := s.allocated[ip.String()]; ok {
+		return false
+	}
+
+	s.allocated[ip.String()] = id
+	return true
+}
+
+func (s *scanStore) ReleaseByID(id string) {
+	for k, v := range s.allocated {
+		if v == id {
+			delete(s.allocated, k)
+		}
+	}
+}
+
+func (s *scanStore) Contains(id string) bool {
+	for _, v := range s.allocated {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// scanAllocate is the allocator's old AllocateIP: every call starts at the
+// beginning of the subnet and tries each address in turn.
+func scanAllocate(store *scanStore, config *types.IPConfig, id string) (net.IP, error) {
+	if store.Contains(id) {
+		return nil, ipam.AlreadyOnNetworkError
+	}
+
+	ip := config.IP.IP
+	if config.Gateway == nil {
+		ip = benchmarkNextIP(ip)
+		config.Gateway = ip
+	}
+
+	for {
+		ip = benchmarkNextIP(ip)
+
+		if !config.IP.Contains(ip) {
+			return nil, ipam.NoMoreAddressesError
+		}
+
+		if config.Gateway.Equal(ip) {
+			continue
+		}
+
+		if store.Reserve(id, ip) {
+			return ip, nil
+		}
+	}
+}
+
+func benchmarkNextIP(ip net.IP) net.IP {
+	n := big.NewInt(0).SetBytes(ip.To4())
+	n.Add(n, big.NewInt(1))
+	return net.IP(n.Bytes())
+}
+
+func filledScanStore(b *testing.B) (*scanStore, *types.IPConfig) {
+	store := &scanStore{allocated: map[string]string{}}
+	config := benchmarkConfig()
+
+	ones, bits := benchmarkSubnet.Mask.Size()
+	ip := benchmarkNextIP(benchmarkNextIP(benchmarkSubnet.IP))
+	for i := 0; i < 1<<uint(bits-ones)-3-benchmarkFree; i++ {
+		store.Reserve(fmt.Sprintf("container-%d", i), ip)
+		ip = benchmarkNextIP(ip)
+	}
+
+	return store, config
+}
+
+func filledNextFitStore(b *testing.B) (ipam.AllocatorStore, *ipam.Pool) {
+	pool := ipam.NewPool(types.IPConfig{
+		IP:      benchmarkSubnet,
+		Gateway: net.ParseIP("10.255.0.1"),
+	})
+
+	store := ipam.NewStore(&sync.Mutex{})
+	for i := uint64(0); i < pool.Size()-benchmarkFree; i++ {
+		_, err := store.ReserveNext(fmt.Sprintf("container-%d", i), pool)
+		if err != nil {
+			b.Fatalf("filling store: %s", err)
+		}
+	}
+
+	return store, pool
+}
+
+func BenchmarkScanAllocateAndRelease(b *testing.B) {
+	store, config := filledScanStore(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := scanAllocate(store, config, "some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		store.ReleaseByID("some-container")
+	}
+}
+
+func BenchmarkNextFitReserveNextAndRelease(b *testing.B) {
+	store, pool := filledNextFitStore(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := store.ReserveNext("some-container", pool)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = store.ReleaseByID("some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextFitAllocateAndRelease(b *testing.B) {
+	store, _ := filledNextFitStore(b)
+	storeFactory := storeFactoryFunc(func(string) (ipam.AllocatorStore, error) { return store, nil })
+	configFactory := &ipam.ConfigFactory{
+		Config: ipam.Config{IP4: benchmarkConfig()},
+	}
+	allocator := ipam.New(storeFactory, &sync.Mutex{}, configFactory, &sync.Mutex{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := allocator.AllocateIP("some-network", "some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = allocator.ReleaseIP("some-network", "some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanContains(b *testing.B) {
+	store, _ := filledScanStore(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Contains("container-30000")
+	}
+}
+
+func BenchmarkNextFitContains(b *testing.B) {
+	store, _ := filledNextFitStore(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := store.Contains("container-30000")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type storeFactoryFunc func(string) (ipam.AllocatorStore, error)
+
+func (f storeFactoryFunc) Create(networkID string) (ipam.AllocatorStore, error) {
+	return f(networkID)
+}
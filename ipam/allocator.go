@@ -3,22 +3,34 @@ package ipam
 import (
 	"errors"
 	"fmt"
-	"math/big"
 	"net"
 	"sync"
 
 	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 )
 
 //go:generate counterfeiter -o ../fakes/allocator_store.go --fake-name AllocatorStore . AllocatorStore
 type AllocatorStore interface {
 	Reserve(id string, ip net.IP) (bool, error)
+	ReserveNext(id string, pool *Pool) (net.IP, error)
 	ReleaseByID(id string) error
-	Contains(id string) bool
+	Contains(id string) (bool, error)
+	Reservations() ([]Reservation, error)
+}
+
+// Reservation is an address held by a container. Addresses in quarantine
+// are held by nobody and have an empty ContainerID.
+type Reservation struct {
+	ContainerID string
+	IP          net.IP
 }
 
 var NoMoreAddressesError = errors.New("no addresses available")
 var AlreadyOnNetworkError = errors.New("already on this network")
+var AddressNotInSubnetError = errors.New("requested address is not in the network's subnet")
+var AddressTakenError = errors.New("requested address is already in use")
+var UnknownNetworkError = errors.New("network has no definition and no reservations")
 
 //go:generate counterfeiter -o ../fakes/store_factory.go --fake-name StoreFactory . storeFactory
 type storeFactory interface {
@@ -27,7 +39,8 @@ type storeFactory interface {
 
 //go:generate counterfeiter -o ../fakes/config_factory.go --fake-name ConfigFactory . configFactory
 type configFactory interface {
-	Create(networkID string) (types.IPConfig, error)
+	Create(networkID string) (Config, error)
+	Defined(networkID string) (bool, error)
 }
 
 //go:generate counterfeiter -o ../fakes/locker.go --fake-name Locker . locker
@@ -42,13 +55,16 @@ type allocator struct {
 
 	configFactory configFactory
 	configLocker  locker
-	configs       map[string]*types.IPConfig
+	configs       map[string]*Config
 }
 
 //go:generate counterfeiter -o ../fakes/ip_allocator.go --fake-name IPAllocator . IPAllocator
 type IPAllocator interface {
 	AllocateIP(networkID, containerID string) (*types.Result, error)
+	AllocateStaticIP(networkID, containerID string, ip net.IP) (*types.Result, error)
 	ReleaseIP(networkID, containerID string) error
+	Usage(networkID string) (models.IPAMUsage, error)
+	Forget(networkID string)
 }
 
 func New(storeFactory storeFactory, storeLocker locker, configFactory configFactory, configLocker sync.Locker) IPAllocator {
@@ -59,11 +75,22 @@ func New(storeFactory storeFactory, storeLocker locker, configFactory configFact
 
 		configFactory: configFactory,
 		configLocker:  configLocker,
-		configs:       map[string]*types.IPConfig{},
+		configs:       map[string]*Config{},
 	}
 }
 
 func (a *allocator) AllocateIP(networkID, containerID string) (*types.Result, error) {
+	return a.allocate(networkID, containerID, nil)
+}
+
+// AllocateStaticIP reserves the requested address for the container instead
+// of the next free one. On a dual-stack network the other family is still
+// allocated dynamically.
+func (a *allocator) AllocateStaticIP(networkID, containerID string, ip net.IP) (*types.Result, error) {
+	return a.allocate(networkID, containerID, ip)
+}
+
+func (a *allocator) allocate(networkID, containerID string, requested net.IP) (*types.Result, error) {
 	config, err := a.getConfig(networkID)
 	if err != nil {
 		return nil, err
@@ -74,48 +101,115 @@ func (a *allocator) AllocateIP(networkID, containerID string) (*types.Result, er
 		return nil, err
 	}
 
-	if store.Contains(containerID) {
-		return nil, AlreadyOnNetworkError
+	alreadyReserved, err := store.Contains(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reservations: %s", err)
 	}
 
-	ip := config.IP.IP
-
-	if config.Gateway == nil {
-		ip = nextIP(ip)
-		config.Gateway = ip
+	if alreadyReserved {
+		return nil, AlreadyOnNetworkError
 	}
 
-	for {
-		ip = nextIP(ip)
+	requested4, requested6 := splitFamily(requested)
+	if (requested4 != nil && config.IP4 == nil) || (requested6 != nil && config.IP6 == nil) {
+		return nil, AddressNotInSubnetError
+	}
 
-		if !config.IP.Contains(ip) {
-			return nil, NoMoreAddressesError
-		}
+	result := &types.Result{}
 
-		if config.Gateway.Equal(ip) {
-			continue
+	if config.IP4 != nil {
+		result.IP4, err = allocateFrom(store, containerID, config.IP4, config.Exclusions, requested4)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		ok, err := store.Reserve(containerID, ip)
+	if config.IP6 != nil {
+		result.IP6, err = allocateFrom(store, containerID, config.IP6, config.Exclusions, requested6)
 		if err != nil {
-			return nil, fmt.Errorf("failed to reserve IP: %s", err)
+			return nil, releaseAfter(store, containerID, err)
 		}
+	}
 
-		if ok {
-			break
-		}
+	return result, nil
+}
+
+func allocateFrom(store AllocatorStore, containerID string, config *types.IPConfig, exclusions []net.IPNet, requested net.IP) (*types.IPConfig, error) {
+	if config.Gateway == nil {
+		config.Gateway = nextIP(config.IP.IP)
+	}
+
+	pool := NewPool(*config, exclusions...)
+
+	var ip net.IP
+	var err error
+	if requested != nil {
+		ip, err = reserveStatic(store, containerID, pool, requested)
+	} else {
+		ip, err = store.ReserveNext(containerID, pool)
+	}
+
+	if err == NoMoreAddressesError || err == AddressNotInSubnetError || err == AddressTakenError {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve IP: %s", err)
 	}
 
-	result := &types.IPConfig{
+	return &types.IPConfig{
 		IP: net.IPNet{
 			IP:   ip,
 			Mask: config.IP.Mask,
 		},
 		Gateway: config.Gateway,
 		Routes:  config.Routes,
+	}, nil
+}
+
+func reserveStatic(store AllocatorStore, containerID string, pool *Pool, ip net.IP) (net.IP, error) {
+	offset, ok := pool.Offset(ip)
+	if !ok {
+		return nil, AddressNotInSubnetError
+	}
+
+	if pool.IsReserved(offset) {
+		return nil, AddressTakenError
+	}
+
+	ip = pool.IP(offset)
+	reserved, err := store.Reserve(containerID, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reserved {
+		return nil, AddressTakenError
+	}
+
+	return ip, nil
+}
+
+func splitFamily(ip net.IP) (net.IP, net.IP) {
+	if ip == nil {
+		return nil, nil
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4, nil
 	}
 
-	return &types.Result{IP4: result}, nil
+	return nil, ip.To16()
+}
+
+// releaseAfter gives back whatever was reserved for the container before a
+// later family failed to allocate, so that a dual-stack ADD is all or nothing.
+func releaseAfter(store AllocatorStore, containerID string, cause error) error {
+	err := store.ReleaseByID(containerID)
+	if err != nil {
+		return fmt.Errorf("%s (release failed: %s)", cause, err)
+	}
+
+	return cause
 }
 
 func (a *allocator) ReleaseIP(networkID, containerID string) error {
@@ -132,7 +226,100 @@ func (a *allocator) ReleaseIP(networkID, containerID string) error {
 	return nil
 }
 
-func (a *allocator) getConfig(networkID string) (*types.IPConfig, error) {
+// Usage reports the size of each of the network's pools and which addresses
+// in them are held. A network that is neither defined nor holds any
+// reservations is unknown.
+func (a *allocator) Usage(networkID string) (models.IPAMUsage, error) {
+	usage := models.IPAMUsage{
+		NetworkID: networkID,
+		Pools:     []models.PoolUsage{},
+	}
+
+	config, err := a.getConfig(networkID)
+	if err != nil {
+		return usage, err
+	}
+
+	store, err := a.getStore(networkID)
+	if err != nil {
+		return usage, err
+	}
+
+	reservations, err := store.Reservations()
+	if err != nil {
+		return usage, fmt.Errorf("failed to list reservations: %s", err)
+	}
+
+	if len(reservations) == 0 {
+		defined, err := a.configFactory.Defined(networkID)
+		if err != nil {
+			return usage, fmt.Errorf("failed to look up network %q: %s", networkID, err)
+		}
+
+		if !defined {
+			return usage, UnknownNetworkError
+		}
+	}
+
+	for _, ipConfig := range []*types.IPConfig{config.IP4, config.IP6} {
+		if ipConfig != nil {
+			usage.Pools = append(usage.Pools, poolUsage(*ipConfig, config.Exclusions, reservations))
+		}
+	}
+
+	return usage, nil
+}
+
+func poolUsage(config types.IPConfig, exclusions []net.IPNet, reservations []Reservation) models.PoolUsage {
+	if config.Gateway == nil {
+		config.Gateway = nextIP(config.IP.IP)
+	}
+
+	pool := NewPool(config, exclusions...)
+	bitmap := pool.NewBitmap()
+
+	usage := models.PoolUsage{
+		Subnet:      config.IP.String(),
+		Gateway:     config.Gateway.String(),
+		Size:        pool.SubnetSize(),
+		Allocations: map[string]string{},
+	}
+
+	for _, r := range reservations {
+		offset, ok := pool.Offset(r.IP)
+		if !ok || !bitmap.Set(offset) {
+			continue
+		}
+
+		if r.ContainerID == "" {
+			usage.Quarantined++
+			continue
+		}
+
+		usage.Allocated++
+		usage.Allocations[r.ContainerID] = r.IP.String()
+	}
+
+	usage.Free = pool.Size() - bitmap.Used()
+
+	return usage
+}
+
+// Forget drops the configuration and store cached for the network, so that
+// both are built afresh from the network's current definition the next time
+// they are needed. The store goes too because the bitmaps it caches do not
+// account for a change of exclusions.
+func (a *allocator) Forget(networkID string) {
+	a.configLocker.Lock()
+	delete(a.configs, networkID)
+	a.configLocker.Unlock()
+
+	a.storeLocker.Lock()
+	delete(a.stores, networkID)
+	a.storeLocker.Unlock()
+}
+
+func (a *allocator) getConfig(networkID string) (*Config, error) {
 	a.configLocker.Lock()
 	defer a.configLocker.Unlock()
 
@@ -167,7 +354,20 @@ func (a *allocator) getStore(networkID string) (AllocatorStore, error) {
 }
 
 func nextIP(ip net.IP) net.IP {
-	newIPInt := big.NewInt(0).SetBytes(ip.To4())
-	newIPInt.Add(newIPInt, big.NewInt(1))
-	return net.IP(newIPInt.Bytes())
+	current := ip.To4()
+	if current == nil {
+		current = ip.To16()
+	}
+
+	next := make(net.IP, len(current))
+	copy(next, current)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
 }
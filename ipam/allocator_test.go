@@ -3,10 +3,12 @@ package ipam_test
 import (
 	"errors"
 	"net"
+	"sync"
 
 	"github.com/appc/cni/pkg/types"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -19,7 +21,6 @@ var _ = Describe("IP Address Management", func() {
 		configFactory *fakes.ConfigFactory
 		configLocker  *fakes.Locker
 		allocator     ipam.IPAllocator
-		allocated     map[string]struct{}
 		config        types.IPConfig
 	)
 
@@ -43,17 +44,10 @@ var _ = Describe("IP Address Management", func() {
 				},
 			}},
 		}
-		configFactory.CreateReturns(config, nil)
+		configFactory.CreateReturns(ipam.Config{IP4: &config}, nil)
 
-		allocated = map[string]struct{}{}
-		store.ReserveStub = func(containerID string, ip net.IP) (bool, error) {
-			s := ip.String()
-			if _, ok := allocated[s]; ok {
-				return false, nil
-			}
-			allocated[s] = struct{}{}
-			return true, nil
-		}
+		backingStore := ipam.NewStore(&sync.Mutex{})
+		store.ReserveNextStub = backingStore.ReserveNext
 
 		storeFactory.CreateReturns(store, nil)
 
@@ -73,15 +67,14 @@ var _ = Describe("IP Address Management", func() {
 			Expect(storeFactory.CreateCallCount()).To(Equal(1))
 			Expect(storeFactory.CreateArgsForCall(0)).To(Equal("network-id"))
 
-			By("skipping the gateway address gateway")
-			Expect(allocated).NotTo(HaveKey("192.168.2.1"))
+			By("defaulting the gateway to the first address")
 			Expect(result.IP4.Gateway.String()).To(Equal("192.168.2.1"))
 
-			By("reserving the IP address")
-			Expect(store.ReserveCallCount()).To(Equal(1))
-			containerID, ipAddress := store.ReserveArgsForCall(0)
+			By("reserving the next address from the subnet pool")
+			Expect(store.ReserveNextCallCount()).To(Equal(1))
+			containerID, pool := store.ReserveNextArgsForCall(0)
 			Expect(containerID).To(Equal("container-id"))
-			Expect(ipAddress.String()).To(Equal("192.168.2.2"))
+			Expect(pool.Key()).To(Equal("192.168.2.0/16"))
 
 			By("returning the result")
 			Expect(result).To(Equal(&types.Result{
@@ -128,7 +121,7 @@ var _ = Describe("IP Address Management", func() {
 
 		Context("when the config factory fails to create a store", func() {
 			BeforeEach(func() {
-				configFactory.CreateReturns(types.IPConfig{}, errors.New("network not found"))
+				configFactory.CreateReturns(ipam.Config{}, errors.New("network not found"))
 			})
 
 			It("returns a meaningful error", func() {
@@ -151,15 +144,27 @@ var _ = Describe("IP Address Management", func() {
 
 		Context("when calling the allocator with duplicate network and container", func() {
 			It("should return a meaningful error", func() {
-				store.ContainsReturns(false)
+				store.ContainsReturns(false, nil)
 				_, err := allocator.AllocateIP("network-id", "container-id")
 				Expect(err).NotTo(HaveOccurred())
-				store.ContainsReturns(true)
+				store.ContainsReturns(true, nil)
 				_, err = allocator.AllocateIP("network-id", "container-id")
 				Expect(err).To(Equal(ipam.AlreadyOnNetworkError))
 			})
 		})
 
+		Context("when checking the store for an existing reservation fails", func() {
+			BeforeEach(func() {
+				store.ContainsReturns(false, errors.New("connection reset"))
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := allocator.AllocateIP("network-id", "container-id")
+				Expect(err).To(MatchError("failed to check reservations: connection reset"))
+				Expect(store.ReserveCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when the store factory fails to create a store", func() {
 			BeforeEach(func() {
 				storeFactory.CreateReturns(nil, errors.New("out of disk space"))
@@ -174,7 +179,8 @@ var _ = Describe("IP Address Management", func() {
 
 		Context("when the address space is exhausted", func() {
 			BeforeEach(func() {
-				store.ReserveReturns(false, nil)
+				store.ReserveNextStub = nil
+				store.ReserveNextReturns(nil, ipam.NoMoreAddressesError)
 			})
 
 			It("returns a meaningful error", func() {
@@ -185,7 +191,8 @@ var _ = Describe("IP Address Management", func() {
 
 		Context("when the store reservation fails", func() {
 			BeforeEach(func() {
-				store.ReserveReturns(false, errors.New("this is a problem"))
+				store.ReserveNextStub = nil
+				store.ReserveNextReturns(nil, errors.New("this is a problem"))
 			})
 
 			It("returns a meaningful error", func() {
@@ -194,10 +201,28 @@ var _ = Describe("IP Address Management", func() {
 			})
 		})
 
+		Context("when the network has exclusions", func() {
+			BeforeEach(func() {
+				configFactory.CreateReturns(ipam.Config{
+					IP4: &config,
+					Exclusions: []net.IPNet{{
+						IP:   net.ParseIP("192.168.2.2").To4(),
+						Mask: net.CIDRMask(31, 32),
+					}},
+				}, nil)
+			})
+
+			It("does not hand out excluded addresses", func() {
+				result, err := allocator.AllocateIP("network-id", "container-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IP4.IP.IP.String()).To(Equal("192.168.2.4"))
+			})
+		})
+
 		Context("when a gateway is specified", func() {
 			BeforeEach(func() {
 				config.Gateway = net.ParseIP("192.168.2.3")
-				configFactory.CreateReturns(config, nil)
+				configFactory.CreateReturns(ipam.Config{IP4: &config}, nil)
 			})
 
 			It("uses the specified gateway", func() {
@@ -227,6 +252,326 @@ var _ = Describe("IP Address Management", func() {
 		})
 	})
 
+	Describe("AllocateStaticIP", func() {
+		BeforeEach(func() {
+			store.ReserveReturns(true, nil)
+		})
+
+		It("reserves the requested address", func() {
+			result, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("192.168.7.9"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.IP4.IP.String()).To(Equal("192.168.7.9/16"))
+			Expect(result.IP4.Gateway.String()).To(Equal("192.168.2.1"))
+			Expect(result.IP4.Routes).To(Equal(config.Routes))
+
+			Expect(store.ReserveCallCount()).To(Equal(1))
+			id, ip := store.ReserveArgsForCall(0)
+			Expect(id).To(Equal("container-id"))
+			Expect(ip.String()).To(Equal("192.168.7.9"))
+			Expect(store.ReserveNextCallCount()).To(Equal(0))
+		})
+
+		Context("when the address is outside the subnet", func() {
+			It("returns an AddressNotInSubnetError", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("10.0.0.1"))
+				Expect(err).To(Equal(ipam.AddressNotInSubnetError))
+				Expect(store.ReserveCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the address is of a family the network does not have", func() {
+			It("returns an AddressNotInSubnetError", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("fd00::9"))
+				Expect(err).To(Equal(ipam.AddressNotInSubnetError))
+			})
+		})
+
+		Context("when the address is the gateway", func() {
+			It("returns an AddressTakenError", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("192.168.2.1"))
+				Expect(err).To(Equal(ipam.AddressTakenError))
+				Expect(store.ReserveCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the address is excluded", func() {
+			BeforeEach(func() {
+				configFactory.CreateReturns(ipam.Config{
+					IP4: &config,
+					Exclusions: []net.IPNet{{
+						IP:   net.ParseIP("192.168.7.0").To4(),
+						Mask: net.CIDRMask(24, 32),
+					}},
+				}, nil)
+			})
+
+			It("returns an AddressTakenError", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("192.168.7.9"))
+				Expect(err).To(Equal(ipam.AddressTakenError))
+			})
+		})
+
+		Context("when another container holds the address", func() {
+			BeforeEach(func() {
+				store.ReserveReturns(false, nil)
+			})
+
+			It("returns an AddressTakenError", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("192.168.7.9"))
+				Expect(err).To(Equal(ipam.AddressTakenError))
+			})
+		})
+
+		Context("when the store fails to reserve", func() {
+			BeforeEach(func() {
+				store.ReserveReturns(false, errors.New("boom"))
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := allocator.AllocateStaticIP("network-id", "container-id", net.ParseIP("192.168.7.9"))
+				Expect(err).To(MatchError("failed to reserve IP: boom"))
+			})
+		})
+	})
+
+	Describe("AllocateIP on a dual-stack network", func() {
+		var config6 types.IPConfig
+
+		BeforeEach(func() {
+			config6 = types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:0:2::"),
+					Mask: net.CIDRMask(64, 128),
+				},
+				Routes: []types.Route{{
+					Dst: net.IPNet{
+						IP:   net.ParseIP("fd00::"),
+						Mask: net.CIDRMask(48, 128),
+					},
+				}},
+			}
+			configFactory.CreateReturns(ipam.Config{IP4: &config, IP6: &config6}, nil)
+		})
+
+		It("reserves an address from each family", func() {
+			result, err := allocator.AllocateIP("network-id", "container-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(store.ReserveNextCallCount()).To(Equal(2))
+			containerID, pool := store.ReserveNextArgsForCall(0)
+			Expect(containerID).To(Equal("container-id"))
+			Expect(pool.Key()).To(Equal("192.168.2.0/16"))
+
+			containerID, pool = store.ReserveNextArgsForCall(1)
+			Expect(containerID).To(Equal("container-id"))
+			Expect(pool.Key()).To(Equal("fd00:0:0:2::/64"))
+
+			Expect(result.IP4.IP.String()).To(Equal("192.168.2.2/16"))
+			Expect(result.IP6).To(Equal(&types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:0:2::2"),
+					Mask: net.CIDRMask(64, 128),
+				},
+				Gateway: net.ParseIP("fd00:0:0:2::1"),
+				Routes: []types.Route{{
+					Dst: net.IPNet{
+						IP:   net.ParseIP("fd00::"),
+						Mask: net.CIDRMask(48, 128),
+					},
+				}},
+			}))
+		})
+
+		It("carries into the higher bytes of the address", func() {
+			config6.IP.IP = net.ParseIP("fd00:0:0:2::fffe")
+			configFactory.CreateReturns(ipam.Config{IP6: &config6}, nil)
+
+			result, err := allocator.AllocateIP("network-id", "container-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.IP4).To(BeNil())
+			Expect(result.IP6.Gateway.String()).To(Equal("fd00:0:0:2::ffff"))
+			Expect(result.IP6.IP.IP.String()).To(Equal("fd00:0:0:2::1:0"))
+		})
+
+		Context("when the IPv6 allocation fails", func() {
+			BeforeEach(func() {
+				config6.Gateway = net.ParseIP("fd00:0:0:2::1")
+				config6.IP.IP = net.ParseIP("fd00:0:0:2:ffff:ffff:ffff:ffff")
+			})
+
+			It("releases the IPv4 reservation and returns the error", func() {
+				_, err := allocator.AllocateIP("network-id", "container-id")
+				Expect(err).To(Equal(ipam.NoMoreAddressesError))
+
+				Expect(store.ReleaseByIDCallCount()).To(Equal(1))
+				Expect(store.ReleaseByIDArgsForCall(0)).To(Equal("container-id"))
+			})
+
+			Context("when the release fails too", func() {
+				BeforeEach(func() {
+					store.ReleaseByIDReturns(errors.New("nope"))
+				})
+
+				It("reports both failures", func() {
+					_, err := allocator.AllocateIP("network-id", "container-id")
+					Expect(err).To(MatchError("no addresses available (release failed: nope)"))
+				})
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		BeforeEach(func() {
+			config.IP.Mask = net.CIDRMask(24, 32)
+			configFactory.CreateReturns(ipam.Config{
+				IP4: &config,
+				Exclusions: []net.IPNet{{
+					IP:   net.ParseIP("192.168.2.16").To4(),
+					Mask: net.CIDRMask(28, 32),
+				}},
+			}, nil)
+
+			store.ReservationsReturns([]ipam.Reservation{
+				{ContainerID: "container-id-1", IP: net.ParseIP("192.168.2.2")},
+				{ContainerID: "container-id-2", IP: net.ParseIP("192.168.2.3")},
+				{ContainerID: "", IP: net.ParseIP("192.168.2.4")},
+				{ContainerID: "container-id-3", IP: net.ParseIP("10.0.0.1")},
+			}, nil)
+		})
+
+		It("reports the size of the pool and who holds what", func() {
+			usage, err := allocator.Usage("network-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(usage).To(Equal(models.IPAMUsage{
+				NetworkID: "network-id",
+				Pools: []models.PoolUsage{{
+					Subnet:      "192.168.2.0/24",
+					Gateway:     "192.168.2.1",
+					Size:        255,
+					Allocated:   2,
+					Quarantined: 1,
+					Free:        255 - 1 - 1 - 16 - 3,
+					Allocations: map[string]string{
+						"container-id-1": "192.168.2.2",
+						"container-id-2": "192.168.2.3",
+					},
+				}},
+			}))
+		})
+
+		It("does not look the network up when it holds reservations", func() {
+			_, err := allocator.Usage("network-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(configFactory.DefinedCallCount()).To(Equal(0))
+		})
+
+		Context("when the network is IPv6", func() {
+			BeforeEach(func() {
+				configFactory.CreateReturns(ipam.Config{
+					IP6: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("fd00:0:0:9::"),
+							Mask: net.CIDRMask(64, 128),
+						},
+					},
+				}, nil)
+
+				store.ReservationsReturns([]ipam.Reservation{
+					{ContainerID: "container-id-1", IP: net.ParseIP("fd00:0:0:9::2")},
+				}, nil)
+			})
+
+			It("reports the size of the subnet rather than of the part handed out", func() {
+				usage, err := allocator.Usage("network-id")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(usage.Pools).To(HaveLen(1))
+				Expect(usage.Pools[0].Size).To(Equal(uint64(1<<64 - 1)))
+				Expect(usage.Pools[0].Free).To(Equal(uint64(1<<20 - 1 - 1)))
+			})
+		})
+
+		Context("when the network holds no reservations", func() {
+			BeforeEach(func() {
+				store.ReservationsReturns([]ipam.Reservation{}, nil)
+				configFactory.DefinedReturns(true, nil)
+			})
+
+			It("reports the usage of a defined network", func() {
+				usage, err := allocator.Usage("network-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(usage.Pools).To(HaveLen(1))
+
+				Expect(configFactory.DefinedCallCount()).To(Equal(1))
+				Expect(configFactory.DefinedArgsForCall(0)).To(Equal("network-id"))
+			})
+
+			Context("when the network is not defined either", func() {
+				BeforeEach(func() {
+					configFactory.DefinedReturns(false, nil)
+				})
+
+				It("returns an UnknownNetworkError", func() {
+					_, err := allocator.Usage("network-id")
+					Expect(err).To(Equal(ipam.UnknownNetworkError))
+				})
+			})
+
+			Context("when looking the network up fails", func() {
+				BeforeEach(func() {
+					configFactory.DefinedReturns(false, errors.New("turnip"))
+				})
+
+				It("returns a meaningful error", func() {
+					_, err := allocator.Usage("network-id")
+					Expect(err).To(MatchError(`failed to look up network "network-id": turnip`))
+				})
+			})
+		})
+
+		Context("when listing the reservations fails", func() {
+			BeforeEach(func() {
+				store.ReservationsReturns(nil, errors.New("potato"))
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := allocator.Usage("network-id")
+				Expect(err).To(MatchError("failed to list reservations: potato"))
+			})
+		})
+	})
+
+	Describe("Forget", func() {
+		BeforeEach(func() {
+			_, err := allocator.AllocateIP("network-id", "container-id")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("builds the configuration and store afresh the next time they are needed", func() {
+			allocator.Forget("network-id")
+
+			_, err := allocator.AllocateIP("network-id", "other-container-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(configFactory.CreateCallCount()).To(Equal(2))
+			Expect(storeFactory.CreateCallCount()).To(Equal(2))
+		})
+
+		It("keeps what it cached for other networks", func() {
+			allocator.Forget("other-network-id")
+
+			_, err := allocator.AllocateIP("network-id", "other-container-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(configFactory.CreateCallCount()).To(Equal(1))
+			Expect(storeFactory.CreateCallCount()).To(Equal(1))
+		})
+	})
+
 	Describe("ReleaseIP", func() {
 		It("releases the IP from the store", func() {
 			_, err := allocator.AllocateIP("network-id", "container-id")
@@ -0,0 +1,78 @@
+package ipam
+
+const fullWord = ^uint64(0)
+
+// Bitmap tracks which offsets of a Pool are taken. Next is next-fit: the
+// search starts just after the last offset it returned, so a pool that is
+// mostly free hands out addresses in constant time and recently released
+// addresses are not immediately reused.
+type Bitmap struct {
+	words  []uint64
+	size   uint64
+	used   uint64
+	cursor uint64
+}
+
+func NewBitmap(size uint64) *Bitmap {
+	return &Bitmap{
+		words: make([]uint64, (size+63)/64),
+		size:  size,
+	}
+}
+
+// Set marks the offset as taken and reports whether it was free.
+func (b *Bitmap) Set(offset uint64) bool {
+	if offset >= b.size || b.IsSet(offset) {
+		return false
+	}
+
+	b.words[offset/64] |= 1 << (offset % 64)
+	b.used++
+	return true
+}
+
+func (b *Bitmap) Clear(offset uint64) {
+	if offset >= b.size || !b.IsSet(offset) {
+		return
+	}
+
+	b.words[offset/64] &^= 1 << (offset % 64)
+	b.used--
+}
+
+// Used returns the number of offsets that are taken.
+func (b *Bitmap) Used() uint64 {
+	return b.used
+}
+
+func (b *Bitmap) IsSet(offset uint64) bool {
+	return b.words[offset/64]&(1<<(offset%64)) != 0
+}
+
+// Next returns the first free offset at or after the cursor, wrapping around
+// at the end, and false when every offset is taken. It does not mark the
+// offset; call Set once it has been handed out.
+func (b *Bitmap) Next() (uint64, bool) {
+	if b.used >= b.size {
+		return 0, false
+	}
+
+	offset := b.cursor
+	for {
+		if offset >= b.size {
+			offset = 0
+		}
+
+		if offset%64 == 0 && b.words[offset/64] == fullWord {
+			offset += 64
+			continue
+		}
+
+		if !b.IsSet(offset) {
+			b.cursor = offset + 1
+			return offset, true
+		}
+
+		offset++
+	}
+}
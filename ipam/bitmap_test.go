@@ -0,0 +1,91 @@
+package ipam_test
+
+import (
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bitmap", func() {
+	var bitmap *ipam.Bitmap
+
+	BeforeEach(func() {
+		bitmap = ipam.NewBitmap(130)
+	})
+
+	It("sets and clears offsets", func() {
+		Expect(bitmap.Set(3)).To(BeTrue())
+		Expect(bitmap.IsSet(3)).To(BeTrue())
+		Expect(bitmap.Set(3)).To(BeFalse())
+
+		bitmap.Clear(3)
+		Expect(bitmap.IsSet(3)).To(BeFalse())
+		Expect(bitmap.Set(3)).To(BeTrue())
+	})
+
+	It("counts the offsets that are taken", func() {
+		bitmap.Set(3)
+		bitmap.Set(3)
+		bitmap.Set(129)
+		Expect(bitmap.Used()).To(Equal(uint64(2)))
+
+		bitmap.Clear(3)
+		bitmap.Clear(3)
+		Expect(bitmap.Used()).To(Equal(uint64(1)))
+	})
+
+	It("ignores offsets beyond the end", func() {
+		Expect(bitmap.Set(130)).To(BeFalse())
+		bitmap.Clear(130)
+	})
+
+	Describe("Next", func() {
+		It("searches from just after the last offset it returned", func() {
+			offset, ok := bitmap.Next()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(0)))
+			bitmap.Set(offset)
+
+			bitmap.Clear(0)
+
+			offset, ok = bitmap.Next()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(1)))
+		})
+
+		It("skips taken offsets, including whole words", func() {
+			for i := uint64(0); i < 100; i++ {
+				bitmap.Set(i)
+			}
+
+			offset, ok := bitmap.Next()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(100)))
+		})
+
+		It("wraps around to offsets released behind the cursor", func() {
+			for {
+				offset, ok := bitmap.Next()
+				if !ok {
+					break
+				}
+				bitmap.Set(offset)
+			}
+
+			bitmap.Clear(7)
+
+			offset, ok := bitmap.Next()
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(7)))
+		})
+
+		It("reports when every offset is taken", func() {
+			for i := uint64(0); i < 130; i++ {
+				bitmap.Set(i)
+			}
+
+			_, ok := bitmap.Next()
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
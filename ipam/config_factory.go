@@ -1,11 +1,159 @@
 package ipam
 
-import "github.com/appc/cni/pkg/types"
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
 
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+// Config describes the address space a network allocates from. Either
+// family may be nil; a network with both is dual-stack. Addresses covered by
+// Exclusions are never handed out.
+type Config struct {
+	IP4        *types.IPConfig
+	IP6        *types.IPConfig
+	Exclusions []net.IPNet
+}
+
+//go:generate counterfeiter -o ../fakes/network_definitions.go --fake-name NetworkDefinitions . networkDefinitions
+type networkDefinitions interface {
+	Get(id string) (models.Network, error)
+}
+
+// ConfigFactory hands out the configuration in Networks for networks that
+// have one, a configuration built from the subnet of the network's
+// definition in Definitions, which may be nil, for networks defined with a
+// subnet, and the default Config for everything else. Definitions returns
+// NotFound for networks without a definition. Reserved addresses are
+// excluded on every network.
+//
+// A definition's subnet is shared by every host, so each host allocates
+// from it the way it allocates from its local subnet: starting at the same
+// offset into the subnet as the default IPv4 configuration starts into its
+// own, with the gateway just after that start. Hosts therefore never share
+// a gateway, and addresses past the start that a later host also covers are
+// kept apart by the cluster-wide reservation table. Networks defined with a
+// subnet keep the default routes, exclusions and IPv6 configuration.
 type ConfigFactory struct {
-	Config types.IPConfig
+	Config      Config
+	Networks    map[string]Config
+	Definitions networkDefinitions
+	NotFound    error
+	Reserved    []net.IPNet
 }
 
-func (cf *ConfigFactory) Create(networkID string) (types.IPConfig, error) {
-	return cf.Config, nil
+func (cf *ConfigFactory) Create(networkID string) (Config, error) {
+	config, ok := cf.Networks[networkID]
+	if !ok {
+		var err error
+		config, err = cf.definedConfig(networkID)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	var exclusions []net.IPNet
+	exclusions = append(exclusions, cf.Reserved...)
+	exclusions = append(exclusions, config.Exclusions...)
+
+	return Config{
+		IP4:        copyIPConfig(config.IP4),
+		IP6:        copyIPConfig(config.IP6),
+		Exclusions: exclusions,
+	}, nil
+}
+
+// Defined reports whether the network has a configuration of its own in
+// Networks or a definition in Definitions.
+func (cf *ConfigFactory) Defined(networkID string) (bool, error) {
+	if _, ok := cf.Networks[networkID]; ok {
+		return true, nil
+	}
+
+	_, found, err := cf.definition(networkID)
+	return found, err
+}
+
+func (cf *ConfigFactory) definedConfig(networkID string) (Config, error) {
+	definition, found, err := cf.definition(networkID)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if !found || definition.Subnet == "" {
+		return cf.Config, nil
+	}
+
+	_, subnet, err := net.ParseCIDR(definition.Subnet)
+	if err != nil {
+		return Config{}, fmt.Errorf("network definition subnet: %s", err)
+	}
+
+	start, err := cf.hostStart(subnet)
+	if err != nil {
+		return Config{}, fmt.Errorf("network definition subnet: %s", err)
+	}
+
+	ip4 := &types.IPConfig{IP: net.IPNet{IP: start, Mask: subnet.Mask}}
+	if cf.Config.IP4 != nil {
+		ip4.Routes = cf.Config.IP4.Routes
+	}
+
+	return Config{
+		IP4:        ip4,
+		IP6:        cf.Config.IP6,
+		Exclusions: cf.Config.Exclusions,
+	}, nil
+}
+
+// hostStart returns the address of the subnet at the offset the default
+// IPv4 configuration starts at into its own subnet.
+func (cf *ConfigFactory) hostStart(subnet *net.IPNet) (net.IP, error) {
+	base := subnet.IP.To4()
+	if base == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 subnet", subnet)
+	}
+
+	var offset uint32
+	if local := cf.Config.IP4; local != nil && local.IP.IP.To4() != nil {
+		ip := binary.BigEndian.Uint32(local.IP.IP.To4())
+		offset = ip - binary.BigEndian.Uint32(local.IP.IP.Mask(local.IP.Mask).To4())
+	}
+
+	ones, bits := subnet.Mask.Size()
+	if uint64(offset) >= uint64(1)<<uint(bits-ones) {
+		return nil, fmt.Errorf("%s has no room for this host, whose range starts %d addresses in", subnet, offset)
+	}
+
+	start := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(start, binary.BigEndian.Uint32(base)+offset)
+	return start, nil
+}
+
+func (cf *ConfigFactory) definition(networkID string) (models.Network, bool, error) {
+	if cf.Definitions == nil {
+		return models.Network{}, false, nil
+	}
+
+	definition, err := cf.Definitions.Get(networkID)
+	if err != nil && err == cf.NotFound {
+		return models.Network{}, false, nil
+	}
+	if err != nil {
+		return models.Network{}, false, fmt.Errorf("getting network definition: %s", err)
+	}
+
+	return definition, true, nil
+}
+
+func copyIPConfig(config *types.IPConfig) *types.IPConfig {
+	if config == nil {
+		return nil
+	}
+
+	c := *config
+	return &c
 }
@@ -0,0 +1,230 @@
+package ipam_test
+
+import (
+	"errors"
+	"net"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigFactory", func() {
+	var factory *ipam.ConfigFactory
+
+	BeforeEach(func() {
+		factory = &ipam.ConfigFactory{
+			Config: ipam.Config{
+				IP4: &types.IPConfig{
+					IP: net.IPNet{
+						IP:   net.ParseIP("192.168.9.0"),
+						Mask: net.CIDRMask(16, 32),
+					},
+				},
+			},
+			Networks: map[string]ipam.Config{
+				"some-network-id": {
+					IP4: &types.IPConfig{
+						IP: net.IPNet{
+							IP:   net.ParseIP("10.10.9.0"),
+							Mask: net.CIDRMask(24, 32),
+						},
+						Gateway: net.ParseIP("10.10.9.254"),
+					},
+					Exclusions: []net.IPNet{{
+						IP:   net.ParseIP("10.10.9.16"),
+						Mask: net.CIDRMask(28, 32),
+					}},
+				},
+			},
+		}
+	})
+
+	It("returns the network's own configuration when it has one", func() {
+		config, err := factory.Create("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(factory.Networks["some-network-id"]))
+	})
+
+	It("falls back to the default configuration", func() {
+		config, err := factory.Create("some-other-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(factory.Config))
+	})
+
+	It("reports networks in Networks as defined, and others as not", func() {
+		defined, err := factory.Defined("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(defined).To(BeTrue())
+
+		defined, err = factory.Defined("some-other-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(defined).To(BeFalse())
+	})
+
+	It("returns copies that can be modified without affecting other networks", func() {
+		config, err := factory.Create("some-other-network-id")
+		Expect(err).NotTo(HaveOccurred())
+
+		config.IP4.Gateway = net.ParseIP("192.168.9.1")
+		Expect(factory.Config.IP4.Gateway).To(BeNil())
+	})
+
+	Context("when networks are defined in the datastore", func() {
+		var (
+			definitions *fakes.NetworkDefinitions
+			notFound    error
+		)
+
+		BeforeEach(func() {
+			notFound = errors.New("not found")
+			defined := map[string]models.Network{
+				"some-network-id":       {ID: "some-network-id", Subnet: "10.20.0.0/16"},
+				"defined-network-id":    {ID: "defined-network-id", Subnet: "10.30.0.0/16"},
+				"subnetless-network-id": {ID: "subnetless-network-id"},
+			}
+
+			definitions = &fakes.NetworkDefinitions{}
+			definitions.GetStub = func(id string) (models.Network, error) {
+				network, ok := defined[id]
+				if !ok {
+					return models.Network{}, notFound
+				}
+				return network, nil
+			}
+			factory.Definitions = definitions
+			factory.NotFound = notFound
+		})
+
+		It("builds the configuration from the subnet of the definition, starting at this host's offset", func() {
+			config, err := factory.Create("defined-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(ipam.Config{
+				IP4: &types.IPConfig{
+					IP: net.IPNet{
+						IP:   net.ParseIP("10.30.9.0").To4(),
+						Mask: net.CIDRMask(16, 32),
+					},
+				},
+			}))
+
+			Expect(definitions.GetCallCount()).To(Equal(1))
+			Expect(definitions.GetArgsForCall(0)).To(Equal("defined-network-id"))
+		})
+
+		It("keeps the default IPv6 configuration", func() {
+			factory.Config.IP6 = &types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:0:9::"),
+					Mask: net.CIDRMask(64, 128),
+				},
+			}
+
+			config, err := factory.Create("defined-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.IP4.IP.String()).To(Equal("10.30.9.0/16"))
+			Expect(config.IP6).To(Equal(factory.Config.IP6))
+		})
+
+		It("gives every host its own start and gateway", func() {
+			factory.Config.IP4.IP.IP = net.ParseIP("192.168.10.0")
+
+			config, err := factory.Create("defined-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.IP4.IP.IP.String()).To(Equal("10.30.10.0"))
+		})
+
+		It("keeps the default routes and exclusions", func() {
+			route := types.Route{Dst: net.IPNet{IP: net.ParseIP("10.255.0.0").To4(), Mask: net.CIDRMask(16, 32)}}
+			exclusion := net.IPNet{IP: net.ParseIP("10.30.9.16").To4(), Mask: net.CIDRMask(28, 32)}
+			factory.Config.IP4.Routes = []types.Route{route}
+			factory.Config.Exclusions = []net.IPNet{exclusion}
+
+			config, err := factory.Create("defined-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.IP4.Routes).To(Equal([]types.Route{route}))
+			Expect(config.Exclusions).To(Equal([]net.IPNet{exclusion}))
+		})
+
+		Context("when the subnet has no room for this host", func() {
+			BeforeEach(func() {
+				definitions.GetStub = nil
+				definitions.GetReturns(models.Network{ID: "defined-network-id", Subnet: "10.30.0.0/24"}, nil)
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := factory.Create("defined-network-id")
+				Expect(err).To(MatchError("network definition subnet: 10.30.0.0/24 has no room for this host, whose range starts 2304 addresses in"))
+			})
+		})
+
+		It("falls back to the default configuration for networks without a definition", func() {
+			config, err := factory.Create("some-other-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(factory.Config))
+		})
+
+		It("prefers the configuration in Networks", func() {
+			config, err := factory.Create("some-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(factory.Networks["some-network-id"]))
+		})
+
+		It("falls back to the default configuration when the definition has no subnet", func() {
+			config, err := factory.Create("subnetless-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(factory.Config))
+		})
+
+		It("reports defined networks, with or without a subnet, as defined", func() {
+			for _, id := range []string{"defined-network-id", "subnetless-network-id"} {
+				defined, err := factory.Defined(id)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(defined).To(BeTrue())
+			}
+		})
+
+		It("reports networks without a definition as undefined", func() {
+			defined, err := factory.Defined("some-other-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(defined).To(BeFalse())
+		})
+
+		Context("when getting the definition fails", func() {
+			BeforeEach(func() {
+				definitions.GetStub = nil
+				definitions.GetReturns(models.Network{}, errors.New("leek"))
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := factory.Create("defined-network-id")
+				Expect(err).To(MatchError("getting network definition: leek"))
+
+				_, err = factory.Defined("defined-network-id")
+				Expect(err).To(MatchError("getting network definition: leek"))
+			})
+		})
+	})
+
+	Context("when there are reserved addresses", func() {
+		var reserved net.IPNet
+
+		BeforeEach(func() {
+			reserved = net.IPNet{IP: net.ParseIP("10.10.9.53").To4(), Mask: net.CIDRMask(32, 32)}
+			factory.Reserved = []net.IPNet{reserved}
+		})
+
+		It("excludes them on every network", func() {
+			config, err := factory.Create("some-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Exclusions).To(Equal(append([]net.IPNet{reserved}, factory.Networks["some-network-id"].Exclusions...)))
+
+			config, err = factory.Create("some-other-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Exclusions).To(Equal([]net.IPNet{reserved}))
+		})
+	})
+})
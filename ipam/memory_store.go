@@ -5,15 +5,27 @@ import (
 	"sync"
 )
 
+type poolBitmap struct {
+	pool   *Pool
+	bitmap *Bitmap
+}
+
+// inMemoryStore keeps a bitmap per pool for allocation alongside an index of
+// the addresses held by each id, so that allocation is next-fit and release
+// and lookup by id do not scan every reservation.
 type inMemoryStore struct {
-	allocated map[string]string
-	locker    sync.Locker
+	locker  sync.Locker
+	owners  map[string]string
+	byID    map[string][]net.IP
+	bitmaps map[string]*poolBitmap
 }
 
 func NewStore(locker sync.Locker) *inMemoryStore {
 	return &inMemoryStore{
-		allocated: map[string]string{},
-		locker:    locker,
+		locker:  locker,
+		owners:  map[string]string{},
+		byID:    map[string][]net.IP{},
+		bitmaps: map[string]*poolBitmap{},
 	}
 }
 
@@ -21,34 +33,102 @@ func (s *inMemoryStore) Reserve(id string, ip net.IP) (bool, error) {
 	s.locker.Lock()
 	defer s.locker.Unlock()
 
-	key := ip.String()
-	_, ok := s.allocated[key]
-	if ok {
+	if _, ok := s.owners[ip.String()]; ok {
 		return false, nil
 	}
 
-	s.allocated[key] = id
+	s.record(id, ip)
+	for _, pb := range s.bitmaps {
+		if offset, ok := pb.pool.Offset(ip); ok {
+			pb.bitmap.Set(offset)
+		}
+	}
+
 	return true, nil
 }
 
+func (s *inMemoryStore) ReserveNext(id string, pool *Pool) (net.IP, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	pb := s.bitmapFor(pool)
+	for {
+		offset, ok := pb.bitmap.Next()
+		if !ok {
+			return nil, NoMoreAddressesError
+		}
+		pb.bitmap.Set(offset)
+
+		ip := pool.IP(offset)
+		if _, taken := s.owners[ip.String()]; taken {
+			continue
+		}
+
+		s.record(id, ip)
+		return ip, nil
+	}
+}
+
 func (s *inMemoryStore) ReleaseByID(id string) error {
 	s.locker.Lock()
 	defer s.locker.Unlock()
 
-	for k, v := range s.allocated {
-		if v == id {
-			delete(s.allocated, k)
+	for _, ip := range s.byID[id] {
+		delete(s.owners, ip.String())
+		for _, pb := range s.bitmaps {
+			if offset, ok := pb.pool.Offset(ip); ok {
+				pb.bitmap.Clear(offset)
+			}
 		}
 	}
+	delete(s.byID, id)
 
 	return nil
 }
 
-func (s *inMemoryStore) Contains(id string) bool {
-	for _, idInStore := range s.allocated {
-		if idInStore == id {
-			return true
+func (s *inMemoryStore) Contains(id string) (bool, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	_, ok := s.byID[id]
+	return ok, nil
+}
+
+func (s *inMemoryStore) Reservations() ([]Reservation, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	reservations := []Reservation{}
+	for id, ips := range s.byID {
+		for _, ip := range ips {
+			reservations = append(reservations, Reservation{ContainerID: id, IP: ip})
+		}
+	}
+
+	return reservations, nil
+}
+
+func (s *inMemoryStore) record(id string, ip net.IP) {
+	s.owners[ip.String()] = id
+	s.byID[id] = append(s.byID[id], ip)
+}
+
+// bitmapFor returns the bitmap for the pool, building it from the existing
+// reservations the first time the pool is seen.
+func (s *inMemoryStore) bitmapFor(pool *Pool) *poolBitmap {
+	if pb, ok := s.bitmaps[pool.Key()]; ok {
+		return pb
+	}
+
+	pb := &poolBitmap{pool: pool, bitmap: pool.NewBitmap()}
+	for _, ips := range s.byID {
+		for _, ip := range ips {
+			if offset, ok := pool.Offset(ip); ok {
+				pb.bitmap.Set(offset)
+			}
 		}
 	}
-	return false
+	s.bitmaps[pool.Key()] = pb
+
+	return pb
 }
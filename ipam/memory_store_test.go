@@ -1,8 +1,10 @@
 package ipam_test
 
 import (
+	"fmt"
 	"net"
 
+	"github.com/appc/cni/pkg/types"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
 
@@ -90,14 +92,119 @@ var _ = Describe("MemoryStore", func() {
 		})
 	})
 
+	Describe("ReserveNext", func() {
+		var pool *ipam.Pool
+
+		BeforeEach(func() {
+			pool = ipam.NewPool(types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("10.0.0.0"),
+					Mask: net.CIDRMask(29, 32),
+				},
+				Gateway: net.ParseIP("10.0.0.1"),
+			})
+		})
+
+		It("reserves free addresses from the pool in turn, skipping the gateway", func() {
+			ip, err := store.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("10.0.0.2"))
+
+			ip, err = store.ReserveNext("some-other-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("10.0.0.3"))
+
+			contains, err := store.Contains("some-other-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contains).To(BeTrue())
+		})
+
+		It("skips addresses that were reserved directly", func() {
+			ok, err := store.Reserve("some-id", net.ParseIP("10.0.0.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ip, err := store.ReserveNext("some-other-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("10.0.0.3"))
+
+			ok, err = store.Reserve("some-third-id", net.ParseIP("10.0.0.4"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ip, err = store.ReserveNext("some-fourth-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("10.0.0.5"))
+		})
+
+		It("returns a NoMoreAddressesError when the pool is exhausted", func() {
+			for i := 0; i < 5; i++ {
+				_, err := store.ReserveNext(fmt.Sprintf("id-%d", i), pool)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err := store.ReserveNext("one-too-many", pool)
+			Expect(err).To(Equal(ipam.NoMoreAddressesError))
+		})
+
+		It("hands out released addresses again", func() {
+			for i := 0; i < 5; i++ {
+				_, err := store.ReserveNext(fmt.Sprintf("id-%d", i), pool)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(store.ReleaseByID("id-2")).To(Succeed())
+
+			ip, err := store.ReserveNext("some-new-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("10.0.0.4"))
+		})
+
+		It("locks the data store while allocating", func() {
+			store.ReserveNext("some-id", pool)
+			Expect(locker.LockCallCount()).To(Equal(1))
+			Expect(locker.UnlockCallCount()).To(Equal(1))
+		})
+	})
+
 	Describe("Contains", func() {
 		It("tests whether a given container id has been allocated an ip", func() {
 			ok, err := store.Reserve("some-id", net.ParseIP("1.2.3.4"))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ok).To(BeTrue())
 
-			Expect(store.Contains("some-id")).To(BeTrue())
-			Expect(store.Contains("some-other-id")).To(BeFalse())
+			contains, err := store.Contains("some-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contains).To(BeTrue())
+
+			contains, err = store.Contains("some-other-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contains).To(BeFalse())
+		})
+
+		It("locks the data store while searching", func() {
+			store.Contains("some-id")
+			Expect(locker.LockCallCount()).To(Equal(1))
+			Expect(locker.UnlockCallCount()).To(Equal(1))
+		})
+	})
+
+	Describe("Reservations", func() {
+		It("lists every address with its owner", func() {
+			ok, err := store.Reserve("some-id", net.ParseIP("1.2.3.4"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = store.Reserve("some-id", net.ParseIP("fd00::4"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			reservations, err := store.Reservations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reservations).To(ConsistOf(
+				ipam.Reservation{ContainerID: "some-id", IP: net.ParseIP("1.2.3.4")},
+				ipam.Reservation{ContainerID: "some-id", IP: net.ParseIP("fd00::4")},
+			))
 		})
 	})
 })
@@ -0,0 +1,217 @@
+package ipam
+
+import (
+	"math"
+	"math/big"
+	"net"
+
+	"github.com/appc/cni/pkg/types"
+)
+
+// maxPoolSize bounds the bitmap kept for an IPv6 pool. IPv6 subnets are far
+// larger than any host will ever fill, so only the first maxPoolSize
+// addresses after the start are handed out. IPv4 pools are never truncated.
+const maxPoolSize = 1 << 20
+
+// Pool is the range of addresses a network configuration allocates from:
+// everything after the configured address up to the end of the subnet,
+// minus the gateway, the IPv4 broadcast address and any exclusions.
+// Addresses are identified by their offset from the first candidate so that
+// reservations can be tracked in a Bitmap.
+type Pool struct {
+	key        string
+	first      net.IP
+	size       uint64
+	subnetSize uint64
+	reserved   []uint64
+	exclusions []net.IPNet
+}
+
+func NewPool(config types.IPConfig, exclusions ...net.IPNet) *Pool {
+	pool := &Pool{
+		key:        config.IP.String(),
+		first:      nextIP(config.IP.IP),
+		exclusions: exclusions,
+	}
+
+	if !config.IP.Contains(pool.first) {
+		return pool
+	}
+
+	last := lastIP(config.IP)
+	size := big.NewInt(0).Sub(toInt(last), toInt(pool.first))
+	size.Add(size, big.NewInt(1))
+
+	pool.subnetSize = math.MaxUint64
+	if size.BitLen() <= 64 {
+		pool.subnetSize = size.Uint64()
+	}
+
+	if len(pool.first) == net.IPv6len && size.Cmp(big.NewInt(maxPoolSize)) > 0 {
+		size.SetInt64(maxPoolSize)
+	}
+	pool.size = size.Uint64()
+
+	if config.Gateway != nil {
+		pool.reserve(config.Gateway)
+	}
+
+	if ones, bits := config.IP.Mask.Size(); len(pool.first) == net.IPv4len && bits-ones > 1 {
+		pool.reserve(last)
+	}
+
+	return pool
+}
+
+func (p *Pool) reserve(ip net.IP) {
+	if offset, ok := p.Offset(ip); ok {
+		p.reserved = append(p.reserved, offset)
+	}
+}
+
+// Key identifies the pool; configurations for the same subnet and start
+// address share a key.
+func (p *Pool) Key() string {
+	return p.key
+}
+
+func (p *Pool) Size() uint64 {
+	return p.size
+}
+
+// SubnetSize counts every address from the first candidate to the end of
+// the subnet. It only differs from Size for IPv6 pools, and subnets with more
+// addresses than a uint64 can count report the largest uint64.
+func (p *Pool) SubnetSize() uint64 {
+	return p.subnetSize
+}
+
+// Offset returns the position of ip within the pool, and false when the
+// address is not part of it.
+func (p *Pool) Offset(ip net.IP) (uint64, bool) {
+	ip = sameFamily(ip, p.first)
+	if ip == nil {
+		return 0, false
+	}
+
+	offset := big.NewInt(0).Sub(toInt(ip), toInt(p.first))
+	if offset.Sign() < 0 || offset.Cmp(big.NewInt(0).SetUint64(p.size)) >= 0 {
+		return 0, false
+	}
+
+	return offset.Uint64(), true
+}
+
+// IP returns the address at offset within the pool.
+func (p *Pool) IP(offset uint64) net.IP {
+	ip := make(net.IP, len(p.first))
+	copy(ip, p.first)
+
+	carry := offset
+	for i := len(ip) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(ip[i]) + carry&0xff
+		ip[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+
+	return ip
+}
+
+// IsReserved reports whether the address at offset is the gateway, the
+// broadcast address or is excluded, and so can never be handed out.
+func (p *Pool) IsReserved(offset uint64) bool {
+	for _, reserved := range p.reserved {
+		if offset == reserved {
+			return true
+		}
+	}
+
+	for _, exclusion := range p.exclusions {
+		first, last, ok := p.overlap(exclusion)
+		if ok && first <= offset && offset <= last {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewBitmap returns an empty bitmap sized for the pool with the reserved
+// addresses and exclusions already marked as taken.
+func (p *Pool) NewBitmap() *Bitmap {
+	bitmap := NewBitmap(p.size)
+	for _, reserved := range p.reserved {
+		bitmap.Set(reserved)
+	}
+
+	for _, exclusion := range p.exclusions {
+		first, last, ok := p.overlap(exclusion)
+		if !ok {
+			continue
+		}
+
+		for offset := first; offset <= last; offset++ {
+			bitmap.Set(offset)
+		}
+	}
+
+	return bitmap
+}
+
+// overlap returns the range of offsets the network shares with the pool.
+func (p *Pool) overlap(network net.IPNet) (uint64, uint64, bool) {
+	start := sameFamily(network.IP.Mask(network.Mask), p.first)
+	if start == nil || p.size == 0 {
+		return 0, 0, false
+	}
+
+	poolFirst := toInt(p.first)
+	poolLast := big.NewInt(0).Add(poolFirst, big.NewInt(0).SetUint64(p.size-1))
+
+	first := toInt(start)
+	if first.Cmp(poolFirst) < 0 {
+		first = poolFirst
+	}
+
+	last := toInt(sameFamily(lastIP(network), p.first))
+	if last.Cmp(poolLast) > 0 {
+		last = poolLast
+	}
+
+	if first.Cmp(last) > 0 {
+		return 0, 0, false
+	}
+
+	firstOffset := big.NewInt(0).Sub(first, poolFirst)
+	lastOffset := big.NewInt(0).Sub(last, poolFirst)
+	return firstOffset.Uint64(), lastOffset.Uint64(), true
+}
+
+func lastIP(subnet net.IPNet) net.IP {
+	ip, mask := subnet.IP.To4(), subnet.Mask
+	if ip == nil {
+		ip = subnet.IP.To16()
+	} else if len(mask) == net.IPv6len {
+		mask = mask[12:]
+	}
+
+	last := make(net.IP, len(ip))
+	for i := range ip {
+		last[i] = ip[i] | ^mask[i]
+	}
+	return last
+}
+
+func sameFamily(ip, like net.IP) net.IP {
+	if len(like) == net.IPv4len {
+		return ip.To4()
+	}
+	if ip.To4() != nil {
+		return nil
+	}
+	return ip.To16()
+}
+
+func toInt(ip net.IP) *big.Int {
+	return big.NewInt(0).SetBytes(ip)
+}
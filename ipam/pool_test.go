@@ -0,0 +1,189 @@
+package ipam_test
+
+import (
+	"math"
+	"net"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pool", func() {
+	var config types.IPConfig
+
+	BeforeEach(func() {
+		config = types.IPConfig{
+			IP: net.IPNet{
+				IP:   net.ParseIP("192.168.2.0"),
+				Mask: net.CIDRMask(24, 32),
+			},
+			Gateway: net.ParseIP("192.168.2.1"),
+		}
+	})
+
+	It("covers the addresses after the configured one up to the end of the subnet", func() {
+		pool := ipam.NewPool(config)
+
+		Expect(pool.Key()).To(Equal("192.168.2.0/24"))
+		Expect(pool.Size()).To(Equal(uint64(255)))
+		Expect(pool.IP(0).String()).To(Equal("192.168.2.1"))
+		Expect(pool.IP(254).String()).To(Equal("192.168.2.255"))
+	})
+
+	It("maps addresses to offsets", func() {
+		pool := ipam.NewPool(config)
+
+		offset, ok := pool.Offset(net.ParseIP("192.168.2.10"))
+		Expect(ok).To(BeTrue())
+		Expect(offset).To(Equal(uint64(9)))
+
+		_, ok = pool.Offset(net.ParseIP("192.168.2.0"))
+		Expect(ok).To(BeFalse())
+
+		_, ok = pool.Offset(net.ParseIP("192.168.3.1"))
+		Expect(ok).To(BeFalse())
+
+		_, ok = pool.Offset(net.ParseIP("fd00::1"))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("starts new bitmaps with the gateway and broadcast address taken", func() {
+		pool := ipam.NewPool(config)
+		bitmap := pool.NewBitmap()
+
+		Expect(bitmap.IsSet(0)).To(BeTrue())
+		Expect(bitmap.IsSet(1)).To(BeFalse())
+		Expect(bitmap.IsSet(253)).To(BeFalse())
+		Expect(bitmap.IsSet(254)).To(BeTrue())
+
+		Expect(pool.IsReserved(0)).To(BeTrue())
+		Expect(pool.IsReserved(254)).To(BeTrue())
+		Expect(pool.IsReserved(1)).To(BeFalse())
+	})
+
+	Context("when the IPv4 subnet has no broadcast address", func() {
+		BeforeEach(func() {
+			config.IP.Mask = net.CIDRMask(31, 32)
+			config.Gateway = nil
+		})
+
+		It("leaves the last address free", func() {
+			pool := ipam.NewPool(config)
+			Expect(pool.Size()).To(Equal(uint64(1)))
+			Expect(pool.NewBitmap().IsSet(0)).To(BeFalse())
+		})
+	})
+
+	Context("when there are exclusions", func() {
+		It("starts new bitmaps with the excluded addresses taken", func() {
+			pool := ipam.NewPool(config,
+				net.IPNet{IP: net.ParseIP("192.168.2.5"), Mask: net.CIDRMask(32, 32)},
+				net.IPNet{IP: net.ParseIP("192.168.2.16"), Mask: net.CIDRMask(28, 32)},
+				net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)},
+			)
+			bitmap := pool.NewBitmap()
+
+			var free []string
+			for {
+				offset, ok := bitmap.Next()
+				if !ok {
+					break
+				}
+				bitmap.Set(offset)
+				free = append(free, pool.IP(offset).String())
+			}
+
+			Expect(free).To(HaveLen(255 - 1 - 1 - 1 - 16))
+			Expect(free).NotTo(ContainElement("192.168.2.5"))
+			Expect(free).NotTo(ContainElement("192.168.2.16"))
+			Expect(free).NotTo(ContainElement("192.168.2.31"))
+			Expect(free).To(ContainElement("192.168.2.32"))
+		})
+
+		It("clips exclusions that are larger than the pool", func() {
+			pool := ipam.NewPool(config, net.IPNet{IP: net.ParseIP("192.168.0.0"), Mask: net.CIDRMask(16, 32)})
+
+			_, ok := pool.NewBitmap().Next()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("when the subnet is IPv6", func() {
+		BeforeEach(func() {
+			config = types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:0:9::"),
+					Mask: net.CIDRMask(64, 128),
+				},
+			}
+		})
+
+		It("bounds the size of the pool", func() {
+			pool := ipam.NewPool(config)
+
+			Expect(pool.Size()).To(Equal(uint64(1 << 20)))
+			Expect(pool.SubnetSize()).To(Equal(uint64(1<<64 - 1)))
+			Expect(pool.IP(0x10000)).To(Equal(net.ParseIP("fd00:0:0:9::1:1")))
+
+			offset, ok := pool.Offset(net.ParseIP("fd00:0:0:9::1:1"))
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(0x10000)))
+		})
+	})
+
+	Context("when the IPv6 subnet is larger than a uint64 can count", func() {
+		BeforeEach(func() {
+			config = types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("fd00:0:9::"),
+					Mask: net.CIDRMask(48, 128),
+				},
+			}
+		})
+
+		It("reports the largest uint64 as the size of the subnet", func() {
+			pool := ipam.NewPool(config)
+
+			Expect(pool.Size()).To(Equal(uint64(1 << 20)))
+			Expect(pool.SubnetSize()).To(Equal(uint64(math.MaxUint64)))
+		})
+	})
+
+	Context("when the subnet is a large IPv4 subnet", func() {
+		BeforeEach(func() {
+			config = types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("10.0.0.0"),
+					Mask: net.CIDRMask(8, 32),
+				},
+			}
+		})
+
+		It("does not bound the size of the pool", func() {
+			pool := ipam.NewPool(config)
+
+			Expect(pool.Size()).To(Equal(uint64(1<<24 - 2)))
+			Expect(pool.SubnetSize()).To(Equal(pool.Size()))
+
+			offset, ok := pool.Offset(net.ParseIP("10.255.255.254"))
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(uint64(1<<24 - 3)))
+		})
+	})
+
+	Context("when the configured address is the last in the subnet", func() {
+		BeforeEach(func() {
+			config.IP.IP = net.ParseIP("192.168.2.255")
+		})
+
+		It("is empty", func() {
+			pool := ipam.NewPool(config)
+
+			Expect(pool.Size()).To(BeZero())
+			_, ok := pool.NewBitmap().Next()
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
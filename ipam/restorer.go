@@ -0,0 +1,206 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+)
+
+type containerLister interface {
+	All() ([]models.Container, error)
+}
+
+//go:generate counterfeiter -o ../fakes/orphan_pruner.go --fake-name OrphanPruner . orphanPruner
+type orphanPruner interface {
+	PruneOrphans(hostIP string, renewedBefore time.Time) (int64, error)
+	QuarantineOrphans(hostIP string, renewedBefore, quarantinedUntil time.Time) (int64, error)
+}
+
+// ReservationConflict is an address that a container record on this host
+// claims but that is held by another container.
+type ReservationConflict struct {
+	NetworkID   string
+	IP          string
+	ContainerID string
+	HeldBy      string
+}
+
+// ConflictError is returned by Restore when it finished reconciling but some
+// addresses could not be restored because another container holds them.
+type ConflictError struct {
+	Conflicts []ReservationConflict
+}
+
+func (e ConflictError) Error() string {
+	descriptions := []string{}
+	for _, c := range e.Conflicts {
+		descriptions = append(descriptions, fmt.Sprintf("%s on network %q claimed by %q but held by %q", c.IP, c.NetworkID, c.ContainerID, c.HeldBy))
+	}
+
+	return fmt.Sprintf("ip reservation conflicts: %s", strings.Join(descriptions, ", "))
+}
+
+// Restorer rebuilds the allocator's reservations from the container records
+// that belong to this host, so that addresses in use are never handed out
+// again after a restart. Before that it prunes the reservations this host
+// made whose container has no record, once they have gone unrenewed for
+// GracePeriod. With a Quarantine they are quarantined rather than deleted,
+// as addresses released by a DEL are.
+type Restorer struct {
+	Logger       lager.Logger
+	Clock        clock.Clock
+	Datastore    containerLister
+	StoreFactory storeFactory
+	Orphans      orphanPruner
+	GracePeriod  time.Duration
+	Quarantine   time.Duration
+	HostIP       net.IP
+}
+
+func (r *Restorer) Restore() error {
+	logger := r.Logger.Session("restore-reservations", lager.Data{"host_ip": r.HostIP.String()})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	r.pruneOrphans(logger)
+
+	containers, err := r.Datastore.All()
+	if err != nil {
+		return fmt.Errorf("listing containers: %s", err)
+	}
+
+	owners := map[string]map[string]string{}
+	var conflicts []ReservationConflict
+
+	for _, container := range containers {
+		if container.HostIP != r.HostIP.String() {
+			continue
+		}
+
+		ips, err := addresses(container)
+		if err != nil {
+			logger.Error("parse-ip-failed", err, lager.Data{"container": container})
+			continue
+		}
+
+		store, err := r.StoreFactory.Create(container.NetworkID)
+		if err != nil {
+			return fmt.Errorf("failed to create allocator store: %s", err)
+		}
+
+		for _, ip := range ips {
+			reserved, err := store.Reserve(container.ID, ip)
+			if err != nil {
+				return fmt.Errorf("failed to reserve IP: %s", err)
+			}
+
+			if reserved {
+				logger.Info("reservation-restored", lager.Data{"container": container, "ip": ip.String()})
+				continue
+			}
+
+			heldBy, err := holder(store, owners, container, ip)
+			if err != nil {
+				return err
+			}
+
+			if heldBy == container.ID {
+				continue
+			}
+
+			conflict := ReservationConflict{
+				NetworkID:   container.NetworkID,
+				IP:          ip.String(),
+				ContainerID: container.ID,
+				HeldBy:      heldBy,
+			}
+			logger.Error("reservation-conflict", fmt.Errorf("ip %s reserved by another container", ip), lager.Data{"conflict": conflict})
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return ConflictError{Conflicts: conflicts}
+	}
+
+	return nil
+}
+
+func (r *Restorer) pruneOrphans(logger lager.Logger) {
+	now := r.Clock.Now()
+	renewedBefore := now.Add(-r.GracePeriod)
+
+	if r.Quarantine > 0 {
+		quarantined, err := r.Orphans.QuarantineOrphans(r.HostIP.String(), renewedBefore, now.Add(r.Quarantine))
+		if err != nil {
+			logger.Error("quarantine-orphans-failed", err)
+		} else if quarantined > 0 {
+			logger.Info("quarantined-orphans", lager.Data{"count": quarantined})
+		}
+		return
+	}
+
+	pruned, err := r.Orphans.PruneOrphans(r.HostIP.String(), renewedBefore)
+	if err != nil {
+		logger.Error("prune-orphans-failed", err)
+	} else if pruned > 0 {
+		logger.Info("pruned-orphans", lager.Data{"count": pruned})
+	}
+}
+
+// addresses returns every address recorded for the container: its IPv4
+// address and, when it has one, its IPv6 address.
+func addresses(container models.Container) ([]net.IP, error) {
+	ip := net.ParseIP(container.IP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %q", container.IP)
+	}
+
+	ips := []net.IP{ip}
+	if container.IP6 != "" {
+		ip6 := net.ParseIP(container.IP6)
+		if ip6 == nil {
+			return nil, fmt.Errorf("invalid ip6 %q", container.IP6)
+		}
+		ips = append(ips, ip6)
+	}
+
+	return ips, nil
+}
+
+// holder returns the container that holds the address. The owners of each
+// network are listed once and listed again whenever they do not show the
+// address held by the container that claims it, so that a conflict reports
+// who holds the address now rather than when the owners were first listed.
+func holder(store AllocatorStore, owners map[string]map[string]string, container models.Container, ip net.IP) (string, error) {
+	if owners[container.NetworkID][ip.String()] == container.ID {
+		return container.ID, nil
+	}
+
+	var err error
+	owners[container.NetworkID], err = reservationOwners(store)
+	if err != nil {
+		return "", err
+	}
+
+	return owners[container.NetworkID][ip.String()], nil
+}
+
+func reservationOwners(store AllocatorStore) (map[string]string, error) {
+	reservations, err := store.Reservations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %s", err)
+	}
+
+	owners := map[string]string{}
+	for _, r := range reservations {
+		owners[r.IP.String()] = r.ContainerID
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,311 @@
+package ipam_test
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Restorer", func() {
+	var (
+		logger       *lagertest.TestLogger
+		datastore    *fakes.Store
+		store        *fakes.AllocatorStore
+		storeFactory *fakes.StoreFactory
+		orphans      *fakes.OrphanPruner
+		fakeClock    *fakeclock.FakeClock
+		restorer     *ipam.Restorer
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		datastore = &fakes.Store{}
+		store = &fakes.AllocatorStore{}
+		storeFactory = &fakes.StoreFactory{}
+		storeFactory.CreateReturns(store, nil)
+
+		datastore.AllReturns([]models.Container{
+			{ID: "container-1", IP: "192.168.1.2", NetworkID: "network-1", HostIP: "10.0.0.1"},
+			{ID: "container-2", IP: "192.168.2.2", NetworkID: "network-2", HostIP: "10.0.0.2"},
+			{ID: "container-3", IP: "192.168.1.3", NetworkID: "network-3", HostIP: "10.0.0.1"},
+		}, nil)
+		store.ReserveReturns(true, nil)
+
+		orphans = &fakes.OrphanPruner{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		restorer = &ipam.Restorer{
+			Logger:       logger,
+			Clock:        fakeClock,
+			Datastore:    datastore,
+			StoreFactory: storeFactory,
+			Orphans:      orphans,
+			GracePeriod:  5 * time.Minute,
+			HostIP:       net.ParseIP("10.0.0.1"),
+		}
+	})
+
+	It("prunes orphaned reservations that have not been renewed within the grace period", func() {
+		orphans.PruneOrphansReturns(3, nil)
+
+		Expect(restorer.Restore()).To(Succeed())
+
+		Expect(orphans.PruneOrphansCallCount()).To(Equal(1))
+		hostIP, renewedBefore := orphans.PruneOrphansArgsForCall(0)
+		Expect(hostIP).To(Equal("10.0.0.1"))
+		Expect(renewedBefore).To(Equal(fakeClock.Now().Add(-5 * time.Minute)))
+		Expect(logger).To(gbytes.Say("pruned-orphans.*3"))
+
+		Expect(orphans.QuarantineOrphansCallCount()).To(Equal(0))
+	})
+
+	Context("when released addresses are quarantined", func() {
+		BeforeEach(func() {
+			restorer.Quarantine = time.Minute
+			orphans.QuarantineOrphansReturns(2, nil)
+		})
+
+		It("quarantines the orphaned reservations instead of deleting them", func() {
+			Expect(restorer.Restore()).To(Succeed())
+
+			Expect(orphans.PruneOrphansCallCount()).To(Equal(0))
+			Expect(orphans.QuarantineOrphansCallCount()).To(Equal(1))
+			hostIP, renewedBefore, quarantinedUntil := orphans.QuarantineOrphansArgsForCall(0)
+			Expect(hostIP).To(Equal("10.0.0.1"))
+			Expect(renewedBefore).To(Equal(fakeClock.Now().Add(-5 * time.Minute)))
+			Expect(quarantinedUntil).To(Equal(fakeClock.Now().Add(time.Minute)))
+			Expect(logger).To(gbytes.Say("quarantined-orphans.*2"))
+		})
+
+		Context("when quarantining fails", func() {
+			BeforeEach(func() {
+				orphans.QuarantineOrphansReturns(0, errors.New("pear"))
+			})
+
+			It("logs the error and restores the reservations anyway", func() {
+				Expect(restorer.Restore()).To(Succeed())
+
+				Expect(logger).To(gbytes.Say("quarantine-orphans-failed.*pear"))
+				Expect(store.ReserveCallCount()).To(Equal(2))
+			})
+		})
+	})
+
+	Context("when pruning fails", func() {
+		BeforeEach(func() {
+			orphans.PruneOrphansReturns(0, errors.New("pear"))
+		})
+
+		It("logs the error and restores the reservations anyway", func() {
+			Expect(restorer.Restore()).To(Succeed())
+
+			Expect(logger).To(gbytes.Say("prune-orphans-failed.*pear"))
+			Expect(store.ReserveCallCount()).To(Equal(2))
+		})
+	})
+
+	It("reserves the address of every container on this host", func() {
+		Expect(restorer.Restore()).To(Succeed())
+
+		Expect(storeFactory.CreateCallCount()).To(Equal(2))
+		Expect(storeFactory.CreateArgsForCall(0)).To(Equal("network-1"))
+		Expect(storeFactory.CreateArgsForCall(1)).To(Equal("network-3"))
+
+		Expect(store.ReserveCallCount()).To(Equal(2))
+		id, ip := store.ReserveArgsForCall(0)
+		Expect(id).To(Equal("container-1"))
+		Expect(ip.String()).To(Equal("192.168.1.2"))
+
+		id, ip = store.ReserveArgsForCall(1)
+		Expect(id).To(Equal("container-3"))
+		Expect(ip.String()).To(Equal("192.168.1.3"))
+
+		Expect(logger).To(gbytes.Say("reservation-restored.*container-1"))
+	})
+
+	Context("when the container record carries an IPv6 address", func() {
+		BeforeEach(func() {
+			datastore.AllReturns([]models.Container{{
+				ID:        "container-1",
+				IP:        "192.168.1.2",
+				NetworkID: "network-1",
+				HostIP:    "10.0.0.1",
+				IP6:       "fd00::2",
+			}}, nil)
+		})
+
+		It("reserves both addresses", func() {
+			Expect(restorer.Restore()).To(Succeed())
+
+			Expect(store.ReserveCallCount()).To(Equal(2))
+			_, ip := store.ReserveArgsForCall(1)
+			Expect(ip.String()).To(Equal("fd00::2"))
+		})
+	})
+
+	Context("when the reservation already exists", func() {
+		BeforeEach(func() {
+			store.ReserveReturns(false, nil)
+			store.ReservationsReturns([]ipam.Reservation{
+				{ContainerID: "container-1", IP: net.ParseIP("192.168.1.2")},
+				{ContainerID: "container-3", IP: net.ParseIP("192.168.1.3")},
+			}, nil)
+		})
+
+		It("leaves it alone", func() {
+			Expect(restorer.Restore()).To(Succeed())
+			Expect(logger).NotTo(gbytes.Say("reservation-conflict"))
+		})
+
+		It("lists the reservations of each network once", func() {
+			Expect(restorer.Restore()).To(Succeed())
+			Expect(store.ReservationsCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the address is reserved by a different container", func() {
+		BeforeEach(func() {
+			store.ReserveStub = func(id string, ip net.IP) (bool, error) {
+				return id != "container-1", nil
+			}
+			store.ReservationsReturns([]ipam.Reservation{
+				{ContainerID: "container-9", IP: net.ParseIP("192.168.1.2")},
+			}, nil)
+		})
+
+		It("restores everything else and reports the conflict", func() {
+			err := restorer.Restore()
+			Expect(err).To(Equal(ipam.ConflictError{
+				Conflicts: []ipam.ReservationConflict{{
+					NetworkID:   "network-1",
+					IP:          "192.168.1.2",
+					ContainerID: "container-1",
+					HeldBy:      "container-9",
+				}},
+			}))
+			Expect(err).To(MatchError(`ip reservation conflicts: 192.168.1.2 on network "network-1" claimed by "container-1" but held by "container-9"`))
+
+			Expect(store.ReserveCallCount()).To(Equal(2))
+			Expect(logger).To(gbytes.Say("reservation-conflict.*192.168.1.2"))
+		})
+	})
+
+	Context("when conflicting addresses are reserved after the owners were listed", func() {
+		BeforeEach(func() {
+			store.ReserveReturns(false, nil)
+			datastore.AllReturns([]models.Container{
+				{ID: "container-1", IP: "192.168.1.2", NetworkID: "network-1", HostIP: "10.0.0.1"},
+				{ID: "container-3", IP: "192.168.1.3", NetworkID: "network-1", HostIP: "10.0.0.1"},
+			}, nil)
+			listings := [][]ipam.Reservation{{
+				{ContainerID: "container-8", IP: net.ParseIP("192.168.1.2")},
+			}, {
+				{ContainerID: "container-8", IP: net.ParseIP("192.168.1.2")},
+				{ContainerID: "container-9", IP: net.ParseIP("192.168.1.3")},
+			}}
+			store.ReservationsStub = func() ([]ipam.Reservation, error) {
+				listing := listings[0]
+				if len(listings) > 1 {
+					listings = listings[1:]
+				}
+				return listing, nil
+			}
+		})
+
+		It("lists the owners again to report who holds them", func() {
+			err := restorer.Restore()
+			Expect(err).To(Equal(ipam.ConflictError{
+				Conflicts: []ipam.ReservationConflict{{
+					NetworkID:   "network-1",
+					IP:          "192.168.1.2",
+					ContainerID: "container-1",
+					HeldBy:      "container-8",
+				}, {
+					NetworkID:   "network-1",
+					IP:          "192.168.1.3",
+					ContainerID: "container-3",
+					HeldBy:      "container-9",
+				}},
+			}))
+		})
+	})
+
+	Context("when a container record has an invalid IP", func() {
+		BeforeEach(func() {
+			datastore.AllReturns([]models.Container{
+				{ID: "container-1", IP: "banana", NetworkID: "network-1", HostIP: "10.0.0.1"},
+			}, nil)
+		})
+
+		It("logs the error and skips the record", func() {
+			Expect(restorer.Restore()).To(Succeed())
+			Expect(logger).To(gbytes.Say("parse-ip-failed.*banana"))
+			Expect(store.ReserveCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when a container record has an invalid IPv6 address", func() {
+		BeforeEach(func() {
+			datastore.AllReturns([]models.Container{
+				{ID: "container-1", IP: "192.168.1.2", IP6: "banana", NetworkID: "network-1", HostIP: "10.0.0.1"},
+			}, nil)
+		})
+
+		It("logs the error and skips the record", func() {
+			Expect(restorer.Restore()).To(Succeed())
+			Expect(logger).To(gbytes.Say("parse-ip-failed.*banana"))
+			Expect(store.ReserveCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			datastore.AllReturns(nil, errors.New("potato"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(restorer.Restore()).To(MatchError("listing containers: potato"))
+		})
+	})
+
+	Context("when creating the allocator store fails", func() {
+		BeforeEach(func() {
+			storeFactory.CreateReturns(nil, errors.New("tomato"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(restorer.Restore()).To(MatchError("failed to create allocator store: tomato"))
+		})
+	})
+
+	Context("when reserving fails", func() {
+		BeforeEach(func() {
+			store.ReserveReturns(false, errors.New("kiwi"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(restorer.Restore()).To(MatchError("failed to reserve IP: kiwi"))
+		})
+	})
+
+	Context("when listing the existing reservations fails", func() {
+		BeforeEach(func() {
+			store.ReserveReturns(false, nil)
+			store.ReservationsReturns(nil, errors.New("lime"))
+		})
+
+		It("returns a meaningful error", func() {
+			Expect(restorer.Restore()).To(MatchError("failed to list reservations: lime"))
+		})
+	})
+})
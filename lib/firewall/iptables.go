@@ -0,0 +1,117 @@
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+const (
+	ipForward  = "/proc/sys/net/ipv4/ip_forward"
+	ip6Forward = "/proc/sys/net/ipv6/conf/all/forwarding"
+)
+
+// IPTables manages the netfilter configuration of the network namespace it
+// is called from. IPv6 selects ip6tables instead of iptables.
+type IPTables struct {
+	IPv6 bool
+}
+
+// EnableForwarding turns on forwarding for the address family. A new network
+// namespace starts out with forwarding disabled.
+func (t IPTables) EnableForwarding() error {
+	return ioutil.WriteFile(t.forwardingPath(), []byte("1"), 0644)
+}
+
+// DisableForwarding turns forwarding for the address family back off.
+func (t IPTables) DisableForwarding() error {
+	return ioutil.WriteFile(t.forwardingPath(), []byte("0"), 0644)
+}
+
+// ForwardingEnabled reports whether forwarding is on for the address family.
+func (t IPTables) ForwardingEnabled() (bool, error) {
+	value, err := ioutil.ReadFile(t.forwardingPath())
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(value)) == "1", nil
+}
+
+// EnsureRule appends the rule to the chain unless the chain already has it.
+func (t IPTables) EnsureRule(table, chain string, rulespec []string) error {
+	check := append([]string{"-w", "-t", table, "-C", chain}, rulespec...)
+	if run(nil, t.command(), check...) == nil {
+		return nil
+	}
+
+	return run(nil, t.command(), append([]string{"-w", "-t", table, "-A", chain}, rulespec...)...)
+}
+
+// DeleteRule removes the rule from the chain if the chain has it.
+func (t IPTables) DeleteRule(table, chain string, rulespec []string) error {
+	check := append([]string{"-w", "-t", table, "-C", chain}, rulespec...)
+	if run(nil, t.command(), check...) != nil {
+		return nil
+	}
+
+	return run(nil, t.command(), append([]string{"-w", "-t", table, "-D", chain}, rulespec...)...)
+}
+
+// ReplaceChain atomically replaces the rules of the chain, creating it when
+// needed, and makes sure the parent chain jumps to it first.
+func (t IPTables) ReplaceChain(table, chain, parent string, rules [][]string) error {
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "*%s\n:%s - [0:0]\n", table, chain)
+	for _, rule := range rules {
+		fmt.Fprintf(&input, "-A %s %s\n", chain, strings.Join(rule, " "))
+	}
+	input.WriteString("COMMIT\n")
+
+	restore := t.command() + "-restore"
+	err := run(&input, restore, "--noflush")
+	if err != nil {
+		return fmt.Errorf("%s: %s", restore, err)
+	}
+
+	if run(nil, t.command(), "-w", "-t", table, "-C", parent, "-j", chain) == nil {
+		return nil
+	}
+
+	err = run(nil, t.command(), "-w", "-t", table, "-I", parent, "1", "-j", chain)
+	if err != nil {
+		return fmt.Errorf("inserting jump to %s: %s", chain, err)
+	}
+
+	return nil
+}
+
+func (t IPTables) forwardingPath() string {
+	if t.IPv6 {
+		return ip6Forward
+	}
+	return ipForward
+}
+
+func (t IPTables) command() string {
+	if t.IPv6 {
+		return "ip6tables"
+	}
+	return "iptables"
+}
+
+func run(stdin *bytes.Buffer, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -14,6 +14,8 @@ import (
 type netlinker interface {
 	LinkByName(name string) (netlink.Link, error)
 	SetNeigh(*netlink.Neigh) error
+	DelNeigh(*netlink.Neigh) error
+	ListNeigh(linkIndex, family int) ([]netlink.Neigh, error)
 }
 
 type ARPInserter struct {
@@ -45,41 +47,97 @@ func (a *ARPInserter) HandleResolvedNeighbors(ready chan error, ns namespace.Nam
 
 func (a *ARPInserter) addNeighbors(vxlanLinkIndex int, ns namespace.Namespace, resolvedChan <-chan watcher.Neighbor) {
 	for msg := range resolvedChan {
-		neigh := reverseConvert(msg.Neigh)
-		neigh.State = netlink.NUD_REACHABLE
-
-		fdb := &netlink.Neigh{
-			LinkIndex:    vxlanLinkIndex,
-			HardwareAddr: neigh.HardwareAddr,
-			IP:           msg.VTEP,
-			Family:       syscall.AF_BRIDGE,
-			Flags:        netlink.NTF_SELF,
-			State:        netlink.NUD_REACHABLE,
+		err := ns.Execute(func(*os.File) error {
+			return a.setNeighbor(vxlanLinkIndex, msg)
+		})
+		if err != nil {
+			a.Logger.Error("add-neighbor-failed", err)
 		}
+	}
+}
 
-		a.Logger.Info("adding-neigbor", lager.Data{
+// AddNeighbor programs the ARP and forwarding entries for a neighbor that is
+// already known, without waiting for the kernel to report a miss.
+func (a *ARPInserter) AddNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error {
+	return ns.Execute(func(*os.File) error {
+		vxlanLink, err := a.Netlinker.LinkByName(vxlanDeviceName)
+		if err != nil {
+			return fmt.Errorf("find link %q: %s", vxlanDeviceName, err)
+		}
+
+		neighbor.Neigh.LinkIndex = vxlanLink.Attrs().Index
+		return a.setNeighbor(vxlanLink.Attrs().Index, neighbor)
+	})
+}
+
+// RemoveNeighbor deletes the ARP and forwarding entries for a neighbor.
+// Entries that are already gone are not treated as an error.
+func (a *ARPInserter) RemoveNeighbor(ns namespace.Namespace, vxlanDeviceName string, neighbor watcher.Neighbor) error {
+	return ns.Execute(func(*os.File) error {
+		vxlanLink, err := a.Netlinker.LinkByName(vxlanDeviceName)
+		if err != nil {
+			return fmt.Errorf("find link %q: %s", vxlanDeviceName, err)
+		}
+
+		neighbor.Neigh.LinkIndex = vxlanLink.Attrs().Index
+		neigh, fdb := entries(vxlanLink.Attrs().Index, neighbor)
+
+		a.Logger.Info("removing-neighbor", lager.Data{
 			"neigh":   neigh.String(),
 			"fdb":     fdb,
 			"hw_addr": neigh.HardwareAddr.String(),
 		})
 
-		err := ns.Execute(func(*os.File) error {
-			err := a.Netlinker.SetNeigh(neigh)
-			if err != nil {
-				return fmt.Errorf("set L3 neighbor failed: %s", err)
-			}
-
-			err = a.Netlinker.SetNeigh(fdb)
-			if err != nil {
-				return fmt.Errorf("set L2 forward failed: %s", err)
-			}
+		err = a.Netlinker.DelNeigh(neigh)
+		if err != nil && err != syscall.ENOENT {
+			return fmt.Errorf("delete L3 neighbor failed: %s", err)
+		}
 
-			return nil
-		})
-		if err != nil {
-			a.Logger.Error("add-neighbor-failed", err)
+		err = a.Netlinker.DelNeigh(fdb)
+		if err != nil && err != syscall.ENOENT {
+			return fmt.Errorf("delete L2 forward failed: %s", err)
 		}
+
+		return nil
+	})
+}
+
+func (a *ARPInserter) setNeighbor(vxlanLinkIndex int, msg watcher.Neighbor) error {
+	neigh, fdb := entries(vxlanLinkIndex, msg)
+
+	a.Logger.Info("adding-neigbor", lager.Data{
+		"neigh":   neigh.String(),
+		"fdb":     fdb,
+		"hw_addr": neigh.HardwareAddr.String(),
+	})
+
+	err := a.Netlinker.SetNeigh(neigh)
+	if err != nil {
+		return fmt.Errorf("set L3 neighbor failed: %s", err)
+	}
+
+	err = a.Netlinker.SetNeigh(fdb)
+	if err != nil {
+		return fmt.Errorf("set L2 forward failed: %s", err)
+	}
+
+	return nil
+}
+
+func entries(vxlanLinkIndex int, msg watcher.Neighbor) (*netlink.Neigh, *netlink.Neigh) {
+	neigh := reverseConvert(msg.Neigh)
+	neigh.State = netlink.NUD_REACHABLE
+
+	fdb := &netlink.Neigh{
+		LinkIndex:    vxlanLinkIndex,
+		HardwareAddr: neigh.HardwareAddr,
+		IP:           msg.VTEP,
+		Family:       syscall.AF_BRIDGE,
+		Flags:        netlink.NTF_SELF,
+		State:        netlink.NUD_REACHABLE,
 	}
+
+	return neigh, fdb
 }
 
 func reverseConvert(input watcher.Neigh) *netlink.Neigh {
@@ -225,4 +225,180 @@ var _ = Describe("ARPInserter", func() {
 			})
 		})
 	})
+
+	Describe("AddNeighbor", func() {
+		var neighbor watcher.Neighbor
+
+		BeforeEach(func() {
+			mac, err := net.ParseMAC("01:02:03:04:05:06")
+			Expect(err).NotTo(HaveOccurred())
+
+			neighbor = watcher.Neighbor{
+				SandboxName: "some-sandbox",
+				VTEP:        net.ParseIP("10.11.12.13"),
+				Neigh: watcher.Neigh{
+					Family:       netlink.FAMILY_V4,
+					IP:           net.ParseIP("1.2.3.4"),
+					HardwareAddr: mac,
+				},
+			}
+		})
+
+		It("sets the neighbor and forwarding entries on the vxlan device in the namespace", func() {
+			err := inserter.AddNeighbor(ns, "some-vxlan-name", neighbor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ns.ExecuteCallCount()).To(Equal(1))
+			Expect(netlinker.LinkByNameArgsForCall(0)).To(Equal("some-vxlan-name"))
+
+			Expect(netlinker.SetNeighCallCount()).To(Equal(2))
+			Expect(netlinker.SetNeighArgsForCall(0)).To(Equal(&netlink.Neigh{
+				LinkIndex:    9876,
+				Family:       netlink.FAMILY_V4,
+				State:        netlink.NUD_REACHABLE,
+				IP:           neighbor.Neigh.IP,
+				HardwareAddr: neighbor.Neigh.HardwareAddr,
+			}))
+			Expect(netlinker.SetNeighArgsForCall(1)).To(Equal(&netlink.Neigh{
+				LinkIndex:    9876,
+				HardwareAddr: neighbor.Neigh.HardwareAddr,
+				Family:       syscall.AF_BRIDGE,
+				State:        netlink.NUD_REACHABLE,
+				Flags:        netlink.NTF_SELF,
+				IP:           neighbor.VTEP,
+			}))
+		})
+
+		Context("when the vxlan device cannot be found", func() {
+			BeforeEach(func() {
+				netlinker.LinkByNameReturns(nil, errors.New("boom-boom"))
+			})
+
+			It("returns a meaningful error", func() {
+				err := inserter.AddNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError(`find link "some-vxlan-name": boom-boom`))
+			})
+		})
+
+		Context("when setting the neighbor entry fails", func() {
+			BeforeEach(func() {
+				netlinker.SetNeighReturns(errors.New("go huskies"))
+			})
+
+			It("returns a meaningful error", func() {
+				err := inserter.AddNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError("set L3 neighbor failed: go huskies"))
+			})
+		})
+
+		Context("when executing in the namespace fails", func() {
+			BeforeEach(func() {
+				ns.ExecuteReturns(errors.New("peppers"))
+			})
+
+			It("returns the error", func() {
+				err := inserter.AddNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError("peppers"))
+			})
+		})
+	})
+
+	Describe("RemoveNeighbor", func() {
+		var neighbor watcher.Neighbor
+
+		BeforeEach(func() {
+			mac, err := net.ParseMAC("01:02:03:04:05:06")
+			Expect(err).NotTo(HaveOccurred())
+
+			neighbor = watcher.Neighbor{
+				SandboxName: "some-sandbox",
+				VTEP:        net.ParseIP("10.11.12.13"),
+				Neigh: watcher.Neigh{
+					Family:       netlink.FAMILY_V4,
+					IP:           net.ParseIP("1.2.3.4"),
+					HardwareAddr: mac,
+				},
+			}
+		})
+
+		It("deletes the neighbor and forwarding entries from the vxlan device in the namespace", func() {
+			err := inserter.RemoveNeighbor(ns, "some-vxlan-name", neighbor)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ns.ExecuteCallCount()).To(Equal(1))
+			Expect(netlinker.LinkByNameArgsForCall(0)).To(Equal("some-vxlan-name"))
+
+			Expect(netlinker.DelNeighCallCount()).To(Equal(2))
+			Expect(netlinker.DelNeighArgsForCall(0)).To(Equal(&netlink.Neigh{
+				LinkIndex:    9876,
+				Family:       netlink.FAMILY_V4,
+				State:        netlink.NUD_REACHABLE,
+				IP:           neighbor.Neigh.IP,
+				HardwareAddr: neighbor.Neigh.HardwareAddr,
+			}))
+			Expect(netlinker.DelNeighArgsForCall(1)).To(Equal(&netlink.Neigh{
+				LinkIndex:    9876,
+				HardwareAddr: neighbor.Neigh.HardwareAddr,
+				Family:       syscall.AF_BRIDGE,
+				State:        netlink.NUD_REACHABLE,
+				Flags:        netlink.NTF_SELF,
+				IP:           neighbor.VTEP,
+			}))
+		})
+
+		Context("when the entries do not exist", func() {
+			BeforeEach(func() {
+				netlinker.DelNeighReturns(syscall.ENOENT)
+			})
+
+			It("succeeds", func() {
+				err := inserter.RemoveNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(netlinker.DelNeighCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when the vxlan device cannot be found", func() {
+			BeforeEach(func() {
+				netlinker.LinkByNameReturns(nil, errors.New("boom-boom"))
+			})
+
+			It("returns a meaningful error", func() {
+				err := inserter.RemoveNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError(`find link "some-vxlan-name": boom-boom`))
+			})
+		})
+
+		Context("when deleting the neighbor entry fails", func() {
+			BeforeEach(func() {
+				netlinker.DelNeighStub = func(n *netlink.Neigh) error {
+					if netlinker.DelNeighCallCount() == 1 {
+						return errors.New("go huskies")
+					}
+					return nil
+				}
+			})
+
+			It("returns a meaningful error", func() {
+				err := inserter.RemoveNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError("delete L3 neighbor failed: go huskies"))
+			})
+		})
+
+		Context("when deleting the forwarding entry fails", func() {
+			BeforeEach(func() {
+				netlinker.DelNeighStub = func(n *netlink.Neigh) error {
+					if netlinker.DelNeighCallCount() == 2 {
+						return errors.New("fail-on-two")
+					}
+					return nil
+				}
+			})
+
+			It("returns a meaningful error", func() {
+				err := inserter.RemoveNeighbor(ns, "some-vxlan-name", neighbor)
+				Expect(err).To(MatchError("delete L2 forward failed: fail-on-two"))
+			})
+		})
+	})
 })
@@ -0,0 +1,47 @@
+package neigh
+
+import (
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter -o ../../fakes/sandbox_iterator.go --fake-name SandboxIterator . sandboxIterator
+type sandboxIterator interface {
+	ForEach(sandbox.SandboxCallback) error
+}
+
+// EvictionLoop runs the Evictor over every sandbox once per Interval.
+type EvictionLoop struct {
+	Logger    lager.Logger
+	Clock     clock.Clock
+	Interval  time.Duration
+	Sandboxes sandboxIterator
+	Evictor   sandbox.SandboxCallback
+}
+
+func (l *EvictionLoop) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := l.Logger.Session("eviction-loop", lager.Data{"interval": l.Interval.String()})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	ticker := l.Clock.NewTicker(l.Interval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			err := l.Sandboxes.ForEach(l.Evictor)
+			if err != nil {
+				logger.Error("sweep-failed", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package neigh_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/neigh"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("EvictionLoop", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		sandboxes *fakes.SandboxIterator
+		evictor   *fakes.SandboxCallback
+		loop      *neigh.EvictionLoop
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		sandboxes = &fakes.SandboxIterator{}
+		evictor = &fakes.SandboxCallback{}
+
+		loop = &neigh.EvictionLoop{
+			Logger:    logger,
+			Clock:     fakeClock,
+			Interval:  time.Minute,
+			Sandboxes: sandboxes,
+			Evictor:   evictor,
+		}
+
+		process = ifrit.Invoke(loop)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("runs the evictor across every sandbox on each tick", func() {
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		Expect(sandboxes.ForEachCallCount()).To(Equal(0))
+
+		fakeClock.Increment(time.Minute)
+		Eventually(sandboxes.ForEachCallCount).Should(Equal(1))
+		Expect(sandboxes.ForEachArgsForCall(0)).To(Equal(evictor))
+
+		fakeClock.Increment(time.Minute)
+		Eventually(sandboxes.ForEachCallCount).Should(Equal(2))
+	})
+
+	Context("when a sweep fails", func() {
+		BeforeEach(func() {
+			sandboxes.ForEachReturns(errors.New("potato"))
+		})
+
+		It("logs the error and keeps going", func() {
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+			fakeClock.Increment(time.Minute)
+			Eventually(logger).Should(gbytes.Say("sweep-failed.*potato"))
+
+			fakeClock.Increment(time.Minute)
+			Eventually(sandboxes.ForEachCallCount).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,160 @@
+package neigh
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/vishvananda/netlink"
+)
+
+type containerStore interface {
+	AllBySandbox(sandboxName string) ([]models.Container, error)
+}
+
+// Evictor removes neighbor and forwarding entries from a sandbox vxlan
+// device when the container they point at is no longer in the store, or now
+// lives somewhere else. It satisfies sandbox.SandboxCallback so that it can
+// be run across every sandbox in the repository.
+type Evictor struct {
+	Logger    lager.Logger
+	Netlinker netlinker
+	Store     containerStore
+}
+
+func (e *Evictor) Callback(ns namespace.Namespace) error {
+	sandboxName := path.Base(ns.Name())
+	logger := e.Logger.Session("evict-stale-neighbors", lager.Data{"sandbox": sandboxName})
+
+	err := e.evict(ns, sandboxName)
+	if err != nil {
+		logger.Error("evict-failed", err)
+	}
+
+	return nil
+}
+
+func (e *Evictor) evict(ns namespace.Namespace, sandboxName string) error {
+	vxlanDeviceName, err := vxlanName(sandboxName)
+	if err != nil {
+		return err
+	}
+
+	var neighbors, forwarding []netlink.Neigh
+	err = ns.Execute(func(*os.File) error {
+		vxlanLink, err := e.Netlinker.LinkByName(vxlanDeviceName)
+		if err != nil {
+			return fmt.Errorf("find link %q: %s", vxlanDeviceName, err)
+		}
+
+		for _, family := range []int{nl.FAMILY_V4, nl.FAMILY_V6} {
+			entries, err := e.Netlinker.ListNeigh(vxlanLink.Attrs().Index, family)
+			if err != nil {
+				return fmt.Errorf("list neighbors: %s", err)
+			}
+			neighbors = append(neighbors, entries...)
+		}
+
+		forwarding, err = e.Netlinker.ListNeigh(vxlanLink.Attrs().Index, syscall.AF_BRIDGE)
+		if err != nil {
+			return fmt.Errorf("list forwarding entries: %s", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("namespace execute: %s", err)
+	}
+
+	neighbors = withAddresses(neighbors)
+	forwarding = withAddresses(forwarding)
+	if len(neighbors) == 0 && len(forwarding) == 0 {
+		return nil
+	}
+
+	// the store is consulted outside of the namespace so that new database
+	// connections are not dialed from inside the sandbox
+	containers, err := e.Store.AllBySandbox(sandboxName)
+	if err != nil {
+		return fmt.Errorf("store lookup: %s", err)
+	}
+
+	byIP := map[string]models.Container{}
+	byMAC := map[string]models.Container{}
+	for _, c := range containers {
+		byIP[c.IP] = c
+		if c.IP6 != "" {
+			byIP[c.IP6] = c
+		}
+		byMAC[c.MAC] = c
+	}
+
+	var stale []netlink.Neigh
+	for _, n := range neighbors {
+		container, ok := byIP[n.IP.String()]
+		if !ok || container.MAC != n.HardwareAddr.String() {
+			stale = append(stale, n)
+		}
+	}
+
+	for _, n := range forwarding {
+		container, ok := byMAC[n.HardwareAddr.String()]
+		if !ok || !n.IP.Equal(net.ParseIP(container.HostIP)) {
+			stale = append(stale, n)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	return ns.Execute(func(*os.File) error {
+		for i := range stale {
+			e.Logger.Info("evicting-neighbor", lager.Data{
+				"sandbox": sandboxName,
+				"neigh":   stale[i].String(),
+				"family":  stale[i].Family,
+			})
+
+			err := e.Netlinker.DelNeigh(&stale[i])
+			if err != nil && err != syscall.ENOENT {
+				return fmt.Errorf("delete neighbor: %s", err)
+			}
+		}
+		return nil
+	})
+}
+
+func vxlanName(sandboxName string) (string, error) {
+	vni := strings.TrimPrefix(sandboxName, "vni-")
+	if vni == sandboxName {
+		return "", fmt.Errorf("not a valid sandbox name: %q", sandboxName)
+	}
+
+	return "vxlan" + vni, nil
+}
+
+// withAddresses drops incomplete entries, which have no IP or MAC to check
+// against the store.
+func withAddresses(entries []netlink.Neigh) []netlink.Neigh {
+	var complete []netlink.Neigh
+	for _, n := range entries {
+		if n.IP == nil || isZeroMAC(n.HardwareAddr) {
+			continue
+		}
+		complete = append(complete, n)
+	}
+	return complete
+}
+
+func isZeroMAC(mac net.HardwareAddr) bool {
+	return len(mac) == 0 || bytes.Equal(mac, make(net.HardwareAddr, len(mac)))
+}
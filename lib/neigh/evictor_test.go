@@ -0,0 +1,260 @@
+package neigh_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/neigh"
+	nl_fakes "github.com/cloudfoundry-incubator/ducati-daemon/lib/nl/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/vishvananda/netlink"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Evictor", func() {
+	var (
+		evictor    *neigh.Evictor
+		ns         *fakes.Namespace
+		netlinker  *nl_fakes.Netlinker
+		datastore  *fakes.Store
+		logger     *lagertest.TestLogger
+		neighbors  []netlink.Neigh
+		forwarding []netlink.Neigh
+		neighbors6 []netlink.Neigh
+		liveMAC    net.HardwareAddr
+		goneMAC    net.HardwareAddr
+	)
+
+	BeforeEach(func() {
+		ns = &fakes.Namespace{}
+		netlinker = &nl_fakes.Netlinker{}
+		datastore = &fakes.Store{}
+		logger = lagertest.NewTestLogger("test")
+
+		ns.NameReturns("/var/vcap/data/ducati/sandbox/vni-42")
+		ns.ExecuteStub = func(callback func(*os.File) error) error {
+			return callback(nil)
+		}
+
+		netlinker.LinkByNameReturns(&netlink.Vxlan{
+			LinkAttrs: netlink.LinkAttrs{Index: 9876},
+		}, nil)
+
+		liveMAC, _ = net.ParseMAC("aa:aa:aa:aa:aa:aa")
+		goneMAC, _ = net.ParseMAC("bb:bb:bb:bb:bb:bb")
+
+		neighbors = []netlink.Neigh{
+			{LinkIndex: 9876, Family: netlink.FAMILY_V4, IP: net.ParseIP("192.168.1.2"), HardwareAddr: liveMAC},
+			{LinkIndex: 9876, Family: netlink.FAMILY_V4, IP: net.ParseIP("192.168.1.3"), HardwareAddr: goneMAC},
+		}
+		forwarding = []netlink.Neigh{
+			{LinkIndex: 9876, Family: syscall.AF_BRIDGE, IP: net.ParseIP("10.0.0.2"), HardwareAddr: liveMAC},
+			{LinkIndex: 9876, Family: syscall.AF_BRIDGE, IP: net.ParseIP("10.0.0.3"), HardwareAddr: goneMAC},
+		}
+
+		neighbors6 = nil
+
+		netlinker.ListNeighStub = func(linkIndex, family int) ([]netlink.Neigh, error) {
+			switch family {
+			case syscall.AF_BRIDGE:
+				return forwarding, nil
+			case netlink.FAMILY_V6:
+				return neighbors6, nil
+			}
+			return neighbors, nil
+		}
+
+		live := models.Container{IP: "192.168.1.2", IP6: "fd00::2", MAC: "aa:aa:aa:aa:aa:aa", HostIP: "10.0.0.2", SandboxName: "vni-42"}
+		datastore.AllBySandboxReturns([]models.Container{live}, nil)
+
+		evictor = &neigh.Evictor{
+			Logger:    logger,
+			Netlinker: netlinker,
+			Store:     datastore,
+		}
+	})
+
+	It("lists the entries on the vxlan device of the sandbox", func() {
+		Expect(evictor.Callback(ns)).To(Succeed())
+
+		Expect(netlinker.LinkByNameArgsForCall(0)).To(Equal("vxlan42"))
+		Expect(netlinker.ListNeighCallCount()).To(Equal(3))
+
+		linkIndex, family := netlinker.ListNeighArgsForCall(0)
+		Expect(linkIndex).To(Equal(9876))
+		Expect(family).To(Equal(netlink.FAMILY_V4))
+
+		linkIndex, family = netlinker.ListNeighArgsForCall(1)
+		Expect(linkIndex).To(Equal(9876))
+		Expect(family).To(Equal(netlink.FAMILY_V6))
+
+		linkIndex, family = netlinker.ListNeighArgsForCall(2)
+		Expect(linkIndex).To(Equal(9876))
+		Expect(family).To(Equal(syscall.AF_BRIDGE))
+	})
+
+	It("looks up the containers of the sandbox in the store once", func() {
+		Expect(evictor.Callback(ns)).To(Succeed())
+
+		Expect(datastore.AllBySandboxCallCount()).To(Equal(1))
+		Expect(datastore.AllBySandboxArgsForCall(0)).To(Equal("vni-42"))
+	})
+
+	It("deletes the entries for containers that are no longer in the store", func() {
+		Expect(evictor.Callback(ns)).To(Succeed())
+
+		Expect(netlinker.DelNeighCallCount()).To(Equal(2))
+		Expect(netlinker.DelNeighArgsForCall(0)).To(Equal(&neighbors[1]))
+		Expect(netlinker.DelNeighArgsForCall(1)).To(Equal(&forwarding[1]))
+
+		Expect(logger).To(gbytes.Say("evicting-neighbor"))
+	})
+
+	It("does not consult the store from inside the sandbox namespace", func() {
+		ns.ExecuteStub = func(callback func(*os.File) error) error {
+			lookups := datastore.AllBySandboxCallCount()
+			err := callback(nil)
+			Expect(datastore.AllBySandboxCallCount()).To(Equal(lookups))
+			return err
+		}
+
+		Expect(evictor.Callback(ns)).To(Succeed())
+		Expect(ns.ExecuteCallCount()).To(Equal(2))
+	})
+
+	Context("when there are IPv6 neighbor entries", func() {
+		BeforeEach(func() {
+			neighbors = nil
+			forwarding = nil
+			neighbors6 = []netlink.Neigh{
+				{LinkIndex: 9876, Family: netlink.FAMILY_V6, IP: net.ParseIP("fd00::2"), HardwareAddr: liveMAC},
+				{LinkIndex: 9876, Family: netlink.FAMILY_V6, IP: net.ParseIP("fd00::3"), HardwareAddr: goneMAC},
+			}
+		})
+
+		It("deletes the entries for addresses that are no longer in the store", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(netlinker.DelNeighCallCount()).To(Equal(1))
+			Expect(netlinker.DelNeighArgsForCall(0)).To(Equal(&neighbors6[1]))
+		})
+	})
+
+	Context("when a neighbor entry points at a different MAC than the store", func() {
+		BeforeEach(func() {
+			neighbors = neighbors[:1]
+			neighbors[0].HardwareAddr = goneMAC
+			forwarding = nil
+		})
+
+		It("deletes the entry", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(netlinker.DelNeighCallCount()).To(Equal(1))
+			Expect(netlinker.DelNeighArgsForCall(0)).To(Equal(&neighbors[0]))
+		})
+	})
+
+	Context("when a forwarding entry points at a different VTEP than the store", func() {
+		BeforeEach(func() {
+			neighbors = nil
+			forwarding = forwarding[:1]
+			forwarding[0].IP = net.ParseIP("10.0.0.99")
+		})
+
+		It("deletes the entry", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(netlinker.DelNeighCallCount()).To(Equal(1))
+			Expect(netlinker.DelNeighArgsForCall(0)).To(Equal(&forwarding[0]))
+		})
+	})
+
+	Context("when every entry is current", func() {
+		BeforeEach(func() {
+			neighbors = neighbors[:1]
+			forwarding = forwarding[:1]
+		})
+
+		It("does not delete anything", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(ns.ExecuteCallCount()).To(Equal(1))
+			Expect(netlinker.DelNeighCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when an entry has no address", func() {
+		BeforeEach(func() {
+			neighbors = []netlink.Neigh{{IP: net.ParseIP("192.168.1.9")}}
+			forwarding = []netlink.Neigh{{IP: net.ParseIP("10.0.0.9"), HardwareAddr: net.HardwareAddr{0, 0, 0, 0, 0, 0}}}
+		})
+
+		It("leaves it alone", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(datastore.AllBySandboxCallCount()).To(Equal(0))
+			Expect(netlinker.DelNeighCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the entry is already gone", func() {
+		BeforeEach(func() {
+			netlinker.DelNeighReturns(syscall.ENOENT)
+		})
+
+		It("carries on", func() {
+			Expect(evictor.Callback(ns)).To(Succeed())
+
+			Expect(netlinker.DelNeighCallCount()).To(Equal(2))
+			Expect(logger).NotTo(gbytes.Say("evict-failed"))
+		})
+	})
+
+	Describe("failures", func() {
+		It("logs when the sandbox name is not a vni", func() {
+			ns.NameReturns("/some/sandbox/potato")
+
+			Expect(evictor.Callback(ns)).To(Succeed())
+			Expect(logger).To(gbytes.Say(`evict-failed.*not a valid sandbox name`))
+			Expect(ns.ExecuteCallCount()).To(Equal(0))
+		})
+
+		It("logs when the vxlan device cannot be found", func() {
+			netlinker.LinkByNameReturns(nil, errors.New("boom-boom"))
+
+			Expect(evictor.Callback(ns)).To(Succeed())
+			Expect(logger).To(gbytes.Say(`evict-failed.*find link.*boom-boom`))
+		})
+
+		It("logs when listing entries fails", func() {
+			netlinker.ListNeighStub = nil
+			netlinker.ListNeighReturns(nil, errors.New("kiwi"))
+
+			Expect(evictor.Callback(ns)).To(Succeed())
+			Expect(logger).To(gbytes.Say(`evict-failed.*list neighbors: kiwi`))
+		})
+
+		It("logs and deletes nothing when the store fails", func() {
+			datastore.AllBySandboxReturns(nil, errors.New("lime"))
+
+			Expect(evictor.Callback(ns)).To(Succeed())
+			Expect(logger).To(gbytes.Say(`evict-failed.*store lookup: lime`))
+			Expect(netlinker.DelNeighCallCount()).To(Equal(0))
+		})
+
+		It("logs when deleting an entry fails", func() {
+			netlinker.DelNeighReturns(errors.New("mango"))
+
+			Expect(evictor.Callback(ns)).To(Succeed())
+			Expect(logger).To(gbytes.Say(`evict-failed.*delete neighbor: mango`))
+		})
+	})
+})
@@ -130,6 +130,32 @@ type Netlinker struct {
 	setNeighReturns struct {
 		result1 error
 	}
+	DelNeighStub        func(*netlink.Neigh) error
+	delNeighMutex       sync.RWMutex
+	delNeighArgsForCall []struct {
+		arg1 *netlink.Neigh
+	}
+	delNeighReturns struct {
+		result1 error
+	}
+	ListNeighStub        func(linkIndex int, family int) ([]netlink.Neigh, error)
+	listNeighMutex       sync.RWMutex
+	listNeighArgsForCall []struct {
+		linkIndex int
+		family    int
+	}
+	listNeighReturns struct {
+		result1 []netlink.Neigh
+		result2 error
+	}
+	RouteDelStub        func(arg1 *netlink.Route) error
+	routeDelMutex       sync.RWMutex
+	routeDelArgsForCall []struct {
+		arg1 *netlink.Route
+	}
+	routeDelReturns struct {
+		result1 error
+	}
 }
 
 func (fake *Netlinker) LinkAdd(link netlink.Link) error {
@@ -588,4 +614,102 @@ func (fake *Netlinker) SetNeighReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *Netlinker) DelNeigh(arg1 *netlink.Neigh) error {
+	fake.delNeighMutex.Lock()
+	fake.delNeighArgsForCall = append(fake.delNeighArgsForCall, struct {
+		arg1 *netlink.Neigh
+	}{arg1})
+	fake.delNeighMutex.Unlock()
+	if fake.DelNeighStub != nil {
+		return fake.DelNeighStub(arg1)
+	} else {
+		return fake.delNeighReturns.result1
+	}
+}
+
+func (fake *Netlinker) DelNeighCallCount() int {
+	fake.delNeighMutex.RLock()
+	defer fake.delNeighMutex.RUnlock()
+	return len(fake.delNeighArgsForCall)
+}
+
+func (fake *Netlinker) DelNeighArgsForCall(i int) *netlink.Neigh {
+	fake.delNeighMutex.RLock()
+	defer fake.delNeighMutex.RUnlock()
+	return fake.delNeighArgsForCall[i].arg1
+}
+
+func (fake *Netlinker) DelNeighReturns(result1 error) {
+	fake.DelNeighStub = nil
+	fake.delNeighReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Netlinker) ListNeigh(linkIndex int, family int) ([]netlink.Neigh, error) {
+	fake.listNeighMutex.Lock()
+	fake.listNeighArgsForCall = append(fake.listNeighArgsForCall, struct {
+		linkIndex int
+		family    int
+	}{linkIndex, family})
+	fake.listNeighMutex.Unlock()
+	if fake.ListNeighStub != nil {
+		return fake.ListNeighStub(linkIndex, family)
+	} else {
+		return fake.listNeighReturns.result1, fake.listNeighReturns.result2
+	}
+}
+
+func (fake *Netlinker) ListNeighCallCount() int {
+	fake.listNeighMutex.RLock()
+	defer fake.listNeighMutex.RUnlock()
+	return len(fake.listNeighArgsForCall)
+}
+
+func (fake *Netlinker) ListNeighArgsForCall(i int) (int, int) {
+	fake.listNeighMutex.RLock()
+	defer fake.listNeighMutex.RUnlock()
+	return fake.listNeighArgsForCall[i].linkIndex, fake.listNeighArgsForCall[i].family
+}
+
+func (fake *Netlinker) ListNeighReturns(result1 []netlink.Neigh, result2 error) {
+	fake.ListNeighStub = nil
+	fake.listNeighReturns = struct {
+		result1 []netlink.Neigh
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Netlinker) RouteDel(arg1 *netlink.Route) error {
+	fake.routeDelMutex.Lock()
+	fake.routeDelArgsForCall = append(fake.routeDelArgsForCall, struct {
+		arg1 *netlink.Route
+	}{arg1})
+	fake.routeDelMutex.Unlock()
+	if fake.RouteDelStub != nil {
+		return fake.RouteDelStub(arg1)
+	} else {
+		return fake.routeDelReturns.result1
+	}
+}
+
+func (fake *Netlinker) RouteDelCallCount() int {
+	fake.routeDelMutex.RLock()
+	defer fake.routeDelMutex.RUnlock()
+	return len(fake.routeDelArgsForCall)
+}
+
+func (fake *Netlinker) RouteDelArgsForCall(i int) *netlink.Route {
+	fake.routeDelMutex.RLock()
+	defer fake.routeDelMutex.RUnlock()
+	return fake.routeDelArgsForCall[i].arg1
+}
+
+func (fake *Netlinker) RouteDelReturns(result1 error) {
+	fake.RouteDelStub = nil
+	fake.routeDelReturns = struct {
+		result1 error
+	}{result1}
+}
+
 var _ nl.Netlinker = new(Netlinker)
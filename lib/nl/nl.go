@@ -7,6 +7,7 @@ import (
 )
 
 const FAMILY_V4 = netlink.FAMILY_V4
+const FAMILY_V6 = netlink.FAMILY_V6
 
 //go:generate counterfeiter -o fakes/nlsocket.go --fake-name NLSocket . NLSocket
 type NLSocket interface {
@@ -26,8 +27,11 @@ type Netlinker interface {
 	LinkSetMaster(slave netlink.Link, master *netlink.Bridge) error
 	LinkByIndex(int) (netlink.Link, error)
 	RouteAdd(*netlink.Route) error
+	RouteDel(*netlink.Route) error
 	RouteList(netlink.Link, int) ([]netlink.Route, error)
 	Subscribe(int, ...uint) (NLSocket, error)
 	NeighDeserialize([]byte) (*netlink.Neigh, error)
 	SetNeigh(*netlink.Neigh) error
+	DelNeigh(*netlink.Neigh) error
+	ListNeigh(linkIndex, family int) ([]netlink.Neigh, error)
 }
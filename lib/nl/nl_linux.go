@@ -41,6 +41,10 @@ func (*nl) RouteAdd(route *netlink.Route) error {
 	return netlink.RouteAdd(route)
 }
 
+func (*nl) RouteDel(route *netlink.Route) error {
+	return netlink.RouteDel(route)
+}
+
 func (*nl) LinkDel(link netlink.Link) error {
 	return netlink.LinkDel(link)
 }
@@ -64,3 +68,11 @@ func (*nl) NeighDeserialize(data []byte) (*netlink.Neigh, error) {
 func (*nl) SetNeigh(neigh *netlink.Neigh) error {
 	return netlink.NeighSet(neigh)
 }
+
+func (*nl) DelNeigh(neigh *netlink.Neigh) error {
+	return netlink.NeighDel(neigh)
+}
+
+func (*nl) ListNeigh(linkIndex, family int) ([]netlink.Neigh, error) {
+	return netlink.NeighList(linkIndex, family)
+}
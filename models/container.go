@@ -1,11 +1,15 @@
 package models
 
 type Container struct {
-	ID          string `json:"id"`
-	IP          string `json:"ip"`
-	MAC         string `json:"mac"`
-	HostIP      string `json:"host_ip" db:"host_ip"`
-	NetworkID   string `json:"network_id" db:"network_id"`
-	SandboxName string `json:"sandbox_name" db:"sandbox_name"`
-	App         string `json:"app" db:"app"`
+	ID                 string      `json:"id"`
+	IP                 string      `json:"ip"`
+	IP6                string      `json:"ip6,omitempty" db:"ip6"`
+	MAC                string      `json:"mac"`
+	HostIP             string      `json:"host_ip" db:"host_ip"`
+	NetworkID          string      `json:"network_id" db:"network_id"`
+	SandboxName        string      `json:"sandbox_name" db:"sandbox_name"`
+	App                string      `json:"app" db:"app"`
+	ContainerNamespace string      `json:"container_namespace" db:"container_namespace"`
+	InterfaceName      string      `json:"interface_name" db:"interface_name"`
+	IPAMResult         *IPAMResult `json:"ipam_result,omitempty" db:"ipam_result"`
 }
@@ -0,0 +1,11 @@
+package models
+
+const (
+	ContainerCreated = "create"
+	ContainerDeleted = "delete"
+)
+
+type ContainerEvent struct {
+	Action    string    `json:"action"`
+	Container Container `json:"container"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "net"
+
+// EgressLink is the egress link of a sandbox on this host: the name of its
+// host end and the address of its sandbox end, which is where the host
+// routes traffic for the sandbox's containers.
+type EgressLink struct {
+	SandboxName    string
+	HostInterface  string
+	SandboxAddress net.IP
+}
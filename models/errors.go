@@ -0,0 +1,27 @@
+package models
+
+import "errors"
+
+// The errors below are returned by the daemon and surfaced by the client
+// from the status of the response, so they live here where both can reach
+// them without the client importing the daemon.
+
+// ContainerConflictError is returned when an ADD names a container that is
+// already attached through a different namespace or interface.
+var ContainerConflictError = errors.New("container already attached with a different namespace or interface")
+
+// InvalidArgsError is returned when the CNI_ARGS passed with an ADD are not
+// a list of KEY=VALUE pairs, or carry an IP that cannot be parsed.
+var InvalidArgsError = errors.New("invalid CNI args")
+
+// UndefinedNetworkError is returned in strict mode when an ADD maps to a
+// network that has no definition.
+var UndefinedNetworkError = errors.New("network is not defined")
+
+// LockTimeoutError is returned when an operation on a container cannot
+// take the container's lock in time.
+var LockTimeoutError = errors.New("timed out waiting for another operation on this container")
+
+// NetworkInUseError is returned when a network that still has containers
+// attached is deleted.
+var NetworkInUseError = errors.New("network has containers attached")
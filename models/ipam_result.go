@@ -0,0 +1,33 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/appc/cni/pkg/types"
+)
+
+// IPAMResult is the CNI result handed back for a container, stored alongside
+// the container record as JSON so that a repeated ADD can return it verbatim.
+type IPAMResult struct {
+	types.Result
+}
+
+func (r IPAMResult) Value() (driver.Value, error) {
+	return json.Marshal(r.Result)
+}
+
+func (r *IPAMResult) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IPAMResult", src)
+	}
+
+	return json.Unmarshal(raw, &r.Result)
+}
@@ -0,0 +1,22 @@
+package models
+
+// IPAMUsage reports how much of a network's address space on this host is
+// in use, with one PoolUsage per address family.
+type IPAMUsage struct {
+	NetworkID string      `json:"network_id"`
+	Pools     []PoolUsage `json:"pools"`
+}
+
+// PoolUsage describes a single pool. Size counts the addresses in the subnet
+// before the gateway and exclusions are taken away; Quarantined counts
+// released addresses that are not yet allocatable again. IPv6 pools only hand
+// out the start of their subnet, so Free counts what is left of that part.
+type PoolUsage struct {
+	Subnet      string            `json:"subnet"`
+	Gateway     string            `json:"gateway"`
+	Size        uint64            `json:"size"`
+	Allocated   uint64            `json:"allocated"`
+	Quarantined uint64            `json:"quarantined"`
+	Free        uint64            `json:"free"`
+	Allocations map[string]string `json:"allocations"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/appc/cni/pkg/types"
+)
+
+// Network is the definition of a network. Settings left at their zero value
+// fall back to the daemon's defaults: the VNI is assigned by the registry,
+// addresses come from the local subnet and the container veth gets the
+// overlay MTU. Subnet spans the whole cluster; each host allocates from it
+// starting at the offset its local subnet starts at, with its own gateway.
+type Network struct {
+	ID     string     `json:"id"`
+	Name   string     `json:"name"`
+	VNI    int        `json:"vni"`
+	Subnet string     `json:"subnet"`
+	MTU    int        `json:"mtu"`
+	DNS    NetworkDNS `json:"dns"`
+}
+
+// NetworkDetails is a network together with the containers attached to it.
+// Network is nil when the network has containers but no definition.
+type NetworkDetails struct {
+	Network    *Network    `json:"network,omitempty"`
+	Containers []Container `json:"containers"`
+}
+
+// NetworkDNS is the DNS configuration handed to containers on the network,
+// stored alongside the network definition as JSON.
+type NetworkDNS struct {
+	types.DNS
+}
+
+func (d NetworkDNS) Value() (driver.Value, error) {
+	return json.Marshal(d.DNS)
+}
+
+func (d *NetworkDNS) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NetworkDNS", src)
+	}
+
+	return json.Unmarshal(raw, &d.DNS)
+}
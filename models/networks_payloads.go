@@ -5,6 +5,7 @@ import "github.com/appc/cni/pkg/types"
 type Properties struct {
 	AppID   string `json:"app_id"`
 	SpaceID string `json:"space_id"`
+	OrgID   string `json:"org_id"`
 }
 
 type NetworkPayload struct {
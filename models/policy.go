@@ -0,0 +1,19 @@
+package models
+
+// Policy allows the containers of the Source app to reach the containers of
+// the Destination app. Containers on the same network reach each other over
+// the overlay. Containers on different networks are routed through the
+// egress links of their sandboxes and the hosts, over IPv4 only; that needs
+// egress enabled on both networks, subnets that do not overlap, hosts that
+// reach each other directly, and egress deny lists that leave the peers
+// out. Protocol is "tcp", "udp", "icmp" or empty for any protocol; Port is
+// the destination port for tcp and udp, and zero for any port. Once an app
+// is the destination of a policy, only traffic that some policy allows can
+// reach it.
+type Policy struct {
+	ID          string `json:"id"`
+	Source      string `json:"source_app" db:"source_app"`
+	Destination string `json:"destination_app" db:"destination_app"`
+	Protocol    string `json:"protocol"`
+	Port        int    `json:"port"`
+}
@@ -0,0 +1,155 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/clock"
+)
+
+//go:generate counterfeiter -o ../fakes/command_runner.go --fake-name CommandRunner . commandRunner
+type commandRunner interface {
+	Run(path string, stdin []byte, timeout time.Duration) ([]byte, error)
+}
+
+//go:generate counterfeiter -o ../fakes/vni_registerer.go --fake-name VNIRegisterer . vniRegisterer
+type vniRegisterer interface {
+	Register(networkID string, vni int) error
+}
+
+type execMapping struct {
+	NetworkID string `json:"network_id"`
+	VNI       *int   `json:"vni"`
+}
+
+type execCacheEntry struct {
+	networkID string
+	expires   time.Time
+}
+
+// ExecNetworkMapper delegates the mapping decision to an operator supplied
+// Executable. The executable is given the models.NetworkPayload as JSON on
+// stdin and must print {"network_id": "...", "vni": 42} on stdout; the vni
+// is optional. Decisions are cached per payload for CacheTTL. A VNI is
+// registered for the network in Registry, and a network that already has a
+// different VNI, or a VNI that belongs to another network, is an error.
+// Networks the executable has not named a VNI for fall back to HashVNI.
+type ExecNetworkMapper struct {
+	Executable string
+	Runner     commandRunner
+	Registry   vniRegisterer
+	Timeout    time.Duration
+	Clock      clock.Clock
+	CacheTTL   time.Duration
+
+	lock    sync.Mutex
+	entries map[string]execCacheEntry
+	vnis    map[string]int
+}
+
+func (m *ExecNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (string, error) {
+	input, err := json.Marshal(netPayload)
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %s", err) // not tested
+	}
+	key := string(input)
+
+	if networkID, ok := m.lookup(key); ok {
+		return networkID, nil
+	}
+
+	output, err := m.Runner.Run(m.Executable, input, m.Timeout)
+	if err != nil {
+		return "", fmt.Errorf("running network mapper: %s", err)
+	}
+
+	var mapping execMapping
+	err = json.Unmarshal(output, &mapping)
+	if err != nil {
+		return "", fmt.Errorf("parsing network mapper output: %s", err)
+	}
+
+	if mapping.NetworkID == "" {
+		return "", errors.New("network mapper returned no network id")
+	}
+
+	if mapping.VNI != nil && (*mapping.VNI < 0 || *mapping.VNI >= store.MaxVNI) {
+		return "", fmt.Errorf("network mapper returned vni out of range: %d", *mapping.VNI)
+	}
+
+	if mapping.VNI != nil {
+		err = m.Registry.Register(mapping.NetworkID, *mapping.VNI)
+		if err != nil {
+			return "", fmt.Errorf("registering vni %d for network %q: %s", *mapping.VNI, mapping.NetworkID, err)
+		}
+	}
+
+	m.insert(key, mapping)
+
+	return mapping.NetworkID, nil
+}
+
+func (m *ExecNetworkMapper) GetVNI(networkID string) (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if vni, ok := m.vnis[networkID]; ok {
+		return vni, nil
+	}
+
+	return HashVNI(networkID), nil
+}
+
+func (m *ExecNetworkMapper) lookup(key string) (string, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	if !m.Clock.Now().Before(entry.expires) {
+		delete(m.entries, key)
+		return "", false
+	}
+
+	return entry.networkID, true
+}
+
+func (m *ExecNetworkMapper) insert(key string, mapping execMapping) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if mapping.VNI != nil {
+		if m.vnis == nil {
+			m.vnis = map[string]int{}
+		}
+		m.vnis[mapping.NetworkID] = *mapping.VNI
+	}
+
+	if m.CacheTTL <= 0 {
+		return
+	}
+
+	now := m.Clock.Now()
+	if m.entries == nil {
+		m.entries = map[string]execCacheEntry{}
+	}
+
+	for k, e := range m.entries {
+		if !now.Before(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+
+	m.entries[key] = execCacheEntry{
+		networkID: mapping.NetworkID,
+		expires:   now.Add(m.CacheTTL),
+	}
+}
@@ -0,0 +1,197 @@
+package network_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecNetworkMapper", func() {
+	var (
+		runner         *fakes.CommandRunner
+		registry       *fakes.VNIRegisterer
+		fakeClock      *fakeclock.FakeClock
+		networkMapper  *network.ExecNetworkMapper
+		networkPayload models.NetworkPayload
+	)
+
+	BeforeEach(func() {
+		runner = &fakes.CommandRunner{}
+		runner.RunReturns([]byte(`{"network_id": "some-network-id", "vni": 42}`), nil)
+		registry = &fakes.VNIRegisterer{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		networkMapper = &network.ExecNetworkMapper{
+			Executable: "/some/mapper",
+			Runner:     runner,
+			Registry:   registry,
+			Timeout:    3 * time.Second,
+			Clock:      fakeClock,
+			CacheTTL:   time.Minute,
+		}
+		networkPayload = models.NetworkPayload{
+			Properties: models.Properties{
+				AppID:   "some-app-guid",
+				SpaceID: "some-space-guid",
+			},
+		}
+	})
+
+	It("runs the executable with the payload on stdin", func() {
+		networkID, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("some-network-id"))
+
+		Expect(runner.RunCallCount()).To(Equal(1))
+		path, stdin, timeout := runner.RunArgsForCall(0)
+		Expect(path).To(Equal("/some/mapper"))
+		Expect(timeout).To(Equal(3 * time.Second))
+
+		var received models.NetworkPayload
+		Expect(json.Unmarshal(stdin, &received)).To(Succeed())
+		Expect(received).To(Equal(networkPayload))
+	})
+
+	It("uses the VNI the executable returned for the network", func() {
+		_, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+
+		vni, err := networkMapper.GetVNI("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(42))
+	})
+
+	It("registers the VNI the executable returned for the network", func() {
+		_, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(registry.RegisterCallCount()).To(Equal(1))
+		networkID, vni := registry.RegisterArgsForCall(0)
+		Expect(networkID).To(Equal("some-network-id"))
+		Expect(vni).To(Equal(42))
+	})
+
+	Context("when the executable has not named a VNI", func() {
+		BeforeEach(func() {
+			runner.RunReturns([]byte(`{"network_id": "some-network-id"}`), nil)
+		})
+
+		It("registers nothing", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.RegisterCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when registering the VNI fails", func() {
+		BeforeEach(func() {
+			registry.RegisterReturns(errors.New("vni conflicts with an existing assignment"))
+		})
+
+		It("returns a meaningful error and caches nothing", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError(`registering vni 42 for network "some-network-id": vni conflicts with an existing assignment`))
+
+			_, err = networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(HaveOccurred())
+			Expect(runner.RunCallCount()).To(Equal(2))
+		})
+	})
+
+	It("hashes the network ID when the executable has not named a VNI", func() {
+		vni, err := networkMapper.GetVNI("some-other-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(network.HashVNI("some-other-network-id")))
+	})
+
+	It("caches decisions per payload until the TTL expires", func() {
+		for i := 0; i < 3; i++ {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(runner.RunCallCount()).To(Equal(1))
+
+		otherPayload := networkPayload
+		otherPayload.Properties.AppID = "some-other-app-guid"
+		_, err := networkMapper.GetNetworkID(otherPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runner.RunCallCount()).To(Equal(2))
+
+		fakeClock.Increment(time.Minute)
+
+		_, err = networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runner.RunCallCount()).To(Equal(3))
+	})
+
+	Context("when the cache TTL is zero", func() {
+		BeforeEach(func() {
+			networkMapper.CacheTTL = 0
+		})
+
+		It("runs the executable every time", func() {
+			for i := 0; i < 3; i++ {
+				_, err := networkMapper.GetNetworkID(networkPayload)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(runner.RunCallCount()).To(Equal(3))
+		})
+	})
+
+	Context("when the executable fails", func() {
+		BeforeEach(func() {
+			runner.RunReturns(nil, errors.New("timed out after 3s"))
+		})
+
+		It("returns a meaningful error and caches nothing", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError("running network mapper: timed out after 3s"))
+
+			_, err = networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(HaveOccurred())
+			Expect(runner.RunCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the output is not JSON", func() {
+		BeforeEach(func() {
+			runner.RunReturns([]byte("nope"), nil)
+		})
+
+		It("returns a meaningful error", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError(HavePrefix("parsing network mapper output: ")))
+		})
+	})
+
+	Context("when the output has no network ID", func() {
+		BeforeEach(func() {
+			runner.RunReturns([]byte(`{"vni": 42}`), nil)
+		})
+
+		It("returns an error", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError("network mapper returned no network id"))
+		})
+	})
+
+	Context("when the VNI is out of range", func() {
+		BeforeEach(func() {
+			runner.RunReturns([]byte(`{"network_id": "some-network-id", "vni": 16777216}`), nil)
+		})
+
+		It("returns an error", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError("network mapper returned vni out of range: 16777216"))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ExecRunner runs an executable with stdin and returns what it wrote to
+// stdout. The executable runs in a process group of its own, and the whole
+// group is killed when it runs for longer than the timeout.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(path string, stdin []byte, timeout time.Duration) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		// a child that outlives the kill would keep the output pipes, and so
+		// Wait, open; the goroutine reaps the process whenever that finishes
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package network_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecRunner", func() {
+	var (
+		dir    string
+		runner network.ExecRunner
+	)
+
+	writeScript := func(body string) string {
+		path := filepath.Join(dir, "mapper")
+		Expect(ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "exec-runner")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("passes stdin to the executable and returns its stdout", func() {
+		path := writeScript("cat")
+
+		output, err := runner.Run(path, []byte(`{"some":"input"}`), time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(MatchJSON(`{"some":"input"}`))
+	})
+
+	Context("when the executable fails", func() {
+		It("returns an error that includes stderr", func() {
+			path := writeScript("echo some-complaint >&2; exit 3")
+
+			_, err := runner.Run(path, nil, time.Second)
+			Expect(err).To(MatchError("exit status 3: some-complaint"))
+		})
+	})
+
+	Context("when the executable runs for too long", func() {
+		It("kills it and returns an error", func() {
+			path := writeScript("exec sleep 10")
+
+			start := time.Now()
+			_, err := runner.Run(path, nil, 100*time.Millisecond)
+			Expect(err).To(MatchError("timed out after 100ms"))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+	})
+
+	Context("when the executable leaves a child running past the timeout", func() {
+		It("kills the child too and does not wait for its output", func() {
+			path := writeScript("sleep 10; echo done")
+
+			start := time.Now()
+			_, err := runner.Run(path, nil, 100*time.Millisecond)
+			Expect(err).To(MatchError("timed out after 100ms"))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+	})
+
+	Context("when the executable does not exist", func() {
+		It("returns an error", func() {
+			_, err := runner.Run(filepath.Join(dir, "missing"), nil, time.Second)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -18,12 +18,7 @@ type FixedNetworkMapper struct {
 }
 
 func (*FixedNetworkMapper) GetVNI(networkID string) (int, error) {
-	digest := sha1.Sum([]byte(networkID))
-	digest[3] = 0
-
-	vni := binary.LittleEndian.Uint32(digest[:4])
-
-	return int(vni), nil
+	return HashVNI(networkID), nil
 }
 
 func (m *FixedNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (string, error) {
@@ -32,3 +27,12 @@ func (m *FixedNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (str
 	}
 	return netPayload.Properties.SpaceID, nil
 }
+
+// HashVNI derives a 24 bit VNI from the network ID. Different networks can
+// hash to the same VNI, so it is only a hint for a RegistryNetworkMapper.
+func HashVNI(networkID string) int {
+	digest := sha1.Sum([]byte(networkID))
+	digest[3] = 0
+
+	return int(binary.LittleEndian.Uint32(digest[:4]))
+}
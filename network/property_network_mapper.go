@@ -0,0 +1,45 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+// Payload properties that a PropertyNetworkMapper can map on.
+const (
+	AppIDProperty   = "app_id"
+	SpaceIDProperty = "space_id"
+	OrgIDProperty   = "org_id"
+)
+
+// PropertyNetworkMapper puts every container whose payload has the same value
+// for Property on the same network. Payloads without a value are put on
+// DefaultNetworkID.
+type PropertyNetworkMapper struct {
+	Property         string
+	DefaultNetworkID string
+}
+
+func (*PropertyNetworkMapper) GetVNI(networkID string) (int, error) {
+	return HashVNI(networkID), nil
+}
+
+func (m *PropertyNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (string, error) {
+	var networkID string
+	switch m.Property {
+	case AppIDProperty:
+		networkID = netPayload.Properties.AppID
+	case SpaceIDProperty:
+		networkID = netPayload.Properties.SpaceID
+	case OrgIDProperty:
+		networkID = netPayload.Properties.OrgID
+	default:
+		return "", fmt.Errorf("unknown property %q", m.Property)
+	}
+
+	if networkID == "" {
+		return m.DefaultNetworkID, nil
+	}
+	return networkID, nil
+}
@@ -0,0 +1,71 @@
+package network_test
+
+import (
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PropertyNetworkMapper", func() {
+	var (
+		networkMapper  *network.PropertyNetworkMapper
+		networkPayload models.NetworkPayload
+	)
+
+	BeforeEach(func() {
+		networkMapper = &network.PropertyNetworkMapper{DefaultNetworkID: "some-default-network"}
+		networkPayload = models.NetworkPayload{
+			Properties: models.Properties{
+				AppID:   "some-app-guid",
+				SpaceID: "some-space-guid",
+				OrgID:   "some-org-guid",
+			},
+		}
+	})
+
+	DescribeTable("GetNetworkID",
+		func(property, expectedNetworkID string) {
+			networkMapper.Property = property
+
+			networkID, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkID).To(Equal(expectedNetworkID))
+		},
+		Entry("per app", network.AppIDProperty, "some-app-guid"),
+		Entry("per space", network.SpaceIDProperty, "some-space-guid"),
+		Entry("per org", network.OrgIDProperty, "some-org-guid"),
+	)
+
+	Context("when the payload does not have the property", func() {
+		BeforeEach(func() {
+			networkMapper.Property = network.OrgIDProperty
+			networkPayload.Properties.OrgID = ""
+		})
+
+		It("uses the default network", func() {
+			networkID, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkID).To(Equal("some-default-network"))
+		})
+	})
+
+	Context("when the property is unknown", func() {
+		BeforeEach(func() {
+			networkMapper.Property = "cell_id"
+		})
+
+		It("returns an error", func() {
+			_, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).To(MatchError(`unknown property "cell_id"`))
+		})
+	})
+
+	It("hashes the network ID into a VNI", func() {
+		vni, err := networkMapper.GetVNI("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(network.HashVNI("some-network-id")))
+	})
+})
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter -o ../fakes/vni_registry.go --fake-name VNIRegistry . vniRegistry
+type vniRegistry interface {
+	Assign(networkID string, hint int) (int, error)
+}
+
+// RegistryNetworkMapper maps payloads to networks with the wrapped Mapper and
+// uses the VNI the Mapper computes only as a hint: the VNI that is actually
+// handed out comes from the Registry, which never gives two networks the same
+// one.
+type RegistryNetworkMapper struct {
+	Logger   lager.Logger
+	Mapper   NetworkMapper
+	Registry vniRegistry
+}
+
+func (m *RegistryNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (string, error) {
+	return m.Mapper.GetNetworkID(netPayload)
+}
+
+func (m *RegistryNetworkMapper) GetVNI(networkID string) (int, error) {
+	hint, err := m.Mapper.GetVNI(networkID)
+	if err != nil {
+		return 0, err
+	}
+
+	vni, err := m.Registry.Assign(networkID, hint)
+	if err != nil {
+		return 0, err
+	}
+
+	if vni != hint {
+		m.Logger.Info("vni-collision", lager.Data{
+			"network_id": networkID,
+			"hint":       hint,
+			"vni":        vni,
+		})
+	}
+
+	return vni, nil
+}
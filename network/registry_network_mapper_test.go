@@ -0,0 +1,101 @@
+package network_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("RegistryNetworkMapper", func() {
+	var (
+		logger        *lagertest.TestLogger
+		mapper        *fakes.NetworkMapper
+		registry      *fakes.VNIRegistry
+		networkMapper *network.RegistryNetworkMapper
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		mapper = &fakes.NetworkMapper{}
+		registry = &fakes.VNIRegistry{}
+
+		mapper.GetNetworkIDReturns("some-network-id", nil)
+		mapper.GetVNIReturns(42, nil)
+		registry.AssignReturns(42, nil)
+
+		networkMapper = &network.RegistryNetworkMapper{
+			Logger:   logger,
+			Mapper:   mapper,
+			Registry: registry,
+		}
+	})
+
+	Describe("GetNetworkID", func() {
+		It("delegates to the wrapped mapper", func() {
+			payload := models.NetworkPayload{Properties: models.Properties{SpaceID: "some-space"}}
+
+			networkID, err := networkMapper.GetNetworkID(payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkID).To(Equal("some-network-id"))
+
+			Expect(mapper.GetNetworkIDArgsForCall(0)).To(Equal(payload))
+		})
+	})
+
+	Describe("GetVNI", func() {
+		It("assigns the VNI from the registry using the mapped VNI as a hint", func() {
+			vni, err := networkMapper.GetVNI("some-network-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vni).To(Equal(42))
+
+			Expect(mapper.GetVNIArgsForCall(0)).To(Equal("some-network-id"))
+			networkID, hint := registry.AssignArgsForCall(0)
+			Expect(networkID).To(Equal("some-network-id"))
+			Expect(hint).To(Equal(42))
+		})
+
+		Context("when the hint collides with another network", func() {
+			BeforeEach(func() {
+				registry.AssignReturns(43, nil)
+			})
+
+			It("returns the registered VNI and logs the collision", func() {
+				vni, err := networkMapper.GetVNI("some-network-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vni).To(Equal(43))
+
+				Expect(logger).To(gbytes.Say("vni-collision.*some-network-id"))
+			})
+		})
+
+		Context("when the wrapped mapper fails", func() {
+			BeforeEach(func() {
+				mapper.GetVNIReturns(0, errors.New("pear"))
+			})
+
+			It("returns the error without consulting the registry", func() {
+				_, err := networkMapper.GetVNI("some-network-id")
+				Expect(err).To(MatchError("pear"))
+				Expect(registry.AssignCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the registry fails", func() {
+			BeforeEach(func() {
+				registry.AssignReturns(0, errors.New("plum"))
+			})
+
+			It("returns the error", func() {
+				_, err := networkMapper.GetVNI("some-network-id")
+				Expect(err).To(MatchError("plum"))
+			})
+		})
+	})
+})
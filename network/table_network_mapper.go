@@ -0,0 +1,66 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+)
+
+//go:generate counterfeiter -o ../fakes/mapping_store.go --fake-name MappingStore . mappingStore
+type mappingStore interface {
+	Lookup(kind, key string) (string, error)
+}
+
+// TableNetworkMapper maps apps and spaces to networks through an explicit
+// table. An app mapping wins over a mapping of its space; the Apps and Spaces
+// loaded from config win over the rows in Store, which may be nil. Payloads
+// that are not in the table are put on DefaultNetworkID.
+type TableNetworkMapper struct {
+	Apps             map[string]string
+	Spaces           map[string]string
+	Store            mappingStore
+	DefaultNetworkID string
+}
+
+func (*TableNetworkMapper) GetVNI(networkID string) (int, error) {
+	return HashVNI(networkID), nil
+}
+
+func (m *TableNetworkMapper) GetNetworkID(netPayload models.NetworkPayload) (string, error) {
+	networkID, err := m.lookup(store.AppMapping, m.Apps, netPayload.Properties.AppID)
+	if err != nil || networkID != "" {
+		return networkID, err
+	}
+
+	networkID, err = m.lookup(store.SpaceMapping, m.Spaces, netPayload.Properties.SpaceID)
+	if err != nil || networkID != "" {
+		return networkID, err
+	}
+
+	return m.DefaultNetworkID, nil
+}
+
+func (m *TableNetworkMapper) lookup(kind string, table map[string]string, key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	if networkID, ok := table[key]; ok {
+		return networkID, nil
+	}
+
+	if m.Store == nil {
+		return "", nil
+	}
+
+	networkID, err := m.Store.Lookup(kind, key)
+	if err == store.RecordNotFoundError {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("looking up %s mapping: %s", kind, err)
+	}
+
+	return networkID, nil
+}
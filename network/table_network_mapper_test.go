@@ -0,0 +1,107 @@
+package network_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/network"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TableNetworkMapper", func() {
+	var (
+		mappingStore   *fakes.MappingStore
+		networkMapper  *network.TableNetworkMapper
+		networkPayload models.NetworkPayload
+	)
+
+	BeforeEach(func() {
+		mappingStore = &fakes.MappingStore{}
+		mappingStore.LookupReturns("", store.RecordNotFoundError)
+
+		networkMapper = &network.TableNetworkMapper{
+			Apps:             map[string]string{"some-app-guid": "app-network"},
+			Spaces:           map[string]string{"some-space-guid": "space-network"},
+			DefaultNetworkID: "some-default-network",
+		}
+		networkPayload = models.NetworkPayload{
+			Properties: models.Properties{
+				AppID:   "some-app-guid",
+				SpaceID: "some-space-guid",
+			},
+		}
+	})
+
+	It("prefers the mapping of the app", func() {
+		networkID, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("app-network"))
+	})
+
+	It("falls back to the mapping of the space", func() {
+		networkPayload.Properties.AppID = "some-other-app-guid"
+
+		networkID, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("space-network"))
+	})
+
+	It("uses the default network when neither is mapped", func() {
+		networkPayload.Properties.AppID = "some-other-app-guid"
+		networkPayload.Properties.SpaceID = "some-other-space-guid"
+
+		networkID, err := networkMapper.GetNetworkID(networkPayload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("some-default-network"))
+	})
+
+	Context("when the table is in the datastore", func() {
+		BeforeEach(func() {
+			networkMapper.Store = mappingStore
+			networkPayload.Properties.AppID = "some-other-app-guid"
+			networkPayload.Properties.SpaceID = "some-other-space-guid"
+		})
+
+		It("looks up the app and then the space", func() {
+			mappingStore.LookupStub = func(kind, key string) (string, error) {
+				if kind == store.SpaceMapping && key == "some-other-space-guid" {
+					return "stored-space-network", nil
+				}
+				return "", store.RecordNotFoundError
+			}
+
+			networkID, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkID).To(Equal("stored-space-network"))
+
+			Expect(mappingStore.LookupCallCount()).To(Equal(2))
+			kind, key := mappingStore.LookupArgsForCall(0)
+			Expect(kind).To(Equal(store.AppMapping))
+			Expect(key).To(Equal("some-other-app-guid"))
+		})
+
+		It("does not consult the datastore for mappings in config", func() {
+			networkPayload.Properties.AppID = "some-app-guid"
+
+			networkID, err := networkMapper.GetNetworkID(networkPayload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkID).To(Equal("app-network"))
+			Expect(mappingStore.LookupCallCount()).To(Equal(0))
+		})
+
+		Context("when the lookup fails", func() {
+			BeforeEach(func() {
+				mappingStore.LookupReturns("", errors.New("papaya"))
+			})
+
+			It("returns a meaningful error", func() {
+				_, err := networkMapper.GetNetworkID(networkPayload)
+				Expect(err).To(MatchError("looking up app mapping: papaya"))
+			})
+		})
+	})
+})
@@ -0,0 +1,236 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+	"github.com/pivotal-golang/lager"
+)
+
+type policyLister interface {
+	All() ([]models.Policy, error)
+}
+
+type containerLister interface {
+	All() ([]models.Container, error)
+}
+
+//go:generate counterfeiter -o ../fakes/egress_link_lister.go --fake-name EgressLinkLister . egressLinkLister
+type egressLinkLister interface {
+	List() ([]models.EgressLink, error)
+}
+
+//go:generate counterfeiter -o ../fakes/route_programmer.go --fake-name RouteProgrammer . routeProgrammer
+type routeProgrammer interface {
+	Program(routes []Route, subnets []net.IPNet) error
+}
+
+//go:generate counterfeiter -o ../fakes/policy_sandboxes.go --fake-name PolicySandboxes . sandboxes
+type sandboxes interface {
+	ForEach(sandbox.SandboxCallback) error
+	Get(sandboxName string) (sandbox.Sandbox, error)
+}
+
+//go:generate counterfeiter -o ../fakes/rule_writer.go --fake-name RuleWriter . ruleWriter
+type ruleWriter interface {
+	Write(chain string, rules [][]string) error
+	WriteNAT(chain string, rules [][]string) error
+}
+
+// Enforcer programs the policy chains of the sandboxes from the policies and
+// the containers of every network. It is handed every container event so
+// that rules follow containers as they come and go; only the sandbox of the
+// container's network is reprogrammed then, unless the container's app has
+// policies with apps on other networks. Writer6 programs the IPv6 rules of
+// sandboxes whose containers have IPv6 addresses. When Routes is set, the
+// host routes that carry allowed traffic between networks are programmed
+// too, through the egress links that Links lists.
+type Enforcer struct {
+	Logger     lager.Logger
+	HostIP     string
+	Policies   policyLister
+	Containers containerLister
+	Sandboxes  sandboxes
+	Writer     ruleWriter
+	Writer6    ruleWriter
+	Links      egressLinkLister
+	Routes     routeProgrammer
+
+	lock sync.Mutex
+}
+
+// Sync reprograms every sandbox and the host routes; a sandbox that cannot
+// be programmed is logged and skipped. Syncs are serialized so that an
+// older view of the datastore never overwrites a newer one.
+func (e *Enforcer) Sync() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	policies, containers, err := e.list()
+	if err != nil {
+		return err
+	}
+
+	return e.sync(policies, containers)
+}
+
+// Distribute reprograms the sandbox of the network the container is
+// attached to, if this host has one. When the container's app has policies
+// with apps on other networks, the sandboxes and routes of the other end
+// change as well, so everything is reprogrammed.
+func (e *Enforcer) Distribute(event models.ContainerEvent) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	policies, containers, err := e.list()
+	if err != nil {
+		return err
+	}
+
+	if crossesNetworks(event.Container, policies, containers) {
+		return e.sync(policies, containers)
+	}
+
+	sandboxName := event.Container.SandboxName
+	sbox, err := e.Sandboxes.Get(sandboxName)
+	if err == sandbox.NotFoundError {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get sandbox: %s", err)
+	}
+
+	sbox.Lock()
+	defer sbox.Unlock()
+
+	return e.program(sbox.Namespace(), sandboxName, policies, containers)
+}
+
+func (e *Enforcer) list() ([]models.Policy, []models.Container, error) {
+	policies, err := e.Policies.All()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing policies: %s", err)
+	}
+
+	containers, err := e.Containers.All()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing containers: %s", err)
+	}
+
+	return policies, containers, nil
+}
+
+func (e *Enforcer) sync(policies []models.Policy, containers []models.Container) error {
+	err := e.Sandboxes.ForEach(&sandboxSync{
+		logger:     e.Logger.Session("sync-policies"),
+		enforcer:   e,
+		policies:   policies,
+		containers: containers,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.Routes == nil {
+		return nil
+	}
+
+	links, err := e.Links.List()
+	if err != nil {
+		return fmt.Errorf("listing egress links: %s", err)
+	}
+
+	err = e.Routes.Program(Routes(e.HostIP, links, policies, containers), subnets(containers))
+	if err != nil {
+		return fmt.Errorf("programming routes: %s", err)
+	}
+
+	return nil
+}
+
+func (e *Enforcer) program(ns namespace.Namespace, sandboxName string, policies []models.Policy, containers []models.Container) error {
+	err := ns.Execute(func(*os.File) error {
+		err := e.Writer.Write(ChainName, Rules(sandboxName, policies, containers))
+		if err != nil {
+			return err
+		}
+
+		err = e.Writer.WriteNAT(NATChainName, NATRules(sandboxName, policies, containers))
+		if err != nil {
+			return fmt.Errorf("nat: %s", err)
+		}
+
+		if !dualStack(sandboxName, containers) {
+			return nil
+		}
+
+		err = e.Writer6.Write(ChainName, Rules6(sandboxName, policies, containers))
+		if err != nil {
+			return fmt.Errorf("ipv6: %s", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("writing rules: %s", err)
+	}
+
+	return nil
+}
+
+// crossesNetworks reports whether a policy connects the container's app
+// with an app that has containers on another network.
+func crossesNetworks(container models.Container, policies []models.Policy, containers []models.Container) bool {
+	for _, p := range policies {
+		var other string
+		switch container.App {
+		case p.Source:
+			other = p.Destination
+		case p.Destination:
+			other = p.Source
+		default:
+			continue
+		}
+
+		for _, c := range containers {
+			if c.App == other && c.SandboxName != container.SandboxName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func dualStack(sandboxName string, containers []models.Container) bool {
+	for _, c := range containers {
+		if c.SandboxName == sandboxName && c.IP6 != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type sandboxSync struct {
+	logger     lager.Logger
+	enforcer   *Enforcer
+	policies   []models.Policy
+	containers []models.Container
+}
+
+func (s *sandboxSync) Callback(ns namespace.Namespace) error {
+	sandboxName := path.Base(ns.Name())
+
+	err := s.enforcer.program(ns, sandboxName, s.policies, s.containers)
+	if err != nil {
+		s.logger.Error("program-sandbox-failed", err, lager.Data{"sandbox": sandboxName})
+	}
+
+	return nil
+}
@@ -0,0 +1,344 @@
+package policy_test
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Enforcer", func() {
+	var (
+		logger     *lagertest.TestLogger
+		policies   *fakes.PolicyStore
+		datastore  *fakes.Store
+		sandboxes  *fakes.PolicySandboxes
+		writer     *fakes.RuleWriter
+		writer6    *fakes.RuleWriter
+		links      *fakes.EgressLinkLister
+		routes     *fakes.RouteProgrammer
+		namespaces []*fakes.Namespace
+		enforcer   *policy.Enforcer
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		policies = &fakes.PolicyStore{}
+		datastore = &fakes.Store{}
+		sandboxes = &fakes.PolicySandboxes{}
+		writer = &fakes.RuleWriter{}
+		writer6 = &fakes.RuleWriter{}
+		links = &fakes.EgressLinkLister{}
+		routes = &fakes.RouteProgrammer{}
+
+		namespaces = nil
+		for _, name := range []string{"/sandboxes/vni-1", "/sandboxes/vni-2"} {
+			ns := &fakes.Namespace{}
+			ns.NameReturns(name)
+			ns.ExecuteStub = func(callback func(*os.File) error) error {
+				return callback(nil)
+			}
+			namespaces = append(namespaces, ns)
+		}
+
+		sandboxes.ForEachStub = func(callback sandbox.SandboxCallback) error {
+			for _, ns := range namespaces {
+				err := callback.Callback(namespace.Namespace(ns))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		policies.AllReturns([]models.Policy{
+			{ID: "some-policy", Source: "frontend", Destination: "backend"},
+		}, nil)
+		datastore.AllReturns([]models.Container{
+			{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1"},
+			{ID: "frontend-1", IP: "192.168.1.3", App: "frontend", SandboxName: "vni-1"},
+			{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2"},
+		}, nil)
+
+		enforcer = &policy.Enforcer{
+			Logger:     logger,
+			Policies:   policies,
+			Containers: datastore,
+			Sandboxes:  sandboxes,
+			Writer:     writer,
+			Writer6:    writer6,
+			Links:      links,
+			Routes:     routes,
+		}
+	})
+
+	It("writes the rules of each sandbox from inside its namespace", func() {
+		Expect(enforcer.Sync()).To(Succeed())
+
+		for _, ns := range namespaces {
+			Expect(ns.ExecuteCallCount()).To(Equal(1))
+		}
+
+		Expect(policies.AllCallCount()).To(Equal(1))
+		Expect(datastore.AllCallCount()).To(Equal(1))
+
+		Expect(writer.WriteCallCount()).To(Equal(2))
+
+		chain, rules := writer.WriteArgsForCall(0)
+		Expect(chain).To(Equal(policy.ChainName))
+		Expect(rules).To(ContainElement([]string{"-s", "192.168.1.3/32", "-d", "192.168.1.2/32", "-j", "ACCEPT"}))
+
+		chain, rules = writer.WriteArgsForCall(1)
+		Expect(chain).To(Equal(policy.ChainName))
+		Expect(rules).To(BeEmpty())
+
+		Expect(writer6.WriteCallCount()).To(Equal(0))
+	})
+
+	It("writes the nat chain of each sandbox", func() {
+		Expect(enforcer.Sync()).To(Succeed())
+
+		Expect(writer.WriteNATCallCount()).To(Equal(2))
+		chain, rules := writer.WriteNATArgsForCall(0)
+		Expect(chain).To(Equal(policy.NATChainName))
+		Expect(rules).To(BeEmpty())
+	})
+
+	It("programs the host routes", func() {
+		Expect(enforcer.Sync()).To(Succeed())
+
+		Expect(links.ListCallCount()).To(Equal(1))
+		Expect(routes.ProgramCallCount()).To(Equal(1))
+	})
+
+	Context("when a policy connects containers on different networks", func() {
+		var link models.EgressLink
+
+		BeforeEach(func() {
+			enforcer.HostIP = "10.0.0.1"
+
+			datastore.AllReturns([]models.Container{
+				{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1", HostIP: "10.0.0.1", IPAMResult: ipamResult("192.168.1.2/24")},
+				{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2", HostIP: "10.0.0.2", IPAMResult: ipamResult("192.168.2.2/24")},
+			}, nil)
+
+			link = models.EgressLink{SandboxName: "vni-1", HostInterface: "egh1", SandboxAddress: net.ParseIP("169.254.0.2")}
+			links.ListReturns([]models.EgressLink{link}, nil)
+		})
+
+		It("accepts the source on the other network", func() {
+			Expect(enforcer.Sync()).To(Succeed())
+
+			_, rules := writer.WriteArgsForCall(0)
+			Expect(rules).To(ContainElement([]string{"-s", "192.168.2.2/32", "-d", "192.168.1.2/32", "-j", "ACCEPT"}))
+		})
+
+		It("keeps the traffic to the peer from being masqueraded", func() {
+			Expect(enforcer.Sync()).To(Succeed())
+
+			_, rules := writer.WriteNATArgsForCall(0)
+			Expect(rules).To(Equal([][]string{{"-s", "192.168.1.2/32", "-d", "192.168.2.2/32", "-j", "ACCEPT"}}))
+		})
+
+		It("routes to the local destination through its egress link and to the source through its host", func() {
+			Expect(enforcer.Sync()).To(Succeed())
+
+			programmed, subnets := routes.ProgramArgsForCall(0)
+			Expect(programmed).To(Equal([]policy.Route{
+				{Destination: net.ParseIP("192.168.1.2").To4(), Gateway: link.SandboxAddress, Interface: "egh1"},
+				{Destination: net.ParseIP("192.168.2.2").To4(), Gateway: net.ParseIP("10.0.0.2")},
+			}))
+			Expect(subnets).To(HaveLen(2))
+		})
+
+		It("reprograms everything when a container of either app comes or goes", func() {
+			err := enforcer.Distribute(models.ContainerEvent{
+				Action:    models.ContainerDeleted,
+				Container: models.Container{ID: "frontend-3", App: "frontend", SandboxName: "vni-2"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sandboxes.GetCallCount()).To(Equal(0))
+			Expect(sandboxes.ForEachCallCount()).To(Equal(1))
+			Expect(routes.ProgramCallCount()).To(Equal(1))
+		})
+
+		Context("when the egress links cannot be listed", func() {
+			BeforeEach(func() {
+				links.ListReturns(nil, errors.New("potato"))
+			})
+
+			It("returns the error", func() {
+				Expect(enforcer.Sync()).To(MatchError("listing egress links: potato"))
+			})
+		})
+
+		Context("when the routes cannot be programmed", func() {
+			BeforeEach(func() {
+				routes.ProgramReturns(errors.New("potato"))
+			})
+
+			It("returns the error", func() {
+				Expect(enforcer.Sync()).To(MatchError("programming routes: potato"))
+			})
+		})
+	})
+
+	Context("when the containers of a sandbox have IPv6 addresses", func() {
+		BeforeEach(func() {
+			namespaces = namespaces[:1]
+			datastore.AllReturns([]models.Container{
+				{ID: "backend-1", IP: "192.168.1.2", IP6: "fd00::2", App: "backend", SandboxName: "vni-1"},
+				{ID: "frontend-1", IP: "192.168.1.3", IP6: "fd00::3", App: "frontend", SandboxName: "vni-1"},
+			}, nil)
+		})
+
+		It("also writes the IPv6 rules", func() {
+			Expect(enforcer.Sync()).To(Succeed())
+
+			Expect(writer.WriteCallCount()).To(Equal(1))
+			Expect(writer6.WriteCallCount()).To(Equal(1))
+
+			chain, rules := writer6.WriteArgsForCall(0)
+			Expect(chain).To(Equal(policy.ChainName))
+			Expect(rules).To(ContainElement([]string{"-s", "fd00::3/128", "-d", "fd00::2/128", "-j", "ACCEPT"}))
+		})
+
+		Context("when the IPv6 rules cannot be written", func() {
+			BeforeEach(func() {
+				writer6.WriteReturns(errors.New("potato"))
+			})
+
+			It("logs the error", func() {
+				Expect(enforcer.Sync()).To(Succeed())
+				Expect(logger).To(gbytes.Say("program-sandbox-failed.*writing rules: ipv6: potato.*vni-1"))
+			})
+		})
+	})
+
+	Context("when it is handed a container event", func() {
+		var (
+			sbox  *fakes.Sandbox
+			event models.ContainerEvent
+		)
+
+		BeforeEach(func() {
+			sbox = &fakes.Sandbox{}
+			sbox.NamespaceReturns(namespaces[0])
+			sandboxes.GetReturns(sbox, nil)
+
+			event = models.ContainerEvent{
+				Action:    models.ContainerCreated,
+				Container: models.Container{ID: "frontend-1", SandboxName: "vni-1"},
+			}
+		})
+
+		It("only reprograms the sandbox of the container's network", func() {
+			Expect(enforcer.Distribute(event)).To(Succeed())
+
+			Expect(sandboxes.ForEachCallCount()).To(Equal(0))
+			Expect(sandboxes.GetCallCount()).To(Equal(1))
+			Expect(sandboxes.GetArgsForCall(0)).To(Equal("vni-1"))
+
+			Expect(routes.ProgramCallCount()).To(Equal(0))
+
+			Expect(namespaces[0].ExecuteCallCount()).To(Equal(1))
+			Expect(writer.WriteCallCount()).To(Equal(1))
+			_, rules := writer.WriteArgsForCall(0)
+			Expect(rules).To(ContainElement([]string{"-s", "192.168.1.3/32", "-d", "192.168.1.2/32", "-j", "ACCEPT"}))
+		})
+
+		It("holds the sandbox lock while it writes", func() {
+			writer.WriteStub = func(string, [][]string) error {
+				Expect(sbox.LockCallCount()).To(Equal(1))
+				Expect(sbox.UnlockCallCount()).To(Equal(0))
+				return nil
+			}
+
+			Expect(enforcer.Distribute(event)).To(Succeed())
+			Expect(sbox.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when this host has no sandbox for the network", func() {
+			BeforeEach(func() {
+				sandboxes.GetReturns(nil, sandbox.NotFoundError)
+			})
+
+			It("writes nothing", func() {
+				Expect(enforcer.Distribute(event)).To(Succeed())
+
+				Expect(writer.WriteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when getting the sandbox fails", func() {
+			BeforeEach(func() {
+				sandboxes.GetReturns(nil, errors.New("potato"))
+			})
+
+			It("returns the error", func() {
+				Expect(enforcer.Distribute(event)).To(MatchError("get sandbox: potato"))
+			})
+		})
+
+		Context("when the rules cannot be written", func() {
+			BeforeEach(func() {
+				writer.WriteReturns(errors.New("potato"))
+			})
+
+			It("returns the error", func() {
+				Expect(enforcer.Distribute(event)).To(MatchError("writing rules: potato"))
+			})
+		})
+	})
+
+	Context("when a sandbox cannot be programmed", func() {
+		BeforeEach(func() {
+			writer.WriteStub = func(chain string, rules [][]string) error {
+				if writer.WriteCallCount() == 1 {
+					return errors.New("potato")
+				}
+				return nil
+			}
+		})
+
+		It("logs the error and carries on with the other sandboxes", func() {
+			Expect(enforcer.Sync()).To(Succeed())
+
+			Expect(writer.WriteCallCount()).To(Equal(2))
+			Expect(logger).To(gbytes.Say("program-sandbox-failed.*potato.*vni-1"))
+		})
+	})
+
+	Context("when the policies cannot be listed", func() {
+		BeforeEach(func() {
+			policies.AllReturns(nil, errors.New("potato"))
+		})
+
+		It("returns the error and writes nothing", func() {
+			Expect(enforcer.Sync()).To(MatchError("listing policies: potato"))
+			Expect(sandboxes.ForEachCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the containers cannot be listed", func() {
+		BeforeEach(func() {
+			datastore.AllReturns(nil, errors.New("potato"))
+		})
+
+		It("returns the error and writes nothing", func() {
+			Expect(enforcer.Sync()).To(MatchError("listing containers: potato"))
+			Expect(sandboxes.ForEachCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/firewall"
+)
+
+const (
+	bridgeNetfilter  = "/proc/sys/net/bridge/bridge-nf-call-iptables"
+	bridgeNetfilter6 = "/proc/sys/net/bridge/bridge-nf-call-ip6tables"
+)
+
+// IPTables programs the filter and nat tables of the network namespace it
+// is called from, with ip6tables when IPv6 is set. Traffic between containers is
+// bridged inside the sandbox, so bridge netfilter is enabled whenever there
+// are rules to enforce; that needs the br_netfilter module to be loaded on
+// the host.
+type IPTables struct {
+	IPv6 bool
+}
+
+// Write atomically replaces the rules of the chain and makes sure FORWARD
+// jumps to it.
+func (t IPTables) Write(chain string, rules [][]string) error {
+	if len(rules) > 0 {
+		sysctl := bridgeNetfilter
+		if t.IPv6 {
+			sysctl = bridgeNetfilter6
+		}
+
+		err := ioutil.WriteFile(sysctl, []byte("1"), 0644)
+		if err != nil {
+			return fmt.Errorf("enabling bridge netfilter: %s", err)
+		}
+	}
+
+	return firewall.IPTables{IPv6: t.IPv6}.ReplaceChain("filter", chain, "FORWARD", rules)
+}
+
+// WriteNAT atomically replaces the rules of the nat chain and makes sure
+// POSTROUTING jumps to it before any other rule.
+func (t IPTables) WriteNAT(chain string, rules [][]string) error {
+	return firewall.IPTables{IPv6: t.IPv6}.ReplaceChain("nat", chain, "POSTROUTING", rules)
+}
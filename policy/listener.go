@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/lager"
+)
+
+type pqListener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+// Listener resyncs the sandboxes whenever a policy is created or deleted
+// through any daemon sharing the datastore. Notifications that arrive while
+// a sync runs are folded into the next one. It is an ifrit runner.
+type Listener struct {
+	Logger     lager.Logger
+	PQListener pqListener
+	Syncer     syncer
+}
+
+func (l *Listener) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := l.Logger.Session("policy-event-listener")
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	err := l.PQListener.Listen(store.PolicyEventChannel)
+	if err != nil {
+		return fmt.Errorf("listen: %s", err)
+	}
+
+	close(ready)
+
+	notifications := l.PQListener.NotificationChannel()
+	for {
+		select {
+		case <-signals:
+			return l.PQListener.Close()
+
+		case notification, ok := <-notifications:
+			if !ok {
+				return fmt.Errorf("notification channel closed")
+			}
+
+			// a nil notification means the connection was re-established and
+			// changes may have been missed, so sync anyway
+			if notification == nil {
+				logger.Info("reconnected")
+			}
+
+			if !drain(notifications) {
+				return fmt.Errorf("notification channel closed")
+			}
+
+			err := l.Syncer.Sync()
+			if err != nil {
+				logger.Error("sync-failed", err)
+			}
+		}
+	}
+}
+
+// drain discards the notifications that are already pending, since a
+// single sync covers them all. It returns false if the channel was closed.
+func drain(notifications <-chan *pq.Notification) bool {
+	for {
+		select {
+		case _, ok := <-notifications:
+			if !ok {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
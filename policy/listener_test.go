@@ -0,0 +1,129 @@
+package policy_test
+
+import (
+	"errors"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Listener", func() {
+	var (
+		logger        *lagertest.TestLogger
+		pqListener    *fakes.PQListener
+		syncer        *fakes.Syncer
+		notifications chan *pq.Notification
+		listener      *policy.Listener
+		process       ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		pqListener = &fakes.PQListener{}
+		syncer = &fakes.Syncer{}
+
+		notifications = make(chan *pq.Notification)
+		pqListener.NotificationChannelReturns(notifications)
+
+		listener = &policy.Listener{
+			Logger:     logger,
+			PQListener: pqListener,
+			Syncer:     syncer,
+		}
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Background(listener)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("listens on the policy event channel before becoming ready", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		Expect(pqListener.ListenCallCount()).To(Equal(1))
+		Expect(pqListener.ListenArgsForCall(0)).To(Equal(store.PolicyEventChannel))
+	})
+
+	It("syncs when it is notified of a policy change", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		notifications <- &pq.Notification{
+			Channel: store.PolicyEventChannel,
+			Extra:   `{"action": "create", "policy": {"id": "some-policy"}}`,
+		}
+		Eventually(syncer.SyncCallCount).Should(Equal(1))
+
+		notifications <- &pq.Notification{
+			Channel: store.PolicyEventChannel,
+			Extra:   `{"action": "delete", "policy": {"id": "some-policy"}}`,
+		}
+		Eventually(syncer.SyncCallCount).Should(Equal(2))
+	})
+
+	It("closes the pq listener when signaled", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(pqListener.CloseCallCount()).To(Equal(1))
+	})
+
+	Context("when the connection is re-established", func() {
+		It("syncs in case changes were missed", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			notifications <- nil
+			Eventually(logger).Should(gbytes.Say("reconnected"))
+			Eventually(syncer.SyncCallCount).Should(Equal(1))
+		})
+	})
+
+	Context("when syncing fails", func() {
+		BeforeEach(func() {
+			syncer.SyncReturns(errors.New("potato"))
+		})
+
+		It("logs the error and keeps listening", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			notifications <- &pq.Notification{Extra: `{"action": "create"}`}
+			Eventually(logger).Should(gbytes.Say("sync-failed.*potato"))
+
+			notifications <- &pq.Notification{Extra: `{"action": "create"}`}
+			Eventually(syncer.SyncCallCount).Should(Equal(2))
+		})
+	})
+
+	Context("when listening fails", func() {
+		BeforeEach(func() {
+			pqListener.ListenReturns(errors.New("kiwi"))
+		})
+
+		It("exits with a meaningful error without becoming ready", func() {
+			Eventually(process.Wait()).Should(Receive(MatchError("listen: kiwi")))
+			Expect(process.Ready()).NotTo(BeClosed())
+		})
+	})
+
+	Context("when the notification channel is closed", func() {
+		It("exits with an error", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			close(notifications)
+			Eventually(process.Wait()).Should(Receive(MatchError("notification channel closed")))
+		})
+	})
+})
@@ -0,0 +1,266 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/vishvananda/netlink"
+)
+
+// Route is a host route to a single container. Traffic that a policy allows
+// between networks leaves the source sandbox through its egress link, and
+// the hosts route it to the destination: through the destination sandbox's
+// egress link when it is on this host, or to the host it is on otherwise.
+type Route struct {
+	Destination net.IP
+	Gateway     net.IP
+	Interface   string
+}
+
+// Routes returns the routes this host needs for the policies that allow
+// traffic between networks: one to each container on this host that has a
+// peer on another network, and one to each of those peers. Containers in a
+// sandbox without an egress link on this host cannot be routed to.
+func Routes(hostIP string, links []models.EgressLink, policies []models.Policy, containers []models.Container) []Route {
+	containers = append([]models.Container{}, containers...)
+	sort.Sort(byID(containers))
+
+	egressLinks := map[string]models.EgressLink{}
+	for _, l := range links {
+		egressLinks[l.SandboxName] = l
+	}
+
+	peers := newRouting(containers).peers(policies, containers)
+
+	destinations := map[string]bool{}
+	for _, c := range containers {
+		if c.HostIP != hostIP || len(peers[c.ID]) == 0 {
+			continue
+		}
+
+		destinations[c.ID] = true
+		for _, peer := range peers[c.ID] {
+			destinations[peer.ID] = true
+		}
+	}
+
+	var routes []Route
+	for _, c := range containers {
+		if !destinations[c.ID] || c.HostIP == "" {
+			continue
+		}
+
+		route := Route{Destination: net.ParseIP(c.IP).To4(), Gateway: net.ParseIP(c.HostIP)}
+		if c.HostIP == hostIP {
+			link, ok := egressLinks[c.SandboxName]
+			if !ok {
+				continue
+			}
+			route.Gateway = link.SandboxAddress
+			route.Interface = link.HostInterface
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// routing decides which pairs of containers can be routed between. An
+// address that more than one network has handed out is ambiguous, so its
+// containers are never routed to.
+type routing struct {
+	conflicts map[string]bool
+}
+
+func newRouting(containers []models.Container) routing {
+	networks := map[string]string{}
+	conflicts := map[string]bool{}
+	for _, c := range containers {
+		if c.IP == "" {
+			continue
+		}
+
+		if networkID, ok := networks[c.IP]; ok && networkID != c.NetworkID {
+			conflicts[c.IP] = true
+		}
+		networks[c.IP] = c.NetworkID
+	}
+
+	return routing{conflicts: conflicts}
+}
+
+// routable reports whether traffic between two containers in different
+// sandboxes can be routed through the hosts: neither address may fall in the
+// other container's subnet, or it would be looked for on the local bridge.
+func (r routing) routable(a, b models.Container) bool {
+	if a.SandboxName == b.SandboxName || r.conflicts[a.IP] || r.conflicts[b.IP] {
+		return false
+	}
+
+	subnetA, subnetB := subnet(a), subnet(b)
+	ipA, ipB := net.ParseIP(a.IP), net.ParseIP(b.IP)
+	if subnetA == nil || subnetB == nil || ipA == nil || ipB == nil {
+		return false
+	}
+
+	return !subnetA.Contains(ipB) && !subnetB.Contains(ipA)
+}
+
+// peers returns, by container ID, the containers on other networks that a
+// policy lets the container talk to or be reached from.
+func (r routing) peers(policies []models.Policy, containers []models.Container) map[string][]models.Container {
+	peers := map[string][]models.Container{}
+	paired := map[[2]string]bool{}
+
+	for _, p := range policies {
+		for _, source := range containers {
+			if source.App != p.Source {
+				continue
+			}
+
+			for _, destination := range containers {
+				if destination.App != p.Destination || !r.routable(source, destination) {
+					continue
+				}
+
+				if paired[[2]string{source.ID, destination.ID}] {
+					continue
+				}
+				paired[[2]string{source.ID, destination.ID}] = true
+				paired[[2]string{destination.ID, source.ID}] = true
+
+				peers[source.ID] = append(peers[source.ID], destination)
+				peers[destination.ID] = append(peers[destination.ID], source)
+			}
+		}
+	}
+
+	for _, p := range peers {
+		sort.Sort(byID(p))
+	}
+
+	return peers
+}
+
+func subnet(c models.Container) *net.IPNet {
+	if c.IPAMResult == nil || c.IPAMResult.IP4 == nil {
+		return nil
+	}
+
+	ip := c.IPAMResult.IP4.IP
+	return &net.IPNet{IP: ip.IP.Mask(ip.Mask), Mask: ip.Mask}
+}
+
+// subnets returns the IPv4 subnets of the networks the containers are on.
+func subnets(containers []models.Container) []net.IPNet {
+	seen := map[string]bool{}
+
+	var subnets []net.IPNet
+	for _, c := range containers {
+		s := subnet(c)
+		if s == nil || seen[s.String()] {
+			continue
+		}
+
+		seen[s.String()] = true
+		subnets = append(subnets, *s)
+	}
+
+	return subnets
+}
+
+type routeNetlinker interface {
+	LinkByName(name string) (netlink.Link, error)
+	RouteAdd(*netlink.Route) error
+	RouteDel(*netlink.Route) error
+	RouteList(netlink.Link, int) ([]netlink.Route, error)
+}
+
+// HostRoutes programs routes in the main table of the host. Every /32 route
+// to an address in one of the given subnets is taken to be its own, so
+// routes to containers that no longer need one are removed.
+type HostRoutes struct {
+	Netlinker routeNetlinker
+}
+
+// Program makes the host routes to addresses in the subnets match routes.
+func (h *HostRoutes) Program(routes []Route, subnets []net.IPNet) error {
+	existing, err := h.Netlinker.RouteList(nil, nl.FAMILY_V4)
+	if err != nil {
+		return fmt.Errorf("listing routes: %s", err)
+	}
+
+	wanted := map[string]Route{}
+	for _, r := range routes {
+		wanted[r.Destination.String()] = r
+	}
+
+	current := map[string]bool{}
+	for _, r := range existing {
+		if !owned(r, subnets) {
+			continue
+		}
+
+		destination := r.Dst.IP.String()
+		if w, ok := wanted[destination]; ok && w.Gateway.Equal(r.Gw) {
+			current[destination] = true
+			continue
+		}
+
+		route := r
+		err := h.Netlinker.RouteDel(&route)
+		if err != nil {
+			return fmt.Errorf("deleting route to %s: %s", destination, err)
+		}
+	}
+
+	for _, r := range routes {
+		if current[r.Destination.String()] {
+			continue
+		}
+
+		route := &netlink.Route{
+			Scope: netlink.SCOPE_UNIVERSE,
+			Dst:   &net.IPNet{IP: r.Destination, Mask: net.CIDRMask(32, 32)},
+			Gw:    r.Gateway,
+		}
+
+		if r.Interface != "" {
+			link, err := h.Netlinker.LinkByName(r.Interface)
+			if err != nil {
+				return fmt.Errorf("link by name %s: %s", r.Interface, err)
+			}
+			route.LinkIndex = link.Attrs().Index
+		}
+
+		err := h.Netlinker.RouteAdd(route)
+		if err != nil {
+			return fmt.Errorf("adding route to %s: %s", r.Destination, err)
+		}
+	}
+
+	return nil
+}
+
+func owned(r netlink.Route, subnets []net.IPNet) bool {
+	if r.Dst == nil {
+		return false
+	}
+
+	ones, bits := r.Dst.Mask.Size()
+	if ones != 32 || bits != 32 {
+		return false
+	}
+
+	for _, s := range subnets {
+		if s.Contains(r.Dst.IP) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package policy_test
+
+import (
+	"errors"
+	"net"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/nl/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+	"github.com/vishvananda/netlink"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		policies   []models.Policy
+		containers []models.Container
+		links      []models.EgressLink
+	)
+
+	BeforeEach(func() {
+		policies = []models.Policy{
+			{ID: "frontend-to-backend", Source: "frontend", Destination: "backend"},
+		}
+		containers = []models.Container{
+			{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1", NetworkID: "one", HostIP: "10.0.0.1", IPAMResult: ipamResult("192.168.1.2/24")},
+			{ID: "backend-2", IP: "192.168.1.3", App: "backend", SandboxName: "vni-1", NetworkID: "one", HostIP: "10.0.0.1", IPAMResult: ipamResult("192.168.1.3/24")},
+			{ID: "frontend-1", IP: "192.168.1.4", App: "frontend", SandboxName: "vni-1", NetworkID: "one", HostIP: "10.0.0.1", IPAMResult: ipamResult("192.168.1.4/24")},
+			{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2", NetworkID: "two", HostIP: "10.0.0.2", IPAMResult: ipamResult("192.168.2.2/24")},
+			{ID: "worker-1", IP: "192.168.3.2", App: "worker", SandboxName: "vni-3", NetworkID: "three", HostIP: "10.0.0.3", IPAMResult: ipamResult("192.168.3.2/24")},
+		}
+		links = []models.EgressLink{
+			{SandboxName: "vni-1", HostInterface: "egh1", SandboxAddress: net.ParseIP("169.254.0.2")},
+		}
+	})
+
+	It("routes to local containers through their egress link and to their peers through the peer's host", func() {
+		Expect(policy.Routes("10.0.0.1", links, policies, containers)).To(Equal([]policy.Route{
+			{Destination: net.ParseIP("192.168.1.2").To4(), Gateway: net.ParseIP("169.254.0.2"), Interface: "egh1"},
+			{Destination: net.ParseIP("192.168.1.3").To4(), Gateway: net.ParseIP("169.254.0.2"), Interface: "egh1"},
+			{Destination: net.ParseIP("192.168.2.2").To4(), Gateway: net.ParseIP("10.0.0.2")},
+		}))
+	})
+
+	It("has no routes on a host without containers that have peers", func() {
+		Expect(policy.Routes("10.0.0.3", links, policies, containers)).To(BeNil())
+	})
+
+	It("skips local containers whose sandbox has no egress link", func() {
+		Expect(policy.Routes("10.0.0.1", nil, policies, containers)).To(Equal([]policy.Route{
+			{Destination: net.ParseIP("192.168.2.2").To4(), Gateway: net.ParseIP("10.0.0.2")},
+		}))
+	})
+})
+
+var _ = Describe("HostRoutes", func() {
+	var (
+		netlinker  *fakes.Netlinker
+		hostRoutes *policy.HostRoutes
+		routes     []policy.Route
+		subnets    []net.IPNet
+	)
+
+	hostRoute := func(destination, gateway string) netlink.Route {
+		return netlink.Route{
+			Dst: &net.IPNet{IP: net.ParseIP(destination).To4(), Mask: net.CIDRMask(32, 32)},
+			Gw:  net.ParseIP(gateway),
+		}
+	}
+
+	BeforeEach(func() {
+		netlinker = &fakes.Netlinker{}
+		netlinker.LinkByNameReturns(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Index: 7}}, nil)
+
+		hostRoutes = &policy.HostRoutes{Netlinker: netlinker}
+
+		routes = []policy.Route{
+			{Destination: net.ParseIP("192.168.1.2").To4(), Gateway: net.ParseIP("169.254.0.2"), Interface: "egh1"},
+			{Destination: net.ParseIP("192.168.2.2").To4(), Gateway: net.ParseIP("10.0.0.2")},
+		}
+
+		_, one, _ := net.ParseCIDR("192.168.1.0/24")
+		_, two, _ := net.ParseCIDR("192.168.2.0/24")
+		subnets = []net.IPNet{*one, *two}
+	})
+
+	It("adds the missing routes", func() {
+		Expect(hostRoutes.Program(routes, subnets)).To(Succeed())
+
+		Expect(netlinker.RouteListCallCount()).To(Equal(1))
+		_, family := netlinker.RouteListArgsForCall(0)
+		Expect(family).To(Equal(nl.FAMILY_V4))
+
+		Expect(netlinker.LinkByNameCallCount()).To(Equal(1))
+		Expect(netlinker.LinkByNameArgsForCall(0)).To(Equal("egh1"))
+
+		Expect(netlinker.RouteAddCallCount()).To(Equal(2))
+		Expect(netlinker.RouteAddArgsForCall(0)).To(Equal(&netlink.Route{
+			LinkIndex: 7,
+			Scope:     netlink.SCOPE_UNIVERSE,
+			Dst:       &net.IPNet{IP: net.ParseIP("192.168.1.2").To4(), Mask: net.CIDRMask(32, 32)},
+			Gw:        net.ParseIP("169.254.0.2"),
+		}))
+		Expect(netlinker.RouteAddArgsForCall(1)).To(Equal(&netlink.Route{
+			Scope: netlink.SCOPE_UNIVERSE,
+			Dst:   &net.IPNet{IP: net.ParseIP("192.168.2.2").To4(), Mask: net.CIDRMask(32, 32)},
+			Gw:    net.ParseIP("10.0.0.2"),
+		}))
+	})
+
+	Context("when some routes are already there", func() {
+		BeforeEach(func() {
+			netlinker.RouteListReturns([]netlink.Route{
+				hostRoute("192.168.1.2", "169.254.0.2"),
+				hostRoute("192.168.2.2", "10.0.0.9"),
+				hostRoute("192.168.2.3", "10.0.0.2"),
+				hostRoute("172.16.0.1", "10.0.0.2"),
+				{Dst: &net.IPNet{IP: net.ParseIP("192.168.1.0").To4(), Mask: net.CIDRMask(24, 32)}},
+				{Gw: net.ParseIP("10.0.0.254")},
+			}, nil)
+		})
+
+		It("keeps the current ones, replaces the changed ones and deletes its stale ones", func() {
+			Expect(hostRoutes.Program(routes, subnets)).To(Succeed())
+
+			Expect(netlinker.RouteDelCallCount()).To(Equal(2))
+			Expect(*netlinker.RouteDelArgsForCall(0)).To(Equal(hostRoute("192.168.2.2", "10.0.0.9")))
+			Expect(*netlinker.RouteDelArgsForCall(1)).To(Equal(hostRoute("192.168.2.3", "10.0.0.2")))
+
+			Expect(netlinker.RouteAddCallCount()).To(Equal(1))
+			Expect(netlinker.RouteAddArgsForCall(0).Gw).To(Equal(net.ParseIP("10.0.0.2")))
+		})
+	})
+
+	Context("when the routes cannot be listed", func() {
+		BeforeEach(func() {
+			netlinker.RouteListReturns(nil, errors.New("potato"))
+		})
+
+		It("returns the error", func() {
+			Expect(hostRoutes.Program(routes, subnets)).To(MatchError("listing routes: potato"))
+		})
+	})
+
+	Context("when a route cannot be deleted", func() {
+		BeforeEach(func() {
+			netlinker.RouteListReturns([]netlink.Route{hostRoute("192.168.2.3", "10.0.0.2")}, nil)
+			netlinker.RouteDelReturns(errors.New("potato"))
+		})
+
+		It("returns the error", func() {
+			Expect(hostRoutes.Program(routes, subnets)).To(MatchError("deleting route to 192.168.2.3: potato"))
+		})
+	})
+
+	Context("when the egress link cannot be found", func() {
+		BeforeEach(func() {
+			netlinker.LinkByNameReturns(nil, errors.New("potato"))
+		})
+
+		It("returns the error", func() {
+			Expect(hostRoutes.Program(routes, subnets)).To(MatchError("link by name egh1: potato"))
+		})
+	})
+
+	Context("when a route cannot be added", func() {
+		BeforeEach(func() {
+			netlinker.RouteAddReturns(errors.New("potato"))
+		})
+
+		It("returns the error", func() {
+			Expect(hostRoutes.Program(routes, subnets)).To(MatchError("adding route to 192.168.1.2: potato"))
+		})
+	})
+})
@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+)
+
+// ChainName is the filter chain that holds the policy rules of a sandbox.
+const ChainName = "ducati-policy"
+
+// NATChainName is the nat chain of a sandbox that keeps traffic between
+// policy peers on different networks from being masqueraded on its way out
+// through the egress link.
+const NATChainName = "ducati-policy-nat"
+
+// Rules returns the rules of the policy chain for a sandbox. Containers in
+// the sandbox whose app is the destination of a policy only accept traffic
+// from the containers of the source apps the policies name; everything else
+// sent to them is dropped. Sources on the same network are bridged inside
+// the sandbox. Sources on other networks are let through when the hosts
+// route between the two containers (see Routes); sources that cannot be
+// routed to are ignored. Replies to allowed traffic are always accepted.
+// When no container in the sandbox is the destination of a policy there
+// are no rules at all.
+func Rules(sandboxName string, policies []models.Policy, containers []models.Container) [][]string {
+	return rules(ipv4, sandboxName, policies, containers)
+}
+
+// Rules6 returns the ip6tables rules of the policy chain for a sandbox on a
+// dual-stack network. They enforce the same policies as Rules, on the IPv6
+// addresses of the containers, and always let neighbor discovery through so
+// that unicast solicitations to a destination are not dropped. Only IPv4 is
+// routed between networks, so IPv6 sources must be on the same network.
+func Rules6(sandboxName string, policies []models.Policy, containers []models.Container) [][]string {
+	return rules(ipv6, sandboxName, policies, containers)
+}
+
+type family struct {
+	address func(models.Container) string
+	host    string
+	icmp    string
+	accept  [][]string
+	routed  bool
+}
+
+var (
+	ipv4 = family{
+		address: func(c models.Container) string { return c.IP },
+		host:    "/32",
+		icmp:    "icmp",
+		routed:  true,
+	}
+	ipv6 = family{
+		address: func(c models.Container) string { return c.IP6 },
+		host:    "/128",
+		icmp:    "icmpv6",
+		accept: [][]string{
+			{"-p", "icmpv6", "--icmpv6-type", "neighbour-solicitation", "-j", "ACCEPT"},
+			{"-p", "icmpv6", "--icmpv6-type", "neighbour-advertisement", "-j", "ACCEPT"},
+		},
+	}
+)
+
+func rules(f family, sandboxName string, policies []models.Policy, containers []models.Container) [][]string {
+	containers = append([]models.Container{}, containers...)
+	sort.Sort(byID(containers))
+
+	routing := newRouting(containers)
+
+	allowed := map[string][]models.Policy{}
+	for _, p := range policies {
+		allowed[p.Destination] = append(allowed[p.Destination], p)
+	}
+
+	var rules [][]string
+	for _, c := range containers {
+		if c.SandboxName != sandboxName || f.address(c) == "" {
+			continue
+		}
+
+		destinationPolicies, ok := allowed[c.App]
+		if !ok {
+			continue
+		}
+
+		destination := f.address(c) + f.host
+		for _, p := range destinationPolicies {
+			for _, source := range containers {
+				if source.App != p.Source || f.address(source) == "" {
+					continue
+				}
+
+				if source.SandboxName != sandboxName && !(f.routed && routing.routable(source, c)) {
+					continue
+				}
+
+				rule := []string{"-s", f.address(source) + f.host, "-d", destination}
+				rule = append(rule, f.protocolMatch(p)...)
+				rules = append(rules, append(rule, "-j", "ACCEPT"))
+			}
+		}
+		rules = append(rules, []string{"-d", destination, "-j", "DROP"})
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	established := []string{"-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"}
+	accept := append([][]string{established}, f.accept...)
+	return append(accept, rules...)
+}
+
+// NATRules returns the rules of the nat chain for a sandbox. Traffic from
+// its containers to their peers on other networks is accepted before the
+// egress masquerade rule, so that it reaches the peer with the source
+// address the peer's policy rules match on.
+func NATRules(sandboxName string, policies []models.Policy, containers []models.Container) [][]string {
+	containers = append([]models.Container{}, containers...)
+	sort.Sort(byID(containers))
+
+	peers := newRouting(containers).peers(policies, containers)
+
+	var rules [][]string
+	for _, c := range containers {
+		if c.SandboxName != sandboxName {
+			continue
+		}
+
+		for _, peer := range peers[c.ID] {
+			rules = append(rules, []string{"-s", c.IP + "/32", "-d", peer.IP + "/32", "-j", "ACCEPT"})
+		}
+	}
+
+	return rules
+}
+
+func (f family) protocolMatch(p models.Policy) []string {
+	if p.Protocol == "" {
+		return nil
+	}
+
+	protocol := p.Protocol
+	if protocol == "icmp" {
+		protocol = f.icmp
+	}
+
+	match := []string{"-p", protocol}
+	if p.Port != 0 {
+		match = append(match, "--dport", strconv.Itoa(p.Port))
+	}
+
+	return match
+}
+
+type byID []models.Container
+
+func (c byID) Len() int           { return len(c) }
+func (c byID) Less(i, j int) bool { return c[i].ID < c[j].ID }
+func (c byID) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
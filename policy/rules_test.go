@@ -0,0 +1,179 @@
+package policy_test
+
+import (
+	"net"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rules", func() {
+	var (
+		policies    []models.Policy
+		containers  []models.Container
+		established []string
+	)
+
+	BeforeEach(func() {
+		established = []string{"-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"}
+
+		policies = []models.Policy{
+			{ID: "frontend-to-backend", Source: "frontend", Destination: "backend", Protocol: "tcp", Port: 8080},
+		}
+		containers = []models.Container{
+			{ID: "backend-2", IP: "192.168.1.3", App: "backend", SandboxName: "vni-1"},
+			{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1"},
+			{ID: "frontend-1", IP: "192.168.1.5", App: "frontend", SandboxName: "vni-1"},
+			{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2"},
+			{ID: "worker-1", IP: "192.168.1.4", App: "worker", SandboxName: "vni-1"},
+		}
+	})
+
+	It("only lets the allowed sources reach the destination containers in the sandbox", func() {
+		Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+			established,
+			{"-s", "192.168.1.5/32", "-d", "192.168.1.2/32", "-p", "tcp", "--dport", "8080", "-j", "ACCEPT"},
+			{"-d", "192.168.1.2/32", "-j", "DROP"},
+			{"-s", "192.168.1.5/32", "-d", "192.168.1.3/32", "-p", "tcp", "--dport", "8080", "-j", "ACCEPT"},
+			{"-d", "192.168.1.3/32", "-j", "DROP"},
+		}))
+	})
+
+	It("ignores sources on other networks that cannot be routed to", func() {
+		containers = []models.Container{
+			{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1"},
+			{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2"},
+		}
+
+		Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+			established,
+			{"-d", "192.168.1.2/32", "-j", "DROP"},
+		}))
+	})
+
+	Context("when the source is on another network that can be routed to", func() {
+		BeforeEach(func() {
+			containers = []models.Container{
+				{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1", NetworkID: "one", IPAMResult: ipamResult("192.168.1.2/24")},
+				{ID: "frontend-2", IP: "192.168.2.2", App: "frontend", SandboxName: "vni-2", NetworkID: "two", IPAMResult: ipamResult("192.168.2.2/24")},
+			}
+		})
+
+		It("accepts it", func() {
+			Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+				established,
+				{"-s", "192.168.2.2/32", "-d", "192.168.1.2/32", "-p", "tcp", "--dport", "8080", "-j", "ACCEPT"},
+				{"-d", "192.168.1.2/32", "-j", "DROP"},
+			}))
+		})
+
+		It("ignores it when the subnets overlap", func() {
+			containers[1].IPAMResult = ipamResult("192.168.2.2/16")
+
+			Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+				established,
+				{"-d", "192.168.1.2/32", "-j", "DROP"},
+			}))
+		})
+
+		It("ignores it when another network has handed out the same address", func() {
+			containers = append(containers, models.Container{ID: "other-1", IP: "192.168.2.2", SandboxName: "vni-3", NetworkID: "three"})
+
+			Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+				established,
+				{"-d", "192.168.1.2/32", "-j", "DROP"},
+			}))
+		})
+
+		It("keeps the traffic between them from being masqueraded in either sandbox", func() {
+			Expect(policy.NATRules("vni-1", policies, containers)).To(Equal([][]string{
+				{"-s", "192.168.1.2/32", "-d", "192.168.2.2/32", "-j", "ACCEPT"},
+			}))
+			Expect(policy.NATRules("vni-2", policies, containers)).To(Equal([][]string{
+				{"-s", "192.168.2.2/32", "-d", "192.168.1.2/32", "-j", "ACCEPT"},
+			}))
+		})
+	})
+
+	It("has no nat rules when no policy crosses networks", func() {
+		Expect(policy.NATRules("vni-1", policies, containers)).To(BeNil())
+	})
+
+	It("has no rules for a sandbox without destinations", func() {
+		Expect(policy.Rules("vni-2", policies, containers)).To(BeNil())
+	})
+
+	It("has no rules when there are no policies", func() {
+		Expect(policy.Rules("vni-1", nil, containers)).To(BeNil())
+	})
+
+	It("drops everything sent to a destination whose sources have no containers", func() {
+		policies[0].Source = "some-stopped-app"
+
+		Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+			established,
+			{"-d", "192.168.1.2/32", "-j", "DROP"},
+			{"-d", "192.168.1.3/32", "-j", "DROP"},
+		}))
+	})
+
+	It("combines the policies of a destination", func() {
+		policies = []models.Policy{
+			{ID: "worker-to-worker", Source: "worker", Destination: "worker"},
+			{ID: "frontend-to-worker", Source: "frontend", Destination: "worker", Protocol: "udp"},
+		}
+
+		Expect(policy.Rules("vni-1", policies, containers)).To(Equal([][]string{
+			established,
+			{"-s", "192.168.1.4/32", "-d", "192.168.1.4/32", "-j", "ACCEPT"},
+			{"-s", "192.168.1.5/32", "-d", "192.168.1.4/32", "-p", "udp", "-j", "ACCEPT"},
+			{"-d", "192.168.1.4/32", "-j", "DROP"},
+		}))
+	})
+
+	Describe("Rules6", func() {
+		BeforeEach(func() {
+			containers = []models.Container{
+				{ID: "backend-1", IP: "192.168.1.2", IP6: "fd00::2", App: "backend", SandboxName: "vni-1"},
+				{ID: "frontend-1", IP: "192.168.1.5", IP6: "fd00::5", App: "frontend", SandboxName: "vni-1"},
+			}
+		})
+
+		It("enforces the policies on the IPv6 addresses", func() {
+			Expect(policy.Rules6("vni-1", policies, containers)).To(Equal([][]string{
+				established,
+				{"-p", "icmpv6", "--icmpv6-type", "neighbour-solicitation", "-j", "ACCEPT"},
+				{"-p", "icmpv6", "--icmpv6-type", "neighbour-advertisement", "-j", "ACCEPT"},
+				{"-s", "fd00::5/128", "-d", "fd00::2/128", "-p", "tcp", "--dport", "8080", "-j", "ACCEPT"},
+				{"-d", "fd00::2/128", "-j", "DROP"},
+			}))
+		})
+
+		It("matches icmpv6 for icmp policies", func() {
+			policies[0].Protocol = "icmp"
+			policies[0].Port = 0
+
+			Expect(policy.Rules6("vni-1", policies, containers)).To(ContainElement(
+				[]string{"-s", "fd00::5/128", "-d", "fd00::2/128", "-p", "icmpv6", "-j", "ACCEPT"},
+			))
+		})
+
+		It("has no rules for containers without an IPv6 address", func() {
+			Expect(policy.Rules6("vni-1", policies, []models.Container{
+				{ID: "backend-1", IP: "192.168.1.2", App: "backend", SandboxName: "vni-1"},
+			})).To(BeNil())
+		})
+	})
+})
+
+func ipamResult(cidr string) *models.IPAMResult {
+	ip, subnet, err := net.ParseCIDR(cidr)
+	Expect(err).NotTo(HaveOccurred())
+
+	subnet.IP = ip
+	return &models.IPAMResult{Result: types.Result{IP4: &types.IPConfig{IP: *subnet}}}
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"os"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter -o ../fakes/syncer.go --fake-name Syncer . syncer
+type syncer interface {
+	Sync() error
+}
+
+// SyncLoop runs the Syncer when it starts and once per Interval after that,
+// as a backstop for policy changes whose notifications were lost.
+type SyncLoop struct {
+	Logger   lager.Logger
+	Clock    clock.Clock
+	Interval time.Duration
+	Syncer   syncer
+}
+
+func (l *SyncLoop) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := l.Logger.Session("policy-sync-loop", lager.Data{"interval": l.Interval.String()})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	ticker := l.Clock.NewTicker(l.Interval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	l.sync(logger)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			l.sync(logger)
+		}
+	}
+}
+
+func (l *SyncLoop) sync(logger lager.Logger) {
+	err := l.Syncer.Sync()
+	if err != nil {
+		logger.Error("sync-failed", err)
+	}
+}
@@ -0,0 +1,74 @@
+package policy_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/policy"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("SyncLoop", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		syncer    *fakes.Syncer
+		loop      *policy.SyncLoop
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		syncer = &fakes.Syncer{}
+
+		loop = &policy.SyncLoop{
+			Logger:   logger,
+			Clock:    fakeClock,
+			Interval: time.Minute,
+			Syncer:   syncer,
+		}
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Invoke(loop)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("syncs when it starts and on each tick", func() {
+		Eventually(syncer.SyncCallCount).Should(Equal(1))
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+		fakeClock.Increment(time.Minute)
+		Eventually(syncer.SyncCallCount).Should(Equal(2))
+
+		fakeClock.Increment(time.Minute)
+		Eventually(syncer.SyncCallCount).Should(Equal(3))
+	})
+
+	Context("when a sync fails", func() {
+		BeforeEach(func() {
+			syncer.SyncReturns(errors.New("potato"))
+		})
+
+		It("logs the error and keeps going", func() {
+			Eventually(logger).Should(gbytes.Say("sync-failed.*potato"))
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+			fakeClock.Increment(time.Minute)
+			Eventually(syncer.SyncCallCount).Should(Equal(2))
+		})
+	})
+})
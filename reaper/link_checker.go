@@ -0,0 +1,44 @@
+package reaper
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+)
+
+type sandboxGetter interface {
+	Get(sandboxName string) (sandbox.Sandbox, error)
+}
+
+type linkFinder interface {
+	Exists(name string) bool
+}
+
+// SandboxLinkChecker looks for a link inside a sandbox namespace. A sandbox
+// that no longer exists has no links.
+type SandboxLinkChecker struct {
+	Sandboxes   sandboxGetter
+	LinkFactory linkFinder
+}
+
+func (c *SandboxLinkChecker) SandboxLinkExists(sandboxName, linkName string) (bool, error) {
+	sbox, err := c.Sandboxes.Get(sandboxName)
+	if err == sandbox.NotFoundError {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get sandbox: %s", err)
+	}
+
+	var exists bool
+	err = sbox.Namespace().Execute(func(*os.File) error {
+		exists = c.LinkFactory.Exists(linkName)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("namespace execute: %s", err)
+	}
+
+	return exists, nil
+}
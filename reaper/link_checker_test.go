@@ -0,0 +1,88 @@
+package reaper_test
+
+import (
+	"errors"
+	"os"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/reaper"
+	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SandboxLinkChecker", func() {
+	var (
+		sandboxRepo *fakes.SandboxRepository
+		sbox        *fakes.Sandbox
+		ns          *fakes.Namespace
+		linkFactory *fakes.LinkFactory
+		checker     *reaper.SandboxLinkChecker
+	)
+
+	BeforeEach(func() {
+		ns = &fakes.Namespace{}
+		ns.ExecuteStub = func(callback func(*os.File) error) error {
+			return callback(nil)
+		}
+
+		sbox = &fakes.Sandbox{}
+		sbox.NamespaceReturns(ns)
+
+		sandboxRepo = &fakes.SandboxRepository{}
+		sandboxRepo.GetReturns(sbox, nil)
+
+		linkFactory = &fakes.LinkFactory{}
+		linkFactory.ExistsReturns(true)
+
+		checker = &reaper.SandboxLinkChecker{
+			Sandboxes:   sandboxRepo,
+			LinkFactory: linkFactory,
+		}
+	})
+
+	It("looks for the link inside the sandbox namespace", func() {
+		exists, err := checker.SandboxLinkExists("vni-1", "some-link")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		Expect(sandboxRepo.GetArgsForCall(0)).To(Equal("vni-1"))
+		Expect(ns.ExecuteCallCount()).To(Equal(1))
+		Expect(linkFactory.ExistsArgsForCall(0)).To(Equal("some-link"))
+	})
+
+	Context("when the sandbox does not exist", func() {
+		BeforeEach(func() {
+			sandboxRepo.GetReturns(nil, sandbox.NotFoundError)
+		})
+
+		It("reports that the link does not exist", func() {
+			exists, err := checker.SandboxLinkExists("vni-1", "some-link")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+	})
+
+	Context("when getting the sandbox fails", func() {
+		BeforeEach(func() {
+			sandboxRepo.GetReturns(nil, errors.New("potato"))
+		})
+
+		It("returns a wrapped error", func() {
+			_, err := checker.SandboxLinkExists("vni-1", "some-link")
+			Expect(err).To(MatchError("get sandbox: potato"))
+		})
+	})
+
+	Context("when entering the namespace fails", func() {
+		BeforeEach(func() {
+			ns.ExecuteReturns(errors.New("potato"))
+		})
+
+		It("returns a wrapped error", func() {
+			_, err := checker.SandboxLinkExists("vni-1", "some-link")
+			Expect(err).To(MatchError("namespace execute: potato"))
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package reaper
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/container"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+)
+
+type containerStore interface {
+	Get(id string) (models.Container, error)
+	All() ([]models.Container, error)
+	Delete(id string) error
+}
+
+type leaseStore interface {
+	Renew(networkID, containerID string, at time.Time) error
+	RenewedAt(networkID, containerID string) (time.Time, error)
+}
+
+//go:generate counterfeiter -o ../fakes/link_checker.go --fake-name LinkChecker . linkChecker
+type linkChecker interface {
+	SandboxLinkExists(sandboxName, linkName string) (bool, error)
+}
+
+type ipReleaser interface {
+	ReleaseIP(networkID, containerID string) error
+}
+
+type containerLocker interface {
+	Lock(containerID string) error
+	Unlock(containerID string)
+}
+
+// Reaper renews the IP lease of every container on this host whose sandbox
+// veth still exists, and reclaims the address and container record of any
+// container whose veth has been gone for longer than LeaseDuration. This
+// catches containers that died without a CNI DEL.
+type Reaper struct {
+	Logger        lager.Logger
+	Clock         clock.Clock
+	Interval      time.Duration
+	LeaseDuration time.Duration
+	HostIP        net.IP
+	Datastore     containerStore
+	Leases        leaseStore
+	Links         linkChecker
+	IPAllocator   ipReleaser
+	Locker        containerLocker
+}
+
+func (r *Reaper) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.Logger.Session("reaper", lager.Data{
+		"interval":       r.Interval.String(),
+		"lease_duration": r.LeaseDuration.String(),
+	})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			err := r.Sweep()
+			if err != nil {
+				logger.Error("sweep-failed", err)
+			}
+		}
+	}
+}
+
+// Sweep makes a single pass over the containers on this host.
+func (r *Reaper) Sweep() error {
+	logger := r.Logger.Session("sweep")
+
+	containers, err := r.Datastore.All()
+	if err != nil {
+		return fmt.Errorf("listing containers: %s", err)
+	}
+
+	now := r.Clock.Now()
+	reclaimed := []string{}
+
+	for _, c := range containers {
+		if c.HostIP != r.HostIP.String() {
+			continue
+		}
+
+		data := lager.Data{"container": c}
+
+		attached, err := r.attached(c)
+		if err != nil {
+			logger.Error("link-check-failed", err, data)
+			continue
+		}
+
+		if attached {
+			err = r.Leases.Renew(c.NetworkID, c.ID, now)
+			if err != nil {
+				logger.Error("renew-failed", err, data)
+			}
+			continue
+		}
+
+		renewedAt, err := r.Leases.RenewedAt(c.NetworkID, c.ID)
+		if err != nil && err != store.RecordNotFoundError {
+			logger.Error("lease-lookup-failed", err, data)
+			continue
+		}
+
+		if now.Sub(renewedAt) < r.LeaseDuration {
+			continue
+		}
+
+		ok, err := r.reclaim(c)
+		if err != nil {
+			logger.Error("reclaim-failed", err, data)
+			continue
+		}
+
+		if ok {
+			logger.Info("reclaimed-address", lager.Data{
+				"container_id": c.ID,
+				"network_id":   c.NetworkID,
+				"ip":           c.IP,
+				"renewed_at":   renewedAt,
+			})
+			reclaimed = append(reclaimed, c.IP)
+		}
+	}
+
+	if len(reclaimed) > 0 {
+		logger.Info("reclaimed-addresses", lager.Data{"ips": reclaimed})
+	}
+
+	return nil
+}
+
+// reclaim releases the container's address and removes its record while
+// holding the container lock, checking again that an ADD or DEL has not
+// dealt with the container in the meantime.
+func (r *Reaper) reclaim(c models.Container) (bool, error) {
+	err := r.Locker.Lock(c.ID)
+	if err != nil {
+		return false, err
+	}
+	defer r.Locker.Unlock(c.ID)
+
+	current, err := r.Datastore.Get(c.ID)
+	if err == store.RecordNotFoundError {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("datastore get: %s", err)
+	}
+
+	attached, err := r.attached(current)
+	if err != nil {
+		return false, err
+	}
+	if attached {
+		return false, nil
+	}
+
+	err = r.IPAllocator.ReleaseIP(current.NetworkID, current.ID)
+	if err != nil {
+		return false, fmt.Errorf("release ip: %s", err)
+	}
+
+	err = r.Datastore.Delete(current.ID)
+	if err != nil && err != store.RecordNotFoundError {
+		return false, fmt.Errorf("datastore delete: %s", err)
+	}
+
+	return true, nil
+}
+
+func (r *Reaper) attached(c models.Container) (bool, error) {
+	return r.Links.SandboxLinkExists(c.SandboxName, container.NameSandboxLink(c.ID))
+}
@@ -0,0 +1,249 @@
+package reaper_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/container"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/reaper"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reaper", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeClock   *fakeclock.FakeClock
+		datastore   *fakes.Store
+		leases      *fakes.LeaseStore
+		links       *fakes.LinkChecker
+		ipAllocator *fakes.IPAllocator
+		locker      *fakes.ContainerLocker
+		r           *reaper.Reaper
+
+		attached models.Container
+		vanished models.Container
+		remote   models.Container
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		datastore = &fakes.Store{}
+		leases = &fakes.LeaseStore{}
+		links = &fakes.LinkChecker{}
+		ipAllocator = &fakes.IPAllocator{}
+		locker = &fakes.ContainerLocker{}
+
+		attached = models.Container{
+			ID:          "attached-id",
+			IP:          "192.168.1.2",
+			HostIP:      "10.0.0.1",
+			NetworkID:   "some-network-id",
+			SandboxName: "vni-1",
+		}
+		vanished = models.Container{
+			ID:          "vanished-id",
+			IP:          "192.168.1.3",
+			HostIP:      "10.0.0.1",
+			NetworkID:   "some-network-id",
+			SandboxName: "vni-1",
+		}
+		remote = models.Container{
+			ID:          "remote-id",
+			IP:          "192.168.1.4",
+			HostIP:      "10.0.0.2",
+			NetworkID:   "some-network-id",
+			SandboxName: "vni-1",
+		}
+
+		datastore.AllReturns([]models.Container{attached, vanished, remote}, nil)
+		datastore.GetStub = func(id string) (models.Container, error) {
+			return vanished, nil
+		}
+
+		links.SandboxLinkExistsStub = func(sandboxName, linkName string) (bool, error) {
+			return linkName == container.NameSandboxLink("attached-id"), nil
+		}
+
+		leases.RenewedAtReturns(fakeClock.Now().Add(-10*time.Minute), nil)
+
+		r = &reaper.Reaper{
+			Logger:        logger,
+			Clock:         fakeClock,
+			Interval:      time.Minute,
+			LeaseDuration: 5 * time.Minute,
+			HostIP:        net.ParseIP("10.0.0.1"),
+			Datastore:     datastore,
+			Leases:        leases,
+			Links:         links,
+			IPAllocator:   ipAllocator,
+			Locker:        locker,
+		}
+	})
+
+	Describe("Sweep", func() {
+		It("renews the lease of containers whose sandbox link still exists", func() {
+			Expect(r.Sweep()).To(Succeed())
+
+			Expect(leases.RenewCallCount()).To(Equal(1))
+			networkID, containerID, at := leases.RenewArgsForCall(0)
+			Expect(networkID).To(Equal("some-network-id"))
+			Expect(containerID).To(Equal("attached-id"))
+			Expect(at).To(Equal(fakeClock.Now()))
+		})
+
+		It("only looks at containers on this host", func() {
+			Expect(r.Sweep()).To(Succeed())
+
+			Expect(links.SandboxLinkExistsCallCount()).To(Equal(3))
+			for i := 0; i < links.SandboxLinkExistsCallCount(); i++ {
+				_, linkName := links.SandboxLinkExistsArgsForCall(i)
+				Expect(linkName).NotTo(Equal(container.NameSandboxLink("remote-id")))
+			}
+		})
+
+		It("reclaims containers whose lease has expired", func() {
+			Expect(r.Sweep()).To(Succeed())
+
+			Expect(locker.LockCallCount()).To(Equal(1))
+			Expect(locker.LockArgsForCall(0)).To(Equal("vanished-id"))
+			Expect(locker.UnlockCallCount()).To(Equal(1))
+
+			Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(1))
+			networkID, containerID := ipAllocator.ReleaseIPArgsForCall(0)
+			Expect(networkID).To(Equal("some-network-id"))
+			Expect(containerID).To(Equal("vanished-id"))
+
+			Expect(datastore.DeleteCallCount()).To(Equal(1))
+			Expect(datastore.DeleteArgsForCall(0)).To(Equal("vanished-id"))
+
+			Expect(logger).To(gbytes.Say(`sweep.reclaimed-address.*192.168.1.3`))
+			Expect(logger).To(gbytes.Say(`sweep.reclaimed-addresses.*192.168.1.3`))
+		})
+
+		Context("when the lease has not expired yet", func() {
+			BeforeEach(func() {
+				leases.RenewedAtReturns(fakeClock.Now().Add(-time.Minute), nil)
+			})
+
+			It("leaves the container alone", func() {
+				Expect(r.Sweep()).To(Succeed())
+
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+				Expect(datastore.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container holds no reservation", func() {
+			BeforeEach(func() {
+				leases.RenewedAtReturns(time.Time{}, store.RecordNotFoundError)
+			})
+
+			It("reclaims the container", func() {
+				Expect(r.Sweep()).To(Succeed())
+				Expect(datastore.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the container is deleted while waiting for the lock", func() {
+			BeforeEach(func() {
+				datastore.GetStub = nil
+				datastore.GetReturns(models.Container{}, store.RecordNotFoundError)
+			})
+
+			It("does not release anything", func() {
+				Expect(r.Sweep()).To(Succeed())
+
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+				Expect(datastore.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container lock cannot be taken", func() {
+			BeforeEach(func() {
+				locker.LockReturns(errors.New("potato"))
+			})
+
+			It("logs the error and moves on", func() {
+				Expect(r.Sweep()).To(Succeed())
+
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+				Expect(locker.UnlockCallCount()).To(Equal(0))
+				Expect(logger).To(gbytes.Say(`sweep.reclaim-failed.*potato`))
+			})
+		})
+
+		Context("when releasing the address fails", func() {
+			BeforeEach(func() {
+				ipAllocator.ReleaseIPReturns(errors.New("potato"))
+			})
+
+			It("keeps the container record so the next sweep tries again", func() {
+				Expect(r.Sweep()).To(Succeed())
+
+				Expect(datastore.DeleteCallCount()).To(Equal(0))
+				Expect(logger).To(gbytes.Say(`sweep.reclaim-failed.*release ip: potato`))
+			})
+		})
+
+		Context("when checking the sandbox link fails", func() {
+			BeforeEach(func() {
+				links.SandboxLinkExistsStub = nil
+				links.SandboxLinkExistsReturns(false, errors.New("potato"))
+			})
+
+			It("neither renews nor reclaims", func() {
+				Expect(r.Sweep()).To(Succeed())
+
+				Expect(leases.RenewCallCount()).To(Equal(0))
+				Expect(ipAllocator.ReleaseIPCallCount()).To(Equal(0))
+				Expect(logger).To(gbytes.Say(`sweep.link-check-failed.*potato`))
+			})
+		})
+
+		Context("when listing containers fails", func() {
+			BeforeEach(func() {
+				datastore.AllReturns(nil, errors.New("potato"))
+			})
+
+			It("returns a wrapped error", func() {
+				Expect(r.Sweep()).To(MatchError("listing containers: potato"))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		var process ifrit.Process
+
+		BeforeEach(func() {
+			process = ifrit.Invoke(r)
+		})
+
+		AfterEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+		})
+
+		It("sweeps on each tick", func() {
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+			Expect(datastore.AllCallCount()).To(Equal(0))
+
+			fakeClock.Increment(time.Minute)
+			Eventually(datastore.AllCallCount).Should(Equal(1))
+
+			fakeClock.Increment(time.Minute)
+			Eventually(datastore.AllCallCount).Should(Equal(2))
+		})
+	})
+})
@@ -10,8 +10,17 @@ import (
 	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
 )
 
+//go:generate counterfeiter -o ../fakes/egress_reloader.go --fake-name EgressReloader . egressReloader
+type egressReloader interface {
+	Reload(ns namespace.Namespace) error
+}
+
+// Reloader picks sandboxes back up when the daemon restarts: it restarts
+// their miss monitors and, when Egress is set, reapplies their egress deny
+// lists from the current configuration.
 type Reloader struct {
 	Watcher watcher.MissWatcher
+	Egress  egressReloader
 }
 
 func (r *Reloader) Callback(ns namespace.Namespace) error {
@@ -28,6 +37,13 @@ func (r *Reloader) Callback(ns namespace.Namespace) error {
 		return fmt.Errorf("start monitor: %s", err)
 	}
 
+	if r.Egress != nil {
+		err = r.Egress.Reload(ns)
+		if err != nil {
+			return fmt.Errorf("reload egress: %s", err)
+		}
+	}
+
 	return nil
 }
 
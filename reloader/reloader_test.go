@@ -14,13 +14,16 @@ var _ = Describe("Reloader", func() {
 	var (
 		monitorReloader *reloader.Reloader
 		watcher         *fakes.MissWatcher
+		egress          *fakes.EgressReloader
 		ns              *fakes.Namespace
 	)
 
 	BeforeEach(func() {
 		watcher = &fakes.MissWatcher{}
+		egress = &fakes.EgressReloader{}
 		monitorReloader = &reloader.Reloader{
 			Watcher: watcher,
+			Egress:  egress,
 		}
 		ns = &fakes.Namespace{}
 
@@ -38,6 +41,14 @@ var _ = Describe("Reloader", func() {
 			Expect(vxlanDev).To(Equal("vxlansome-sandbox"))
 		})
 
+		It("reapplies the egress deny list of the namespace", func() {
+			err := monitorReloader.Callback(ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(egress.ReloadCallCount()).To(Equal(1))
+			Expect(egress.ReloadArgsForCall(0)).To(Equal(ns))
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when the sandbox name is not valid", func() {
 				ns.NameReturns("some-invalid-name")
@@ -51,6 +62,12 @@ var _ = Describe("Reloader", func() {
 				err := monitorReloader.Callback(ns)
 				Expect(err).To(MatchError("start monitor: some-fake-error"))
 			})
+			It("returns an error when the egress deny list cannot be reapplied", func() {
+				egress.ReloadReturns(errors.New("some-fake-error"))
+
+				err := monitorReloader.Callback(ns)
+				Expect(err).To(MatchError("reload egress: some-fake-error"))
+			})
 		})
 	})
 })
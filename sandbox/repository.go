@@ -89,12 +89,21 @@ func (r *Repository) Load(sandboxRepoDir string) error {
 	return nil
 }
 
+// ForEach runs the callback against every sandbox in the repository. The
+// repository lock is only held while the sandboxes are listed so that slow
+// callbacks do not block Create and Destroy; each sandbox is locked instead
+// while its callback runs, and sandboxes destroyed in the meantime are
+// skipped.
 func (r *Repository) ForEach(s SandboxCallback) error {
 	r.Locker.Lock()
-	defer r.Locker.Unlock()
+	sandboxes := make(map[string]Sandbox, len(r.Sandboxes))
+	for name, sbox := range r.Sandboxes {
+		sandboxes[name] = sbox
+	}
+	r.Locker.Unlock()
 
-	for _, sbox := range r.Sandboxes {
-		err := s.Callback(sbox.Namespace())
+	for name, sbox := range sandboxes {
+		err := r.callback(name, sbox, s)
 		if err != nil {
 			return fmt.Errorf("callback: %s", err)
 		}
@@ -102,6 +111,18 @@ func (r *Repository) ForEach(s SandboxCallback) error {
 	return nil
 }
 
+func (r *Repository) callback(sandboxName string, sbox Sandbox, s SandboxCallback) error {
+	sbox.Lock()
+	defer sbox.Unlock()
+
+	current, err := r.Get(sandboxName)
+	if err != nil || current != sbox {
+		return nil
+	}
+
+	return s.Callback(sbox.Namespace())
+}
+
 func (r *Repository) Create(sandboxName string) (Sandbox, error) {
 	logger := r.Logger.Session("create", lager.Data{"name": sandboxName})
 	logger.Info("starting")
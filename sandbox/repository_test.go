@@ -7,6 +7,7 @@ import (
 	"path"
 
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/lib/namespace"
 	"github.com/cloudfoundry-incubator/ducati-daemon/sandbox"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -55,10 +56,12 @@ var _ = Describe("Sandbox Repository", func() {
 	})
 
 	Describe("ForEach", func() {
+		var sbox *fakes.Sandbox
+
 		BeforeEach(func() {
-			sbox, err := sandboxRepo.Create("some-sandbox-name")
-			Expect(err).NotTo(HaveOccurred())
-			Expect(sbox).NotTo(BeNil())
+			sbox = &fakes.Sandbox{}
+			sbox.NamespaceReturns(sboxNamespace)
+			sandboxRepo.Sandboxes["some-sandbox-name"] = sbox
 
 			sandboxCallback.CallbackReturns(nil)
 			sboxNamespace.NameReturns("some-sandbox-name")
@@ -74,14 +77,49 @@ var _ = Describe("Sandbox Repository", func() {
 		})
 
 		It("locks and unlocks", func() {
-			Expect(locker.LockCallCount()).To(Equal(1))
-			Expect(locker.UnlockCallCount()).To(Equal(1))
+			err := sandboxRepo.ForEach(sandboxCallback)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(locker.LockCallCount()).To(Equal(locker.UnlockCallCount()))
+		})
+
+		It("does not hold the repository lock while the callback runs", func() {
+			sandboxCallback.CallbackStub = func(namespace.Namespace) error {
+				Expect(locker.LockCallCount()).To(Equal(locker.UnlockCallCount()))
+				return nil
+			}
 
 			err := sandboxRepo.ForEach(sandboxCallback)
 			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("holds the sandbox lock while the callback runs", func() {
+			sandboxCallback.CallbackStub = func(namespace.Namespace) error {
+				Expect(sbox.LockCallCount()).To(Equal(1))
+				Expect(sbox.UnlockCallCount()).To(Equal(0))
+				return nil
+			}
+
+			err := sandboxRepo.ForEach(sandboxCallback)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sbox.UnlockCallCount()).To(Equal(1))
+		})
 
-			Expect(locker.LockCallCount()).To(Equal(2))
-			Expect(locker.UnlockCallCount()).To(Equal(2))
+		Context("when the sandbox is destroyed before it is locked", func() {
+			BeforeEach(func() {
+				sbox.LockStub = func() {
+					delete(sandboxRepo.Sandboxes, "some-sandbox-name")
+				}
+			})
+
+			It("skips it", func() {
+				err := sandboxRepo.ForEach(sandboxCallback)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(sandboxCallback.CallbackCallCount()).To(Equal(0))
+				Expect(sbox.UnlockCallCount()).To(Equal(1))
+			})
 		})
 
 		Context("when the callback fails", func() {
@@ -0,0 +1,96 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Container events", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		dataStore    store.Store
+		listener     *pq.Listener
+		container    models.Container
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		dataStore, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		listener = pq.NewListener(testDatabase.URL(), 10*time.Millisecond, time.Second, nil)
+		Expect(listener.Listen(store.ContainerEventChannel)).To(Succeed())
+
+		container = models.Container{
+			ID:          "some-id",
+			IP:          "192.168.1.2",
+			MAC:         "aa:bb:cc:dd:ee:ff",
+			HostIP:      "10.0.0.1",
+			NetworkID:   "some-network-id",
+			SandboxName: "vni-1",
+			App:         "some-app",
+		}
+	})
+
+	AfterEach(func() {
+		if listener != nil {
+			Expect(listener.Close()).To(Succeed())
+		}
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	receiveEvent := func() models.ContainerEvent {
+		var notification *pq.Notification
+		Eventually(listener.NotificationChannel()).Should(Receive(&notification))
+		Expect(notification.Channel).To(Equal(store.ContainerEventChannel))
+
+		var event models.ContainerEvent
+		Expect(json.Unmarshal([]byte(notification.Extra), &event)).To(Succeed())
+		return event
+	}
+
+	It("notifies when a container is created", func() {
+		Expect(dataStore.Create(container)).To(Succeed())
+
+		Expect(receiveEvent()).To(Equal(models.ContainerEvent{
+			Action:    models.ContainerCreated,
+			Container: container,
+		}))
+	})
+
+	It("notifies when a container is deleted", func() {
+		Expect(dataStore.Create(container)).To(Succeed())
+		receiveEvent()
+
+		Expect(dataStore.Delete(container.ID)).To(Succeed())
+
+		Expect(receiveEvent()).To(Equal(models.ContainerEvent{
+			Action:    models.ContainerDeleted,
+			Container: container,
+		}))
+	})
+})
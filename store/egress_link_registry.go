@@ -0,0 +1,173 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// egressLinkLockID is the first key of the postgres advisory lock that
+// serializes egress link changes on a host; the second is derived from the
+// host address.
+const egressLinkLockID = 0x65677265
+
+var EgressLinksExhaustedError = errors.New("no egress link subnets left on this host")
+var EgressLinkConflictError = errors.New("egress link subnet is already in use on this host")
+
+//go:generate counterfeiter -o ../fakes/egress_link_registry.go --fake-name EgressLinkRegistry . EgressLinkRegistry
+type EgressLinkRegistry interface {
+	Acquire(sandboxName string, hostForwarding bool) (int, error)
+	Release(sandboxName string) (bool, bool, error)
+	Links() (map[string]int, error)
+}
+
+type egressLinkRegistry struct {
+	conn   db
+	hostIP string
+	links  int
+}
+
+// NewEgressLinkRegistry returns a registry that hands out the egress link
+// subnets of a host, numbered from zero up to links, from the egress_link
+// table so that no two sandboxes on the host ever share one.
+func NewEgressLinkRegistry(dbConnectionPool db, hostIP string, links int) EgressLinkRegistry {
+	return &egressLinkRegistry{
+		conn:   dbConnectionPool,
+		hostIP: hostIP,
+		links:  links,
+	}
+}
+
+// Acquire returns the link held by the sandbox, or else the lowest free one.
+// hostForwarding records whether the host was forwarding before egress was
+// set up, so that Release can tell when to turn forwarding back off.
+func (r *egressLinkRegistry) Acquire(sandboxName string, hostForwarding bool) (int, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1, hashtext($2))", egressLinkLockID, r.hostIP)
+	if err != nil {
+		return 0, fmt.Errorf("acquiring lock: %s", err)
+	}
+
+	var held []int
+	err = tx.Select(&held, "SELECT link_index FROM egress_link WHERE host_ip=$1 AND sandbox_name=$2", r.hostIP, sandboxName)
+	if err != nil {
+		return 0, fmt.Errorf("reading egress link: %s", err)
+	}
+	if len(held) > 0 {
+		return held[0], nil
+	}
+
+	var taken []int
+	err = tx.Select(&taken, "SELECT link_index FROM egress_link WHERE host_ip=$1 ORDER BY link_index", r.hostIP)
+	if err != nil {
+		return 0, fmt.Errorf("listing egress links: %s", err)
+	}
+
+	link := 0
+	for _, t := range taken {
+		if t != link {
+			break
+		}
+		link++
+	}
+	if link >= r.links {
+		return 0, EgressLinksExhaustedError
+	}
+
+	_, err = tx.Exec(`
+	INSERT INTO egress_link (host_ip, sandbox_name, link_index, host_forwarding)
+	VALUES ($1, $2, $3, $4)`, r.hostIP, sandboxName, link, hostForwarding)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return 0, fmt.Errorf("inserting egress link: %s", err)
+		}
+		if pqErr.Code.Name() == "unique_violation" {
+			return 0, EgressLinkConflictError
+		}
+		return 0, fmt.Errorf("inserting egress link: %s", pqErr.Code.Name())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("commit: %s", err)
+	}
+
+	return link, nil
+}
+
+// Release gives up the link held by the sandbox. It reports whether that was
+// the last egress link on the host, and if so whether the host was
+// forwarding before egress was first set up. Releasing a sandbox that holds
+// no link reports false.
+func (r *egressLinkRegistry) Release(sandboxName string) (bool, bool, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1, hashtext($2))", egressLinkLockID, r.hostIP)
+	if err != nil {
+		return false, false, fmt.Errorf("acquiring lock: %s", err)
+	}
+
+	var released []bool
+	err = tx.Select(&released, `
+	DELETE FROM egress_link WHERE host_ip=$1 AND sandbox_name=$2
+	RETURNING host_forwarding`, r.hostIP, sandboxName)
+	if err != nil {
+		return false, false, fmt.Errorf("deleting egress link: %s", err)
+	}
+	if len(released) == 0 {
+		return false, false, nil
+	}
+	hostForwarding := released[0]
+
+	if !hostForwarding {
+		// links set up later saw the forwarding this one turned on; hand the
+		// original setting to them
+		_, err = tx.Exec("UPDATE egress_link SET host_forwarding=false WHERE host_ip=$1", r.hostIP)
+		if err != nil {
+			return false, false, fmt.Errorf("updating egress links: %s", err)
+		}
+	}
+
+	var remaining int
+	err = tx.Get(&remaining, "SELECT COUNT(*) FROM egress_link WHERE host_ip=$1", r.hostIP)
+	if err != nil {
+		return false, false, fmt.Errorf("counting egress links: %s", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, false, fmt.Errorf("commit: %s", err)
+	}
+
+	return remaining == 0, hostForwarding, nil
+}
+
+// Links returns the link held by each sandbox on the host.
+func (r *egressLinkRegistry) Links() (map[string]int, error) {
+	var rows []struct {
+		SandboxName string `db:"sandbox_name"`
+		LinkIndex   int    `db:"link_index"`
+	}
+	err := r.conn.Select(&rows, "SELECT sandbox_name, link_index FROM egress_link WHERE host_ip=$1", r.hostIP)
+	if err != nil {
+		return nil, fmt.Errorf("listing egress links: %s", err)
+	}
+
+	links := map[string]int{}
+	for _, row := range rows {
+		links[row.SandboxName] = row.LinkIndex
+	}
+
+	return links, nil
+}
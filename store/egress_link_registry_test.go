@@ -0,0 +1,195 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressLinkRegistry", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		registry     store.EgressLinkRegistry
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry = store.NewEgressLinkRegistry(realDb, "10.0.0.1", 3)
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("hands out the lowest free link", func() {
+		link, err := registry.Acquire("vni-7", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(Equal(0))
+
+		link, err = registry.Acquire("vni-3", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(Equal(1))
+
+		_, _, err = registry.Release("vni-7")
+		Expect(err).NotTo(HaveOccurred())
+
+		link, err = registry.Acquire("vni-9", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(Equal(0))
+	})
+
+	It("keeps returning the link a sandbox holds", func() {
+		_, err := registry.Acquire("vni-7", false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = registry.Acquire("vni-3", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		link, err := registry.Acquire("vni-3", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(Equal(1))
+	})
+
+	It("does not share links between sandboxes whose VNIs used to collide", func() {
+		first, err := registry.Acquire("vni-1", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := registry.Acquire("vni-4", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).NotTo(Equal(first))
+	})
+
+	It("hands out links per host", func() {
+		other := store.NewEgressLinkRegistry(realDb, "10.0.0.2", 3)
+
+		_, err := registry.Acquire("vni-7", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		link, err := other.Acquire("vni-7", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(Equal(0))
+	})
+
+	Context("when every link is taken", func() {
+		It("returns a meaningful error", func() {
+			for _, name := range []string{"vni-1", "vni-2", "vni-3"} {
+				_, err := registry.Acquire(name, false)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err := registry.Acquire("vni-4", false)
+			Expect(err).To(Equal(store.EgressLinksExhaustedError))
+		})
+	})
+
+	Context("when a link is already recorded for another sandbox", func() {
+		It("never records it for a second sandbox", func() {
+			_, err := realDb.Exec(`
+			INSERT INTO egress_link (host_ip, sandbox_name, link_index, host_forwarding)
+			VALUES ('10.0.0.1', 'vni-1', 1, false)`)
+			Expect(err).NotTo(HaveOccurred())
+
+			link, err := registry.Acquire("vni-2", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal(0))
+
+			_, err = realDb.Exec("UPDATE egress_link SET link_index=0 WHERE sandbox_name='vni-1'")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Release", func() {
+		It("reports the last link on the host and the forwarding before egress", func() {
+			_, err := registry.Acquire("vni-1", false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = registry.Acquire("vni-2", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			last, _, err := registry.Release("vni-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(last).To(BeFalse())
+
+			last, hostForwarding, err := registry.Release("vni-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(last).To(BeTrue())
+			Expect(hostForwarding).To(BeFalse())
+		})
+
+		It("reports when the host was forwarding before egress", func() {
+			_, err := registry.Acquire("vni-1", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			last, hostForwarding, err := registry.Release("vni-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(last).To(BeTrue())
+			Expect(hostForwarding).To(BeTrue())
+		})
+
+		It("reports nothing for a sandbox without a link", func() {
+			last, _, err := registry.Release("vni-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(last).To(BeFalse())
+		})
+	})
+
+	Describe("Links", func() {
+		It("lists the link of each sandbox on the host", func() {
+			_, err := registry.Acquire("vni-1", false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = registry.Acquire("vni-2", false)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = store.NewEgressLinkRegistry(realDb, "10.0.0.2", 3).Acquire("vni-3", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			links, err := registry.Links()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(links).To(Equal(map[string]int{"vni-1": 0, "vni-2": 1}))
+		})
+	})
+
+	Context("when the database fails", func() {
+		var mockDb *fakes.Db
+
+		BeforeEach(func() {
+			mockDb = &fakes.Db{}
+			mockDb.BeginxReturns(nil, errors.New("potato"))
+			mockDb.SelectReturns(errors.New("potato"))
+			registry = store.NewEgressLinkRegistry(mockDb, "10.0.0.1", 3)
+		})
+
+		It("returns the error", func() {
+			_, err := registry.Acquire("vni-1", false)
+			Expect(err).To(MatchError("begin transaction: potato"))
+
+			_, _, err = registry.Release("vni-1")
+			Expect(err).To(MatchError("begin transaction: potato"))
+
+			_, err = registry.Links()
+			Expect(err).To(MatchError("listing egress links: potato"))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+//go:generate counterfeiter -o ../fakes/lease_store.go --fake-name LeaseStore . LeaseStore
+type LeaseStore interface {
+	Renew(networkID, containerID string, at time.Time) error
+	RenewedAt(networkID, containerID string) (time.Time, error)
+}
+
+type leaseStore struct {
+	conn db
+}
+
+// NewLeaseStore returns a store for the lease timestamps kept on each IP
+// reservation. A lease is renewed whenever the container that holds the
+// reservation is seen to still be attached.
+func NewLeaseStore(dbConnectionPool db) LeaseStore {
+	return &leaseStore{conn: dbConnectionPool}
+}
+
+func (s *leaseStore) Renew(networkID, containerID string, at time.Time) error {
+	_, err := s.conn.Exec(`
+	UPDATE ip_reservation SET renewed_at=$3
+	WHERE network_id=$1 AND container_id=$2`, networkID, containerID, at)
+	if err != nil {
+		return fmt.Errorf("renewing: %s", err)
+	}
+
+	return nil
+}
+
+// RenewedAt returns the last time the container's reservations on the
+// network were renewed, or RecordNotFoundError when it holds none.
+func (s *leaseStore) RenewedAt(networkID, containerID string) (time.Time, error) {
+	var renewedAt time.Time
+	err := s.conn.Get(&renewedAt, `
+	SELECT renewed_at FROM ip_reservation
+	WHERE network_id=$1 AND container_id=$2
+	ORDER BY renewed_at DESC LIMIT 1`, networkID, containerID)
+	if err == sql.ErrNoRows {
+		return time.Time{}, RecordNotFoundError
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading lease: %s", err)
+	}
+
+	return renewedAt, nil
+}
@@ -0,0 +1,103 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+)
+
+var _ = Describe("LeaseStore", func() {
+	var (
+		testDatabase     *testsupport.TestDatabase
+		realDb           *sqlx.DB
+		mockDb           *fakes.Db
+		fakeClock        *fakeclock.FakeClock
+		reservationStore ipam.AllocatorStore
+		leaseStore       store.LeaseStore
+	)
+
+	BeforeEach(func() {
+		mockDb = &fakes.Db{}
+
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeClock = fakeclock.NewFakeClock(time.Unix(1000000, 0))
+		reservationStore = store.NewQuarantiningReservationStore(realDb, "some-network-id", fakeClock, 0)
+		leaseStore = store.NewLeaseStore(realDb)
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("starts the lease when the address is reserved", func() {
+		ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		renewedAt, err := leaseStore.RenewedAt("some-network-id", "some-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(renewedAt.Equal(fakeClock.Now())).To(BeTrue())
+	})
+
+	It("renews the lease", func() {
+		ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		later := fakeClock.Now().Add(time.Hour)
+		Expect(leaseStore.Renew("some-network-id", "some-id", later)).To(Succeed())
+
+		renewedAt, err := leaseStore.RenewedAt("some-network-id", "some-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(renewedAt.Equal(later)).To(BeTrue())
+	})
+
+	Context("when the container holds no reservation", func() {
+		It("returns a RecordNotFoundError", func() {
+			_, err := leaseStore.RenewedAt("some-network-id", "some-id")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+		})
+	})
+
+	Context("when the db operations fail", func() {
+		BeforeEach(func() {
+			mockDb.ExecReturns(nil, errors.New("some update error"))
+			mockDb.GetReturns(errors.New("some get error"))
+		})
+
+		It("returns sensible errors", func() {
+			err := store.NewLeaseStore(mockDb).Renew("some-network-id", "some-id", time.Now())
+			Expect(err).To(MatchError("renewing: some update error"))
+
+			_, err = store.NewLeaseStore(mockDb).RenewedAt("some-network-id", "some-id")
+			Expect(err).To(MatchError("reading lease: some get error"))
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Kinds of rows in the network_mapping table.
+const (
+	AppMapping   = "app"
+	SpaceMapping = "space"
+)
+
+type MappingStore interface {
+	Lookup(kind, key string) (string, error)
+}
+
+type mappingStore struct {
+	conn db
+}
+
+// NewMappingStore returns a store for the network_mapping table, which maps
+// an app or space guid to the network its containers are put on.
+func NewMappingStore(dbConnectionPool db) MappingStore {
+	return &mappingStore{conn: dbConnectionPool}
+}
+
+func (s *mappingStore) Lookup(kind, key string) (string, error) {
+	var networkID string
+	err := s.conn.Get(&networkID, "SELECT network_id FROM network_mapping WHERE kind=$1 AND key=$2", kind, key)
+	if err == sql.ErrNoRows {
+		return "", RecordNotFoundError
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading mapping: %s", err)
+	}
+
+	return networkID, nil
+}
@@ -0,0 +1,79 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MappingStore", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		mappingStore store.MappingStore
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		mappingStore = store.NewMappingStore(realDb)
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("looks up the network of an app or space", func() {
+		_, err := realDb.Exec(`INSERT INTO network_mapping (kind, key, network_id) VALUES
+			('app', 'some-guid', 'app-network'),
+			('space', 'some-guid', 'space-network')`)
+		Expect(err).NotTo(HaveOccurred())
+
+		networkID, err := mappingStore.Lookup(store.AppMapping, "some-guid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("app-network"))
+
+		networkID, err = mappingStore.Lookup(store.SpaceMapping, "some-guid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networkID).To(Equal("space-network"))
+	})
+
+	Context("when there is no mapping", func() {
+		It("returns a RecordNotFoundError", func() {
+			_, err := mappingStore.Lookup(store.AppMapping, "some-guid")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+		})
+	})
+
+	Context("when the db operation fails", func() {
+		It("returns a sensible error", func() {
+			mockDb := &fakes.Db{}
+			mockDb.GetReturns(errors.New("some get error"))
+
+			_, err := store.NewMappingStore(mockDb).Lookup(store.AppMapping, "some-guid")
+			Expect(err).To(MatchError("reading mapping: some get error"))
+		})
+	})
+})
@@ -0,0 +1,280 @@
+package store
+
+import "fmt"
+
+// migrationLockID is the key of the postgres advisory lock that serializes
+// migrations across every ducatid sharing the database.
+const migrationLockID = 0x64756361
+
+const schemaVersionTable = `
+CREATE TABLE IF NOT EXISTS schema_version (
+  version integer PRIMARY KEY,
+  description text,
+  applied_at timestamp DEFAULT now()
+);
+`
+
+type migration struct {
+	version     int
+	description string
+	statement   string
+}
+
+// migrations are applied in order and must never be edited once released;
+// add a new entry with the next version instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create container table",
+		statement: `
+CREATE TABLE IF NOT EXISTS container (
+  id text PRIMARY KEY,
+  ip text,
+  mac text,
+  host_ip text,
+  network_id text,
+  sandbox_name text,
+  app text
+);
+`,
+	},
+	{
+		version:     2,
+		description: "create ip_reservation table",
+		statement: `
+CREATE TABLE IF NOT EXISTS ip_reservation (
+  network_id text,
+  ip text,
+  container_id text,
+  PRIMARY KEY (network_id, ip)
+);
+`,
+	},
+	{
+		version:     3,
+		description: "index container neighbor lookups",
+		statement: `
+CREATE INDEX container_sandbox_name_ip_idx ON container (sandbox_name, ip);
+CREATE INDEX container_mac_idx ON container (mac);
+`,
+	},
+	{
+		version:     4,
+		description: "notify on container create and delete",
+		statement: `
+CREATE FUNCTION notify_container_event() RETURNS trigger AS $$
+DECLARE
+  action text;
+  changed container;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    action := 'delete';
+    changed := OLD;
+  ELSE
+    action := 'create';
+    changed := NEW;
+  END IF;
+  PERFORM pg_notify('container_event', json_build_object('action', action, 'container', row_to_json(changed))::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER container_event_notify
+  AFTER INSERT OR DELETE ON container
+  FOR EACH ROW EXECUTE PROCEDURE notify_container_event();
+`,
+	},
+	{
+		version:     5,
+		description: "record container attachment and ipam result",
+		statement: `
+ALTER TABLE container
+  ADD COLUMN container_namespace text NOT NULL DEFAULT '',
+  ADD COLUMN interface_name text NOT NULL DEFAULT '',
+  ADD COLUMN ipam_result json;
+`,
+	},
+	{
+		version:     6,
+		description: "quarantine released ip reservations",
+		statement: `
+ALTER TABLE ip_reservation ADD COLUMN quarantined_until timestamptz;
+`,
+	},
+	{
+		version:     7,
+		description: "track ip reservation leases",
+		statement: `
+ALTER TABLE ip_reservation ADD COLUMN renewed_at timestamptz NOT NULL DEFAULT now();
+`,
+	},
+	{
+		version:     8,
+		description: "create network_vni table",
+		statement: `
+CREATE TABLE IF NOT EXISTS network_vni (
+  network_id text PRIMARY KEY,
+  vni integer NOT NULL UNIQUE
+);
+`,
+	},
+	{
+		version:     9,
+		description: "create network_mapping table",
+		statement: `
+CREATE TABLE IF NOT EXISTS network_mapping (
+  kind text,
+  key text,
+  network_id text NOT NULL,
+  PRIMARY KEY (kind, key)
+);
+`,
+	},
+	{
+		version:     10,
+		description: "create network table",
+		statement: `
+CREATE TABLE IF NOT EXISTS network (
+  id text PRIMARY KEY,
+  name text NOT NULL DEFAULT '',
+  subnet text NOT NULL DEFAULT '',
+  mtu integer NOT NULL DEFAULT 0,
+  dns json NOT NULL DEFAULT '{}'
+);
+`,
+	},
+	{
+		version:     11,
+		description: "create policy table",
+		statement: `
+CREATE TABLE IF NOT EXISTS policy (
+  id text PRIMARY KEY,
+  source_app text NOT NULL,
+  destination_app text NOT NULL,
+  protocol text NOT NULL DEFAULT '',
+  port integer NOT NULL DEFAULT 0
+);
+`,
+	},
+	{
+		version:     12,
+		description: "index ip reservations by container",
+		statement: `
+CREATE INDEX ip_reservation_container_id_idx ON ip_reservation (container_id);
+`,
+	},
+	{
+		version:     13,
+		description: "record container ipv6 address",
+		statement: `
+ALTER TABLE container ADD COLUMN ip6 text NOT NULL DEFAULT '';
+UPDATE container SET ip6 = split_part(ipam_result->'ip6'->>'ip', '/', 1)
+  WHERE ipam_result->'ip6'->>'ip' IS NOT NULL;
+CREATE INDEX container_sandbox_name_ip6_idx ON container (sandbox_name, ip6);
+`,
+	},
+	{
+		version:     14,
+		description: "index ip reservations by network and container",
+		statement: `
+CREATE INDEX ip_reservation_network_id_container_id_idx ON ip_reservation (network_id, container_id);
+`,
+	},
+	{
+		version:     15,
+		description: "notify on policy create and delete",
+		statement: `
+CREATE FUNCTION notify_policy_event() RETURNS trigger AS $$
+DECLARE
+  action text;
+  changed policy;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    action := 'delete';
+    changed := OLD;
+  ELSE
+    action := 'create';
+    changed := NEW;
+  END IF;
+  PERFORM pg_notify('policy_event', json_build_object('action', action, 'policy', row_to_json(changed))::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER policy_event_notify
+  AFTER INSERT OR DELETE ON policy
+  FOR EACH ROW EXECUTE PROCEDURE notify_policy_event();
+`,
+	},
+	{
+		version:     16,
+		description: "create egress_link table",
+		statement: `
+CREATE TABLE IF NOT EXISTS egress_link (
+  host_ip text NOT NULL,
+  sandbox_name text NOT NULL,
+  link_index integer NOT NULL,
+  host_forwarding boolean NOT NULL,
+  PRIMARY KEY (host_ip, sandbox_name),
+  UNIQUE (host_ip, link_index)
+);
+`,
+	},
+	{
+		version:     17,
+		description: "record the host of ip reservations",
+		statement: `
+ALTER TABLE ip_reservation ADD COLUMN host_ip text;
+UPDATE ip_reservation r SET host_ip=c.host_ip
+FROM container c WHERE c.id=r.container_id AND c.network_id=r.network_id;
+CREATE INDEX ip_reservation_host_ip_idx ON ip_reservation (host_ip);
+`,
+	},
+}
+
+func migrate(conn db) error {
+	tx, err := conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLockID)
+	if err != nil {
+		return fmt.Errorf("acquiring lock: %s", err)
+	}
+
+	_, err = tx.Exec(schemaVersionTable)
+	if err != nil {
+		return fmt.Errorf("creating schema_version table: %s", err)
+	}
+
+	var currentVersion int
+	err = tx.Get(&currentVersion, "SELECT COALESCE(MAX(version), 0) FROM schema_version")
+	if err != nil {
+		return fmt.Errorf("reading schema version: %s", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= currentVersion {
+			continue
+		}
+
+		_, err = tx.Exec(m.statement)
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %s", m.version, m.description, err)
+		}
+
+		_, err = tx.Exec("INSERT INTO schema_version (version, description) VALUES ($1, $2)", m.version, m.description)
+		if err != nil {
+			return fmt.Errorf("recording migration %d: %s", m.version, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit: %s", err)
+	}
+
+	return nil
+}
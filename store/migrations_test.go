@@ -0,0 +1,124 @@
+package store_test
+
+import (
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrations", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	appliedVersions := func() []int {
+		var versions []int
+		Expect(realDb.Select(&versions, "SELECT version FROM schema_version ORDER BY version")).To(Succeed())
+		return versions
+	}
+
+	It("records every migration it applies", func() {
+		_, err := store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		versions := appliedVersions()
+		Expect(versions).NotTo(BeEmpty())
+		for i, v := range versions {
+			Expect(v).To(Equal(i + 1))
+		}
+	})
+
+	It("does not reapply migrations on subsequent boots", func() {
+		_, err := store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+		versions := appliedVersions()
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(appliedVersions()).To(Equal(versions))
+	})
+
+	Context("when the database predates schema versioning", func() {
+		BeforeEach(func() {
+			_, err := realDb.Exec(`
+			CREATE TABLE container (
+				id text PRIMARY KEY,
+				ip text,
+				mac text,
+				host_ip text,
+				network_id text,
+				sandbox_name text,
+				app text
+			);
+			INSERT INTO container (id, ip) VALUES ('some-legacy-id', '192.168.1.2');
+			`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("migrates without losing data", func() {
+			dataStore, err := store.New(realDb)
+			Expect(err).NotTo(HaveOccurred())
+
+			container, err := dataStore.Get("some-legacy-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container).To(Equal(models.Container{ID: "some-legacy-id", IP: "192.168.1.2"}))
+		})
+	})
+
+	Context("when several daemons boot at the same time", func() {
+		It("applies each migration exactly once", func() {
+			var wg sync.WaitGroup
+			errs := make(chan error, 5)
+
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					_, err := store.New(realDb)
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			versions := appliedVersions()
+			for i, v := range versions {
+				Expect(v).To(Equal(i + 1))
+			}
+		})
+	})
+})
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// VNIConflictError is returned when a network is defined with a VNI that
+// another network holds, or that differs from the one it already has.
+var VNIConflictError = errors.New("vni conflicts with an existing assignment")
+
+var NetworkInUseError = models.NetworkInUseError
+
+//go:generate counterfeiter -o ../fakes/network_store.go --fake-name NetworkStore . NetworkStore
+type NetworkStore interface {
+	Create(network models.Network) error
+	Get(id string) (models.Network, error)
+	All() ([]models.Network, error)
+	Delete(id string) error
+}
+
+// the VNI of a network lives in the registry's network_vni table so that
+// defined and implicit networks share a single source of truth
+const selectNetworks = `
+SELECT n.id, n.name, n.subnet, n.mtu, n.dns, COALESCE(v.vni, 0) AS vni
+FROM network n LEFT JOIN network_vni v ON v.network_id = n.id`
+
+type networkStore struct {
+	conn db
+}
+
+// NewNetworkStore returns a store for network definitions. It expects the
+// schema to have been migrated by New.
+func NewNetworkStore(dbConnectionPool db) NetworkStore {
+	return &networkStore{conn: dbConnectionPool}
+}
+
+// Create records the definition. A non-zero VNI is registered for the
+// network in the same transaction.
+func (s *networkStore) Create(network models.Network) error {
+	tx, err := s.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	INSERT INTO network (id, name, subnet, mtu, dns) VALUES ($1, $2, $3, $4, $5)`,
+		network.ID, network.Name, network.Subnet, network.MTU, network.DNS)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return fmt.Errorf("insert: %s", err)
+		}
+		if pqErr.Code.Name() == "unique_violation" {
+			return RecordExistsError
+		}
+		return fmt.Errorf("insert: %s", pqErr.Code.Name())
+	}
+
+	if network.VNI != 0 {
+		err = registerVNI(tx, network.ID, network.VNI)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit: %s", err)
+	}
+
+	return nil
+}
+
+func registerVNI(tx *sqlx.Tx, networkID string, vni int) error {
+	var existing []int
+	err := tx.Select(&existing, "SELECT vni FROM network_vni WHERE network_id=$1", networkID)
+	if err != nil {
+		return fmt.Errorf("reading vni: %s", err)
+	}
+	if len(existing) > 0 {
+		if existing[0] != vni {
+			return VNIConflictError
+		}
+		return nil
+	}
+
+	_, err = tx.Exec("INSERT INTO network_vni (network_id, vni) VALUES ($1, $2)", networkID, vni)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return fmt.Errorf("assigning vni: %s", err)
+		}
+		if pqErr.Code.Name() == "unique_violation" {
+			return VNIConflictError
+		}
+		return fmt.Errorf("assigning vni: %s", pqErr.Code.Name())
+	}
+
+	return nil
+}
+
+func (s *networkStore) Get(id string) (models.Network, error) {
+	networks := []models.Network{}
+	err := s.conn.Select(&networks, selectNetworks+" WHERE n.id=$1", id)
+	if err != nil {
+		return models.Network{}, fmt.Errorf("getting record: %s", err)
+	}
+	if len(networks) == 0 {
+		return models.Network{}, RecordNotFoundError
+	}
+
+	return networks[0], nil
+}
+
+func (s *networkStore) All() ([]models.Network, error) {
+	networks := []models.Network{}
+	err := s.conn.Select(&networks, selectNetworks+" ORDER BY n.id")
+	if err != nil {
+		return nil, fmt.Errorf("listing all: %s", err)
+	}
+
+	return networks, nil
+}
+
+// Delete removes the definition and releases the network's VNI. A network
+// with containers attached is left alone; the containers are checked for in
+// the same transaction as the delete.
+func (s *networkStore) Delete(id string) error {
+	tx, err := s.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.Get(&inUse, "SELECT EXISTS(SELECT 1 FROM container WHERE network_id=$1)", id)
+	if err != nil {
+		return fmt.Errorf("checking containers: %s", err)
+	}
+	if inUse {
+		return NetworkInUseError
+	}
+
+	execResult, err := tx.Exec("DELETE FROM network WHERE id=$1", id)
+	if err != nil {
+		return fmt.Errorf("deleting: %s", err)
+	}
+	rowsAffected, err := execResult.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deleting: rows affected: %s", err)
+	}
+	if rowsAffected == 0 {
+		return RecordNotFoundError
+	}
+
+	_, err = tx.Exec("DELETE FROM network_vni WHERE network_id=$1", id)
+	if err != nil {
+		return fmt.Errorf("releasing vni: %s", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit: %s", err)
+	}
+
+	return nil
+}
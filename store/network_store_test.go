@@ -0,0 +1,193 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NetworkStore", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		mockDb       *fakes.Db
+		networkStore store.NetworkStore
+		network      models.Network
+	)
+
+	BeforeEach(func() {
+		mockDb = &fakes.Db{}
+
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		networkStore = store.NewNetworkStore(realDb)
+
+		network = models.Network{
+			ID:     "some-network-id",
+			Name:   "some-name",
+			VNI:    42,
+			Subnet: "10.10.0.0/16",
+			MTU:    1400,
+			DNS: models.NetworkDNS{DNS: types.DNS{
+				Nameservers: []string{"10.10.0.53"},
+				Search:      []string{"apps.internal"},
+			}},
+		}
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("creates and gets a network", func() {
+		Expect(networkStore.Create(network)).To(Succeed())
+
+		fetched, err := networkStore.Get("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fetched).To(Equal(network))
+	})
+
+	It("registers the VNI of the network", func() {
+		Expect(networkStore.Create(network)).To(Succeed())
+
+		vni, err := store.NewVNIRegistry(realDb).Assign("some-network-id", 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(42))
+	})
+
+	It("reports the VNI the registry assigned to a network defined without one", func() {
+		network.VNI = 0
+		Expect(networkStore.Create(network)).To(Succeed())
+
+		_, err := store.NewVNIRegistry(realDb).Assign("some-network-id", 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		fetched, err := networkStore.Get("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fetched.VNI).To(Equal(7))
+	})
+
+	It("lists all networks", func() {
+		other := models.Network{ID: "other-network-id"}
+		Expect(networkStore.Create(other)).To(Succeed())
+		Expect(networkStore.Create(network)).To(Succeed())
+
+		networks, err := networkStore.All()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks).To(Equal([]models.Network{other, network}))
+	})
+
+	It("deletes a network and releases its VNI", func() {
+		Expect(networkStore.Create(network)).To(Succeed())
+		Expect(networkStore.Delete("some-network-id")).To(Succeed())
+
+		_, err := networkStore.Get("some-network-id")
+		Expect(err).To(Equal(store.RecordNotFoundError))
+
+		vni, err := store.NewVNIRegistry(realDb).Assign("other-network-id", 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(42))
+	})
+
+	Context("when containers are attached to the network", func() {
+		BeforeEach(func() {
+			Expect(networkStore.Create(network)).To(Succeed())
+
+			dataStore, err := store.New(realDb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataStore.Create(models.Container{
+				ID:        "some-container-id",
+				IP:        "192.168.1.2",
+				NetworkID: "some-network-id",
+			})).To(Succeed())
+		})
+
+		It("returns a NetworkInUseError and keeps the definition", func() {
+			Expect(networkStore.Delete("some-network-id")).To(Equal(store.NetworkInUseError))
+
+			_, err := networkStore.Get("some-network-id")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the network already exists", func() {
+		It("returns a RecordExistsError", func() {
+			Expect(networkStore.Create(network)).To(Succeed())
+			Expect(networkStore.Create(network)).To(Equal(store.RecordExistsError))
+		})
+	})
+
+	Context("when the VNI belongs to another network", func() {
+		It("returns a VNIConflictError and does not create the network", func() {
+			_, err := store.NewVNIRegistry(realDb).Assign("other-network-id", 42)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(networkStore.Create(network)).To(Equal(store.VNIConflictError))
+
+			_, err = networkStore.Get("some-network-id")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+		})
+	})
+
+	Context("when the network already has a different VNI", func() {
+		It("returns a VNIConflictError", func() {
+			_, err := store.NewVNIRegistry(realDb).Assign("some-network-id", 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(networkStore.Create(network)).To(Equal(store.VNIConflictError))
+		})
+	})
+
+	Context("when the network does not exist", func() {
+		It("returns a RecordNotFoundError", func() {
+			_, err := networkStore.Get("some-network-id")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+
+			Expect(networkStore.Delete("some-network-id")).To(Equal(store.RecordNotFoundError))
+		})
+	})
+
+	Context("when the db operations fail", func() {
+		BeforeEach(func() {
+			mockDb.BeginxReturns(nil, errors.New("some begin error"))
+			mockDb.SelectReturns(errors.New("some select error"))
+		})
+
+		It("returns sensible errors", func() {
+			networkStore = store.NewNetworkStore(mockDb)
+
+			Expect(networkStore.Create(network)).To(MatchError("begin transaction: some begin error"))
+			Expect(networkStore.Delete("some-network-id")).To(MatchError("begin transaction: some begin error"))
+
+			_, err := networkStore.Get("some-network-id")
+			Expect(err).To(MatchError("getting record: some select error"))
+
+			_, err = networkStore.All()
+			Expect(err).To(MatchError("listing all: some select error"))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy events", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		policyStore  store.PolicyStore
+		listener     *pq.Listener
+		policy       models.Policy
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+		policyStore = store.NewPolicyStore(realDb)
+
+		listener = pq.NewListener(testDatabase.URL(), 10*time.Millisecond, time.Second, nil)
+		Expect(listener.Listen(store.PolicyEventChannel)).To(Succeed())
+
+		policy = models.Policy{
+			ID:          "some-policy",
+			Source:      "frontend",
+			Destination: "backend",
+			Protocol:    "tcp",
+			Port:        8080,
+		}
+	})
+
+	AfterEach(func() {
+		if listener != nil {
+			Expect(listener.Close()).To(Succeed())
+		}
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	type policyEvent struct {
+		Action string        `json:"action"`
+		Policy models.Policy `json:"policy"`
+	}
+
+	receiveEvent := func() policyEvent {
+		var notification *pq.Notification
+		Eventually(listener.NotificationChannel()).Should(Receive(&notification))
+		Expect(notification.Channel).To(Equal(store.PolicyEventChannel))
+
+		var event policyEvent
+		Expect(json.Unmarshal([]byte(notification.Extra), &event)).To(Succeed())
+		return event
+	}
+
+	It("notifies when a policy is created", func() {
+		Expect(policyStore.Create(policy)).To(Succeed())
+
+		event := receiveEvent()
+		Expect(event.Action).To(Equal("create"))
+		Expect(event.Policy.ID).To(Equal("some-policy"))
+	})
+
+	It("notifies when a policy is deleted", func() {
+		Expect(policyStore.Create(policy)).To(Succeed())
+		receiveEvent()
+
+		Expect(policyStore.Delete(policy.ID)).To(Succeed())
+
+		event := receiveEvent()
+		Expect(event.Action).To(Equal("delete"))
+		Expect(event.Policy.ID).To(Equal("some-policy"))
+	})
+})
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/lib/pq"
+)
+
+// PolicyEventChannel is the channel the policy table triggers NOTIFY
+// whenever a policy is created or deleted.
+const PolicyEventChannel = "policy_event"
+
+//go:generate counterfeiter -o ../fakes/policy_store.go --fake-name PolicyStore . PolicyStore
+type PolicyStore interface {
+	Create(policy models.Policy) error
+	All() ([]models.Policy, error)
+	Delete(id string) error
+}
+
+type policyStore struct {
+	conn db
+}
+
+// NewPolicyStore returns a store for the policies that govern traffic
+// between apps. It expects the schema to have been migrated by New.
+func NewPolicyStore(dbConnectionPool db) PolicyStore {
+	return &policyStore{conn: dbConnectionPool}
+}
+
+func (s *policyStore) Create(policy models.Policy) error {
+	_, err := s.conn.NamedExec(`
+	INSERT INTO policy (id, source_app, destination_app, protocol, port)
+	VALUES (:id, :source_app, :destination_app, :protocol, :port)`, &policy)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return fmt.Errorf("insert: %s", err)
+		}
+		if pqErr.Code.Name() == "unique_violation" {
+			return RecordExistsError
+		}
+		return fmt.Errorf("insert: %s", pqErr.Code.Name())
+	}
+
+	return nil
+}
+
+func (s *policyStore) All() ([]models.Policy, error) {
+	policies := []models.Policy{}
+	err := s.conn.Select(&policies, "SELECT id, source_app, destination_app, protocol, port FROM policy ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("listing all: %s", err)
+	}
+
+	return policies, nil
+}
+
+func (s *policyStore) Delete(id string) error {
+	execResult, err := s.conn.Exec("DELETE FROM policy WHERE id=$1", id)
+	if err != nil {
+		return fmt.Errorf("deleting: %s", err)
+	}
+
+	rowsAffected, err := execResult.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deleting: rows affected: %s", err)
+	}
+	if rowsAffected == 0 {
+		return RecordNotFoundError
+	}
+
+	return nil
+}
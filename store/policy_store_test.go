@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PolicyStore", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		mockDb       *fakes.Db
+		policyStore  store.PolicyStore
+		policy       models.Policy
+	)
+
+	BeforeEach(func() {
+		mockDb = &fakes.Db{}
+
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		policyStore = store.NewPolicyStore(realDb)
+
+		policy = models.Policy{
+			ID:          "some-policy-id",
+			Source:      "some-app-guid",
+			Destination: "some-other-app-guid",
+			Protocol:    "tcp",
+			Port:        8080,
+		}
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("creates and lists policies", func() {
+		other := models.Policy{ID: "other-policy-id", Source: "a", Destination: "b"}
+		Expect(policyStore.Create(policy)).To(Succeed())
+		Expect(policyStore.Create(other)).To(Succeed())
+
+		policies, err := policyStore.All()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(Equal([]models.Policy{other, policy}))
+	})
+
+	It("deletes a policy", func() {
+		Expect(policyStore.Create(policy)).To(Succeed())
+		Expect(policyStore.Delete("some-policy-id")).To(Succeed())
+
+		policies, err := policyStore.All()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(BeEmpty())
+	})
+
+	Context("when the policy already exists", func() {
+		It("returns a RecordExistsError", func() {
+			Expect(policyStore.Create(policy)).To(Succeed())
+			Expect(policyStore.Create(policy)).To(Equal(store.RecordExistsError))
+		})
+	})
+
+	Context("when the policy does not exist", func() {
+		It("returns a RecordNotFoundError", func() {
+			Expect(policyStore.Delete("some-policy-id")).To(Equal(store.RecordNotFoundError))
+		})
+	})
+
+	Context("when the db operations fail", func() {
+		BeforeEach(func() {
+			mockDb.NamedExecReturns(nil, errors.New("some insert error"))
+			mockDb.SelectReturns(errors.New("some select error"))
+			mockDb.ExecReturns(nil, errors.New("some delete error"))
+		})
+
+		It("returns sensible errors", func() {
+			policyStore = store.NewPolicyStore(mockDb)
+
+			Expect(policyStore.Create(policy)).To(MatchError("insert: some insert error"))
+			Expect(policyStore.Delete("some-policy-id")).To(MatchError("deleting: some delete error"))
+
+			_, err := policyStore.All()
+			Expect(err).To(MatchError("listing all: some select error"))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+//go:generate counterfeiter -o ../fakes/reservation_index.go --fake-name ReservationIndex . ReservationIndex
+type ReservationIndex interface {
+	Networks(containerID string) ([]string, error)
+	PruneOrphans(hostIP string, renewedBefore time.Time) (int64, error)
+	QuarantineOrphans(hostIP string, renewedBefore, quarantinedUntil time.Time) (int64, error)
+}
+
+type reservationIndex struct {
+	conn db
+}
+
+// NewReservationIndex returns an index of IP reservations by container, for
+// finding the addresses a container holds when its record is gone.
+func NewReservationIndex(dbConnectionPool db) ReservationIndex {
+	return &reservationIndex{conn: dbConnectionPool}
+}
+
+// Networks returns the networks on which the container holds addresses.
+func (i *reservationIndex) Networks(containerID string) ([]string, error) {
+	networkIDs := []string{}
+	err := i.conn.Select(&networkIDs, `
+	SELECT DISTINCT network_id FROM ip_reservation
+	WHERE container_id=$1 ORDER BY network_id`, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("listing networks: %s", err)
+	}
+
+	return networkIDs, nil
+}
+
+// orphaned matches the reservations made by the host in $1 whose container
+// has no record on the reservation's network and that were last renewed
+// before $2. Reservations renewed since are left alone, so that an ADD that
+// has reserved its address but not yet written its record keeps it.
+// Reservations made before hosts were recorded are never matched.
+const orphaned = `
+	r.host_ip=$1 AND r.container_id IS NOT NULL AND r.renewed_at < $2
+	AND NOT EXISTS (
+		SELECT 1 FROM container c
+		WHERE c.id=r.container_id AND c.network_id=r.network_id
+	)`
+
+// PruneOrphans deletes the orphaned reservations made by the host.
+func (i *reservationIndex) PruneOrphans(hostIP string, renewedBefore time.Time) (int64, error) {
+	result, err := i.conn.Exec(`
+	DELETE FROM ip_reservation r WHERE`+orphaned, hostIP, renewedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("pruning orphans: %s", err)
+	}
+
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("pruning orphans: %s", err) // not tested
+	}
+
+	return pruned, nil
+}
+
+// QuarantineOrphans releases the orphaned reservations made by the host into
+// quarantine until quarantinedUntil, as if their containers had been deleted.
+func (i *reservationIndex) QuarantineOrphans(hostIP string, renewedBefore, quarantinedUntil time.Time) (int64, error) {
+	result, err := i.conn.Exec(`
+	UPDATE ip_reservation r SET container_id=NULL, quarantined_until=$3 WHERE`+orphaned,
+		hostIP, renewedBefore, quarantinedUntil)
+	if err != nil {
+		return 0, fmt.Errorf("quarantining orphans: %s", err)
+	}
+
+	quarantined, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("quarantining orphans: %s", err) // not tested
+	}
+
+	return quarantined, nil
+}
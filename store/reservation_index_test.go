@@ -0,0 +1,192 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReservationIndex", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		index        store.ReservationIndex
+	)
+
+	BeforeEach(func() {
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		index = store.NewReservationIndex(realDb)
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("returns the networks the container holds addresses on", func() {
+		reservations := []struct {
+			networkID, containerID, ip string
+		}{
+			{"network-b", "some-id", "192.168.1.2"},
+			{"network-b", "some-id", "fd00::2"},
+			{"network-a", "some-id", "192.168.2.2"},
+			{"network-a", "some-other-id", "192.168.2.3"},
+		}
+		for _, r := range reservations {
+			ok, err := store.NewReservationStore(realDb, r.networkID).Reserve(r.containerID, net.ParseIP(r.ip))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		}
+
+		Expect(index.Networks("some-id")).To(Equal([]string{"network-a", "network-b"}))
+		Expect(index.Networks("some-other-id")).To(Equal([]string{"network-a"}))
+	})
+
+	Context("when the container holds nothing", func() {
+		It("returns no networks", func() {
+			Expect(index.Networks("some-id")).To(BeEmpty())
+		})
+	})
+
+	Context("when the db operation fails", func() {
+		It("returns a sensible error", func() {
+			mockDb := &fakes.Db{}
+			mockDb.SelectReturns(errors.New("some select error"))
+
+			_, err := store.NewReservationIndex(mockDb).Networks("some-id")
+			Expect(err).To(MatchError("listing networks: some select error"))
+		})
+	})
+	Describe("orphans", func() {
+		var longAgo, recently, cutoff time.Time
+
+		BeforeEach(func() {
+			now := time.Now()
+			longAgo = now.Add(-time.Hour)
+			recently = now.Add(-time.Second)
+			cutoff = now.Add(-time.Minute)
+
+			dataStore, err := store.New(realDb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataStore.Create(models.Container{
+				ID:        "recorded-id",
+				IP:        "192.168.1.2",
+				NetworkID: "network-a",
+				HostIP:    "10.0.0.1",
+			})).To(Succeed())
+
+			held := []struct {
+				hostIP, networkID, containerID, ip string
+				renewedAt                          time.Time
+			}{
+				{"10.0.0.1", "network-a", "recorded-id", "192.168.1.2", longAgo},
+				{"10.0.0.1", "network-b", "recorded-id", "192.168.2.2", longAgo},
+				{"10.0.0.1", "network-a", "orphaned-id", "192.168.1.3", longAgo},
+				{"10.0.0.1", "network-a", "in-flight-id", "192.168.1.4", recently},
+				{"10.0.0.2", "network-a", "other-host-id", "192.168.1.5", longAgo},
+			}
+			leases := store.NewLeaseStore(realDb)
+			for _, r := range held {
+				factory := &store.ReservationStoreFactory{DB: realDb, HostIP: r.hostIP}
+				reservations, err := factory.Create(r.networkID)
+				Expect(err).NotTo(HaveOccurred())
+
+				ok, err := reservations.Reserve(r.containerID, net.ParseIP(r.ip))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(leases.Renew(r.networkID, r.containerID, r.renewedAt)).To(Succeed())
+			}
+		})
+
+		Describe("PruneOrphans", func() {
+			It("deletes the host's reservations without a container record on their network", func() {
+				pruned, err := index.PruneOrphans("10.0.0.1", cutoff)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pruned).To(Equal(int64(2)))
+
+				Expect(index.Networks("recorded-id")).To(Equal([]string{"network-a"}))
+				Expect(index.Networks("orphaned-id")).To(BeEmpty())
+			})
+
+			It("keeps reservations renewed after the cutoff", func() {
+				_, err := index.PruneOrphans("10.0.0.1", cutoff)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(index.Networks("in-flight-id")).To(Equal([]string{"network-a"}))
+			})
+
+			It("keeps the reservations of other hosts", func() {
+				_, err := index.PruneOrphans("10.0.0.1", cutoff)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(index.Networks("other-host-id")).To(Equal([]string{"network-a"}))
+			})
+
+			Context("when the db operation fails", func() {
+				It("returns a sensible error", func() {
+					mockDb := &fakes.Db{}
+					mockDb.ExecReturns(nil, errors.New("some delete error"))
+
+					_, err := store.NewReservationIndex(mockDb).PruneOrphans("10.0.0.1", cutoff)
+					Expect(err).To(MatchError("pruning orphans: some delete error"))
+				})
+			})
+		})
+
+		Describe("QuarantineOrphans", func() {
+			It("releases the host's orphaned reservations into quarantine", func() {
+				quarantined, err := index.QuarantineOrphans("10.0.0.1", cutoff, time.Now().Add(time.Hour))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(quarantined).To(Equal(int64(2)))
+
+				Expect(index.Networks("orphaned-id")).To(BeEmpty())
+				Expect(index.Networks("in-flight-id")).To(Equal([]string{"network-a"}))
+				Expect(index.Networks("other-host-id")).To(Equal([]string{"network-a"}))
+			})
+
+			It("does not hand the quarantined addresses out again", func() {
+				_, err := index.QuarantineOrphans("10.0.0.1", cutoff, time.Now().Add(time.Hour))
+				Expect(err).NotTo(HaveOccurred())
+
+				ok, err := store.NewReservationStore(realDb, "network-a").Reserve("new-id", net.ParseIP("192.168.1.3"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+
+			Context("when the db operation fails", func() {
+				It("returns a sensible error", func() {
+					mockDb := &fakes.Db{}
+					mockDb.ExecReturns(nil, errors.New("some update error"))
+
+					_, err := store.NewReservationIndex(mockDb).QuarantineOrphans("10.0.0.1", cutoff, time.Now())
+					Expect(err).To(MatchError("quarantining orphans: some update error"))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,277 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/lib/pq"
+	"github.com/pivotal-golang/clock"
+)
+
+// ReservationStoreFactory creates stores that quarantine released addresses
+// for Quarantine before handing them out again. A zero Quarantine releases
+// addresses immediately. Reservations are recorded as made by HostIP, so
+// that each host only ever prunes its own.
+type ReservationStoreFactory struct {
+	DB         db
+	Clock      clock.Clock
+	Quarantine time.Duration
+	HostIP     string
+}
+
+func (f *ReservationStoreFactory) Create(networkID string) (ipam.AllocatorStore, error) {
+	c := f.Clock
+	if c == nil {
+		c = clock.NewClock()
+	}
+
+	return newReservationStore(f.DB, networkID, f.HostIP, c, f.Quarantine), nil
+}
+
+type reservationStore struct {
+	conn       db
+	networkID  string
+	hostIP     string
+	clock      clock.Clock
+	quarantine time.Duration
+
+	lock    sync.Mutex
+	bitmaps map[string]*poolBitmap
+}
+
+// poolBitmap caches which addresses of a pool are taken, and where the
+// next-fit search left off, between calls to ReserveNext.
+type poolBitmap struct {
+	pool   *ipam.Pool
+	bitmap *ipam.Bitmap
+}
+
+func NewReservationStore(dbConnectionPool db, networkID string) ipam.AllocatorStore {
+	return NewQuarantiningReservationStore(dbConnectionPool, networkID, clock.NewClock(), 0)
+}
+
+// NewQuarantiningReservationStore returns a store whose released addresses
+// stay in the ip_reservation table, without an owner, until the quarantine
+// has passed, so that remote hosts have time to forget the old neighbor
+// entries before the address belongs to somebody else.
+func NewQuarantiningReservationStore(dbConnectionPool db, networkID string, clock clock.Clock, quarantine time.Duration) ipam.AllocatorStore {
+	return newReservationStore(dbConnectionPool, networkID, "", clock, quarantine)
+}
+
+func newReservationStore(dbConnectionPool db, networkID, hostIP string, clock clock.Clock, quarantine time.Duration) *reservationStore {
+	return &reservationStore{
+		conn:       dbConnectionPool,
+		networkID:  networkID,
+		hostIP:     hostIP,
+		clock:      clock,
+		quarantine: quarantine,
+		bitmaps:    map[string]*poolBitmap{},
+	}
+}
+
+// Reserve claims the address for the id. An address that is still in
+// quarantine is not claimed, even when the caller asks for it by name.
+func (s *reservationStore) Reserve(id string, ip net.IP) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reserved, err := s.reserve(id, ip)
+	if err != nil || !reserved {
+		return reserved, err
+	}
+
+	for _, pb := range s.bitmaps {
+		if offset, ok := pb.pool.Offset(ip); ok {
+			pb.bitmap.Set(offset)
+		}
+	}
+
+	return true, nil
+}
+
+func (s *reservationStore) reserve(id string, ip net.IP) (bool, error) {
+	_, err := s.conn.Exec(`
+	INSERT INTO ip_reservation (
+		network_id, ip, container_id, renewed_at, host_ip
+	) VALUES (
+		$1, $2, $3, $4, NULLIF($5, '')
+	)`, s.networkID, ip.String(), id, s.clock.Now(), s.hostIP)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return false, fmt.Errorf("insert: %s", err)
+		}
+		if pqErr.Code.Name() == "unique_violation" {
+			return s.reclaim(id, ip)
+		}
+		return false, fmt.Errorf("insert: %s", pqErr.Code.Name())
+	}
+
+	return true, nil
+}
+
+// reclaim takes over a released address that is still in the table once
+// its quarantine has passed.
+func (s *reservationStore) reclaim(id string, ip net.IP) (bool, error) {
+	result, err := s.conn.Exec(`
+	UPDATE ip_reservation SET container_id=$3, quarantined_until=NULL, renewed_at=$4, host_ip=NULLIF($5, '')
+	WHERE network_id=$1 AND ip=$2 AND container_id IS NULL AND quarantined_until <= $4`,
+		s.networkID, ip.String(), id, s.clock.Now(), s.hostIP)
+	if err != nil {
+		return false, fmt.Errorf("reclaiming: %s", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("reclaiming: %s", err) // not tested
+	}
+
+	return rows == 1, nil
+}
+
+// ReserveNext hands out addresses next-fit from a bitmap of the pool that
+// is built from the database the first time the pool is seen and kept up to
+// date with the reservations made and released through this store. Other
+// hosts share the table, so an insert can still lose a race for an address
+// the bitmap thinks is free; the address is then marked and the search moves
+// on. Addresses released elsewhere are only noticed when the bitmap is
+// rebuilt, which happens when it looks full.
+func (s *reservationStore) ReserveNext(id string, pool *ipam.Pool) (net.IP, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pb, fresh := s.bitmaps[pool.Key()], false
+	if pb == nil {
+		var err error
+		pb, err = s.loadBitmap(pool)
+		if err != nil {
+			return nil, err
+		}
+		fresh = true
+	}
+
+	for {
+		offset, ok := pb.bitmap.Next()
+		if !ok {
+			if fresh {
+				return nil, ipam.NoMoreAddressesError
+			}
+
+			var err error
+			pb, err = s.loadBitmap(pool)
+			if err != nil {
+				return nil, err
+			}
+			fresh = true
+			continue
+		}
+		pb.bitmap.Set(offset)
+
+		ip := pool.IP(offset)
+		ok, err := s.reserve(id, ip)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return ip, nil
+		}
+	}
+}
+
+// loadBitmap builds the bitmap for the pool from the reservations in the
+// database and caches it.
+func (s *reservationStore) loadBitmap(pool *ipam.Pool) (*poolBitmap, error) {
+	var reserved []string
+	err := s.conn.Select(&reserved, `
+	SELECT ip FROM ip_reservation
+	WHERE network_id=$1 AND (container_id IS NOT NULL OR quarantined_until > $2)`, s.networkID, s.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("listing reservations: %s", err)
+	}
+
+	pb := &poolBitmap{pool: pool, bitmap: pool.NewBitmap()}
+	for _, r := range reserved {
+		if offset, ok := pool.Offset(net.ParseIP(r)); ok {
+			pb.bitmap.Set(offset)
+		}
+	}
+	s.bitmaps[pool.Key()] = pb
+
+	return pb, nil
+}
+
+// ReleaseByID gives up the addresses held by the id. Quarantined addresses
+// stay marked in the bitmap; otherwise they are free to be handed out again
+// once the next-fit search comes back around to them.
+func (s *reservationStore) ReleaseByID(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.quarantine > 0 {
+		_, err := s.conn.Exec(`
+		UPDATE ip_reservation SET container_id=NULL, quarantined_until=$3
+		WHERE network_id=$1 AND container_id=$2`, s.networkID, id, s.clock.Now().Add(s.quarantine))
+		if err != nil {
+			return fmt.Errorf("quarantining: %s", err)
+		}
+
+		return nil
+	}
+
+	var released []string
+	err := s.conn.Select(&released, "DELETE FROM ip_reservation WHERE network_id=$1 AND container_id=$2 RETURNING ip", s.networkID, id)
+	if err != nil {
+		return fmt.Errorf("deleting: %s", err)
+	}
+
+	for _, r := range released {
+		ip := net.ParseIP(r)
+		for _, pb := range s.bitmaps {
+			if offset, ok := pb.pool.Offset(ip); ok {
+				pb.bitmap.Clear(offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *reservationStore) Contains(id string) (bool, error) {
+	var exists bool
+	err := s.conn.Get(&exists, "SELECT EXISTS(SELECT 1 FROM ip_reservation WHERE network_id=$1 AND container_id=$2)", s.networkID, id)
+	if err != nil {
+		return false, fmt.Errorf("checking reservation: %s", err)
+	}
+
+	return exists, nil
+}
+
+// Reservations lists the addresses held on the network, including those
+// still in quarantine.
+func (s *reservationStore) Reservations() ([]ipam.Reservation, error) {
+	var rows []struct {
+		IP          string         `db:"ip"`
+		ContainerID sql.NullString `db:"container_id"`
+	}
+	err := s.conn.Select(&rows, `
+	SELECT ip, container_id FROM ip_reservation
+	WHERE network_id=$1 AND (container_id IS NOT NULL OR quarantined_until > $2)`, s.networkID, s.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("listing reservations: %s", err)
+	}
+
+	reservations := []ipam.Reservation{}
+	for _, r := range rows {
+		reservations = append(reservations, ipam.Reservation{
+			ContainerID: r.ContainerID.String,
+			IP:          net.ParseIP(r.IP),
+		})
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,147 @@
+package store_test
+
+import (
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+)
+
+// The cold benchmarks build a new store for every allocation, so every call
+// pays for listing the network's reservations and building the bitmap, which
+// is what each allocation cost before the bitmap was cached. Run both to
+// compare:
+//
+//   go test ./store -run NONE -bench ReservationStore
+
+// filledNetwork returns a database whose /18 pool has all but a handful of
+// addresses reserved, which is where a scan for a free address is slowest.
+func filledNetwork(b *testing.B) (*sqlx.DB, *ipam.Pool, func()) {
+	dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+	testDatabase := testsupport.GetDBConnectionInfo().CreateDatabase(dbName)
+
+	realDb, err := db.GetConnectionPool(testDatabase.URL())
+	if err != nil {
+		b.Fatalf("connecting: %s", err)
+	}
+
+	_, err = store.New(realDb)
+	if err != nil {
+		b.Fatalf("migrating: %s", err)
+	}
+
+	pool := ipam.NewPool(types.IPConfig{
+		IP: net.IPNet{
+			IP:   net.ParseIP("10.255.0.0"),
+			Mask: net.CIDRMask(18, 32),
+		},
+		Gateway: net.ParseIP("10.255.0.1"),
+	})
+
+	tx, err := realDb.Beginx()
+	if err != nil {
+		b.Fatalf("begin: %s", err)
+	}
+	for offset := uint64(1); offset < pool.Size()-16; offset++ {
+		_, err = tx.Exec("INSERT INTO ip_reservation (network_id, ip, container_id) VALUES ($1, $2, $3)",
+			"some-network-id", pool.IP(offset).String(), fmt.Sprintf("container-%d", offset))
+		if err != nil {
+			b.Fatalf("filling: %s", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		b.Fatalf("commit: %s", err)
+	}
+
+	return realDb, pool, func() {
+		realDb.Close()
+		testDatabase.Destroy()
+	}
+}
+
+func reserveAndRelease(b *testing.B, reservationStore ipam.AllocatorStore, pool *ipam.Pool) {
+	_, err := reservationStore.ReserveNext("some-container", pool)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = reservationStore.ReleaseByID("some-container")
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkReservationStoreReserveNextAndRelease(b *testing.B) {
+	realDb, pool, cleanup := filledNetwork(b)
+	defer cleanup()
+
+	reservationStore := store.NewReservationStore(realDb, "some-network-id")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reserveAndRelease(b, reservationStore, pool)
+	}
+}
+
+func BenchmarkReservationStoreReserveNextAndReleaseCold(b *testing.B) {
+	realDb, pool, cleanup := filledNetwork(b)
+	defer cleanup()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reserveAndRelease(b, store.NewReservationStore(realDb, "some-network-id"), pool)
+	}
+}
+
+func BenchmarkReservationStoreContains(b *testing.B) {
+	realDb, _, cleanup := filledNetwork(b)
+	defer cleanup()
+
+	reservationStore := store.NewReservationStore(realDb, "some-network-id")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := reservationStore.Contains("container-8000")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReservationStoreAllocatorAllocateAndRelease(b *testing.B) {
+	realDb, _, cleanup := filledNetwork(b)
+	defer cleanup()
+
+	configFactory := &ipam.ConfigFactory{
+		Config: ipam.Config{
+			IP4: &types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("10.255.0.0"),
+					Mask: net.CIDRMask(18, 32),
+				},
+			},
+		},
+	}
+	allocator := ipam.New(&store.ReservationStoreFactory{DB: realDb}, &sync.Mutex{}, configFactory, &sync.Mutex{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := allocator.AllocateIP("some-network-id", "some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = allocator.ReleaseIP("some-network-id", "some-container")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
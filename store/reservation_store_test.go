@@ -0,0 +1,376 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/appc/cni/pkg/types"
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/ipam"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+)
+
+var _ = Describe("ReservationStore", func() {
+	var (
+		testDatabase     *testsupport.TestDatabase
+		realDb           *sqlx.DB
+		mockDb           *fakes.Db
+		reservationStore ipam.AllocatorStore
+	)
+
+	BeforeEach(func() {
+		mockDb = &fakes.Db{}
+
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		factory := &store.ReservationStoreFactory{DB: realDb}
+		reservationStore, err = factory.Create("some-network-id")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	Describe("Reserve", func() {
+		It("reserves an unused address", func() {
+			ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		Context("when the address is already reserved", func() {
+			BeforeEach(func() {
+				ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			})
+
+			It("does not reserve it again", func() {
+				ok, err := reservationStore.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+
+			It("allows the same address to be reserved on a different network", func() {
+				otherStore := store.NewReservationStore(realDb, "some-other-network-id")
+
+				ok, err := otherStore.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			})
+		})
+
+		Context("when the reservations survive a new store", func() {
+			It("sees the reservations made by the previous store", func() {
+				ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				restarted := store.NewReservationStore(realDb, "some-network-id")
+				ok, err = restarted.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+
+				Expect(restarted.Contains("some-id")).To(BeTrue())
+			})
+		})
+
+		Context("when the failure is an unexpected pq error", func() {
+			BeforeEach(func() {
+				mockDb.ExecReturns(nil, &pq.Error{Code: "2201G"})
+			})
+
+			It("returns the error code", func() {
+				_, err := store.NewReservationStore(mockDb, "some-network-id").Reserve("some-id", net.ParseIP("192.168.1.2"))
+				Expect(err).To(MatchError("insert: invalid_argument_for_width_bucket_function"))
+			})
+		})
+
+		Context("when the failure is not a pq Error", func() {
+			BeforeEach(func() {
+				mockDb.ExecReturns(nil, errors.New("some-insert-error"))
+			})
+
+			It("returns a sensible error", func() {
+				_, err := store.NewReservationStore(mockDb, "some-network-id").Reserve("some-id", net.ParseIP("192.168.1.2"))
+				Expect(err).To(MatchError("insert: some-insert-error"))
+			})
+		})
+	})
+
+	Describe("ReserveNext", func() {
+		var pool *ipam.Pool
+
+		BeforeEach(func() {
+			pool = ipam.NewPool(types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("192.168.1.0"),
+					Mask: net.CIDRMask(29, 32),
+				},
+				Gateway: net.ParseIP("192.168.1.1"),
+			})
+		})
+
+		It("reserves the first free address in the pool, skipping the gateway", func() {
+			ip, err := reservationStore.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.2"))
+
+			Expect(reservationStore.Contains("some-id")).To(BeTrue())
+		})
+
+		It("skips addresses that are already reserved", func() {
+			ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ip, err := reservationStore.ReserveNext("some-other-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.3"))
+		})
+
+		It("carries on from the last address it handed out", func() {
+			ip, err := reservationStore.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.2"))
+
+			Expect(reservationStore.ReleaseByID("some-id")).To(Succeed())
+
+			ip, err = reservationStore.ReserveNext("some-other-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.3"))
+		})
+
+		It("only lists the reservations once", func() {
+			countingDb := &fakes.Db{}
+			countingDb.SelectStub = realDb.Select
+			countingDb.ExecStub = realDb.Exec
+			countingStore := store.NewReservationStore(countingDb, "some-network-id")
+
+			for i := 0; i < 3; i++ {
+				_, err := countingStore.ReserveNext(fmt.Sprintf("id-%d", i), pool)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(countingDb.SelectCallCount()).To(Equal(1))
+		})
+
+		It("skips addresses reserved through another store since the bitmap was built", func() {
+			_, err := reservationStore.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+
+			otherHost := store.NewReservationStore(realDb, "some-network-id")
+			ok, err := otherHost.Reserve("some-other-id", net.ParseIP("192.168.1.3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ip, err := reservationStore.ReserveNext("some-third-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.4"))
+		})
+
+		It("finds addresses released through another store once the pool looks full", func() {
+			for i := 0; i < 5; i++ {
+				_, err := reservationStore.ReserveNext(fmt.Sprintf("id-%d", i), pool)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			otherHost := store.NewReservationStore(realDb, "some-network-id")
+			Expect(otherHost.ReleaseByID("id-2")).To(Succeed())
+
+			ip, err := reservationStore.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.4"))
+		})
+
+		It("returns a NoMoreAddressesError when the pool is exhausted", func() {
+			for i := 0; i < 5; i++ {
+				_, err := reservationStore.ReserveNext(fmt.Sprintf("id-%d", i), pool)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err := reservationStore.ReserveNext("one-too-many", pool)
+			Expect(err).To(Equal(ipam.NoMoreAddressesError))
+		})
+
+		Context("when listing the reservations fails", func() {
+			BeforeEach(func() {
+				mockDb.SelectReturns(errors.New("some-select-error"))
+			})
+
+			It("returns a sensible error", func() {
+				_, err := store.NewReservationStore(mockDb, "some-network-id").ReserveNext("some-id", pool)
+				Expect(err).To(MatchError("listing reservations: some-select-error"))
+			})
+		})
+	})
+
+	Describe("ReleaseByID", func() {
+		BeforeEach(func() {
+			ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("frees the address for reuse", func() {
+			Expect(reservationStore.ReleaseByID("some-id")).To(Succeed())
+
+			ok, err := reservationStore.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("does not release reservations on other networks", func() {
+			otherStore := store.NewReservationStore(realDb, "some-other-network-id")
+			Expect(otherStore.ReleaseByID("some-id")).To(Succeed())
+
+			Expect(reservationStore.Contains("some-id")).To(BeTrue())
+		})
+
+		Context("when the id is not reserved", func() {
+			It("silently succeeds", func() {
+				Expect(reservationStore.ReleaseByID("out-of-nowhere")).To(Succeed())
+			})
+		})
+
+		Context("when the db operation fails", func() {
+			BeforeEach(func() {
+				mockDb.SelectReturns(errors.New("some delete error"))
+			})
+
+			It("returns a sensible error", func() {
+				err := store.NewReservationStore(mockDb, "some-network-id").ReleaseByID("some-id")
+				Expect(err).To(MatchError("deleting: some delete error"))
+			})
+		})
+	})
+
+	Describe("quarantine", func() {
+		var (
+			fakeClock         *fakeclock.FakeClock
+			quarantiningStore ipam.AllocatorStore
+			pool              *ipam.Pool
+		)
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+
+			factory := &store.ReservationStoreFactory{
+				DB:         realDb,
+				Clock:      fakeClock,
+				Quarantine: time.Minute,
+			}
+
+			var err error
+			quarantiningStore, err = factory.Create("some-network-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			pool = ipam.NewPool(types.IPConfig{
+				IP: net.IPNet{
+					IP:   net.ParseIP("192.168.1.0"),
+					Mask: net.CIDRMask(29, 32),
+				},
+				Gateway: net.ParseIP("192.168.1.1"),
+			})
+
+			ip, err := quarantiningStore.ReserveNext("some-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.2"))
+
+			Expect(quarantiningStore.ReleaseByID("some-id")).To(Succeed())
+		})
+
+		It("releases the reservation", func() {
+			Expect(quarantiningStore.Contains("some-id")).To(BeFalse())
+		})
+
+		It("does not hand out released addresses until the quarantine has passed", func() {
+			ip, err := quarantiningStore.ReserveNext("some-other-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.3"))
+
+			fakeClock.Increment(time.Minute)
+
+			restarted := store.NewQuarantiningReservationStore(realDb, "some-network-id", fakeClock, time.Minute)
+			ip, err = restarted.ReserveNext("some-third-id", pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("192.168.1.2"))
+
+			Expect(restarted.Contains("some-third-id")).To(BeTrue())
+		})
+
+		It("does not let a quarantined address be reserved by name until the quarantine has passed", func() {
+			ok, err := quarantiningStore.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			fakeClock.Increment(time.Minute)
+
+			ok, err = quarantiningStore.Reserve("some-other-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = quarantiningStore.Reserve("some-third-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		Context("when the quarantine cannot be recorded", func() {
+			BeforeEach(func() {
+				mockDb.ExecReturns(nil, errors.New("some update error"))
+			})
+
+			It("returns a sensible error", func() {
+				err := store.NewQuarantiningReservationStore(mockDb, "some-network-id", fakeClock, time.Minute).ReleaseByID("some-id")
+				Expect(err).To(MatchError("quarantining: some update error"))
+			})
+		})
+	})
+
+	Describe("Contains", func() {
+		It("reports whether the id holds a reservation", func() {
+			ok, err := reservationStore.Reserve("some-id", net.ParseIP("192.168.1.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			Expect(reservationStore.Contains("some-id")).To(BeTrue())
+			Expect(reservationStore.Contains("some-other-id")).To(BeFalse())
+		})
+
+		Context("when the db operation fails", func() {
+			BeforeEach(func() {
+				mockDb.GetReturns(errors.New("some get error"))
+			})
+
+			It("returns a sensible error", func() {
+				_, err := store.NewReservationStore(mockDb, "some-network-id").Contains("some-id")
+				Expect(err).To(MatchError("checking reservation: some get error"))
+			})
+		})
+	})
+})
@@ -4,28 +4,21 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net"
 
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS container (
-  id text PRIMARY KEY,
-  ip text,
-  mac text,
-  host_ip text,
-  network_id text,
-  sandbox_name text,
-  app text
-);
-`
-
 //go:generate counterfeiter -o ../fakes/store.go --fake-name Store . Store
 type Store interface {
 	Create(container models.Container) error
 	Get(id string) (models.Container, error)
+	GetBySandboxAndIP(sandboxName, ip string) (models.Container, error)
+	GetBySandboxAndMAC(sandboxName, mac string) (models.Container, error)
 	All() ([]models.Container, error)
+	AllBySandbox(sandboxName string) ([]models.Container, error)
 	Delete(id string) error
 }
 
@@ -35,8 +28,14 @@ type db interface {
 	NamedExec(query string, arg interface{}) (sql.Result, error)
 	Get(dest interface{}, query string, args ...interface{}) error
 	Select(dest interface{}, query string, args ...interface{}) error
+	Beginx() (*sqlx.Tx, error)
 }
 
+// ContainerEventChannel is the channel the container table triggers NOTIFY
+// with a models.ContainerEvent payload whenever a container is created or
+// deleted.
+const ContainerEventChannel = "container_event"
+
 var RecordNotFoundError = errors.New("record not found")
 var RecordExistsError = errors.New("record already exists")
 
@@ -45,9 +44,9 @@ type store struct {
 }
 
 func New(dbConnectionPool db) (Store, error) {
-	err := setupTables(dbConnectionPool)
+	err := migrate(dbConnectionPool)
 	if err != nil {
-		return nil, fmt.Errorf("setting up tables: %s", err)
+		return nil, fmt.Errorf("running migrations: %s", err)
 	}
 
 	return &store{
@@ -58,9 +57,11 @@ func New(dbConnectionPool db) (Store, error) {
 func (s *store) Create(container models.Container) error {
 	_, err := s.conn.NamedExec(`
 	INSERT INTO container (
-		id, ip, mac, host_ip, network_id, sandbox_name, app
+		id, ip, ip6, mac, host_ip, network_id, sandbox_name, app,
+		container_namespace, interface_name, ipam_result
 	) VALUES (
-		:id, :ip, :mac, :host_ip, :network_id, :sandbox_name, :app
+		:id, :ip, :ip6, :mac, :host_ip, :network_id, :sandbox_name, :app,
+		:container_namespace, :interface_name, :ipam_result
 	)`, &container)
 	if err != nil {
 		pqErr, ok := err.(*pq.Error)
@@ -77,8 +78,32 @@ func (s *store) Create(container models.Container) error {
 }
 
 func (s *store) Get(id string) (models.Container, error) {
+	return s.getWhere("id=$1", id)
+}
+
+// GetBySandboxAndIP finds the container by its IPv4 or, for an IPv6
+// address, its IPv6 address.
+func (s *store) GetBySandboxAndIP(sandboxName, ip string) (models.Container, error) {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return s.getWhere("sandbox_name=$1 AND ip6=$2", sandboxName, parsed.String())
+	}
+
+	return s.getWhere("sandbox_name=$1 AND ip=$2", sandboxName, ip)
+}
+
+// GetBySandboxAndMAC finds the container by its hardware address, in any
+// of the notations net.ParseMAC accepts.
+func (s *store) GetBySandboxAndMAC(sandboxName, mac string) (models.Container, error) {
+	if parsed, err := net.ParseMAC(mac); err == nil {
+		mac = parsed.String()
+	}
+
+	return s.getWhere("sandbox_name=$1 AND mac=$2", sandboxName, mac)
+}
+
+func (s *store) getWhere(condition string, args ...interface{}) (models.Container, error) {
 	var container models.Container
-	err := s.conn.Get(&container, "SELECT * FROM container WHERE id=$1", id)
+	err := s.conn.Get(&container, "SELECT * FROM container WHERE "+condition+" LIMIT 1", args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.Container{}, RecordNotFoundError
@@ -99,6 +124,16 @@ func (s *store) All() ([]models.Container, error) {
 	return containers, nil
 }
 
+func (s *store) AllBySandbox(sandboxName string) ([]models.Container, error) {
+	containers := []models.Container{}
+	err := s.conn.Select(&containers, "SELECT * FROM container WHERE sandbox_name=$1", sandboxName)
+	if err != nil {
+		return nil, fmt.Errorf("listing by sandbox: %s", err)
+	}
+
+	return containers, nil
+}
+
 func (s *store) Delete(id string) error {
 	execResult, err := s.conn.Exec("DELETE FROM container WHERE id=$1", id)
 	if err != nil {
@@ -116,8 +151,3 @@ func (s *store) Delete(id string) error {
 
 	return nil
 }
-
-func setupTables(dbConnectionPool db) error {
-	_, err := dbConnectionPool.Exec(schema)
-	return err
-}
@@ -7,7 +7,9 @@ import (
 	"lib/db"
 	"lib/testsupport"
 	"math/rand"
+	"net"
 
+	"github.com/appc/cni/pkg/types"
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
 	"github.com/cloudfoundry-incubator/ducati-daemon/store"
@@ -35,6 +37,8 @@ var _ = Describe("Store", func() {
 		Expect(err).NotTo(HaveOccurred())
 		dataStore, err = store.New(realDb)
 		Expect(err).NotTo(HaveOccurred())
+
+		mockDb.BeginxStub = realDb.Beginx
 	})
 
 	AfterEach(func() {
@@ -56,12 +60,12 @@ var _ = Describe("Store", func() {
 
 		Context("when the db operation fails", func() {
 			BeforeEach(func() {
-				mockDb.ExecReturns(nil, errors.New("some error"))
+				mockDb.BeginxReturns(nil, errors.New("some error"))
 			})
 
 			It("should return a sensible error", func() {
 				_, err := store.New(mockDb)
-				Expect(err).To(MatchError("setting up tables: some error"))
+				Expect(err).To(MatchError("running migrations: begin transaction: some error"))
 			})
 		})
 	})
@@ -69,13 +73,26 @@ var _ = Describe("Store", func() {
 	Describe("round-tripping a container through the database", func() {
 		It("stores and retrieves all the fields on the container model", func() {
 			toCreate := models.Container{
-				NetworkID:   "some-crazy-network-id",
-				ID:          "some-container-id",
-				MAC:         "01:02:03:04:05:06",
-				IP:          "192.168.100.2",
-				HostIP:      "10.11.12.13",
-				SandboxName: "vni-99",
-				App:         "some-app-guid",
+				NetworkID:          "some-crazy-network-id",
+				ID:                 "some-container-id",
+				MAC:                "01:02:03:04:05:06",
+				IP:                 "192.168.100.2",
+				HostIP:             "10.11.12.13",
+				SandboxName:        "vni-99",
+				App:                "some-app-guid",
+				ContainerNamespace: "/some/container/ns/path",
+				InterfaceName:      "some-interface",
+				IPAMResult: &models.IPAMResult{
+					Result: types.Result{
+						IP4: &types.IPConfig{
+							IP: net.IPNet{
+								IP:   net.ParseIP("192.168.100.2"),
+								Mask: net.CIDRMask(24, 32),
+							},
+							Gateway: net.ParseIP("192.168.100.1"),
+						},
+					},
+				},
 			}
 
 			Expect(dataStore.Create(toCreate)).To(Succeed())
@@ -185,6 +202,105 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	Describe("GetBySandboxAndIP", func() {
+		var expectedContainer models.Container
+
+		BeforeEach(func() {
+			expectedContainer = models.Container{
+				ID:          "some-container",
+				IP:          "192.168.1.2",
+				SandboxName: "some-sandbox",
+			}
+
+			Expect(dataStore.Create(models.Container{ID: "some-other-container", IP: "192.168.1.2", SandboxName: "some-other-sandbox"})).To(Succeed())
+			Expect(dataStore.Create(expectedContainer)).To(Succeed())
+		})
+
+		It("retrieves the container with the matching sandbox and IP", func() {
+			container, err := dataStore.GetBySandboxAndIP("some-sandbox", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container).To(Equal(expectedContainer))
+		})
+
+		Context("when the IP is an IPv6 address", func() {
+			BeforeEach(func() {
+				Expect(dataStore.Delete(expectedContainer.ID)).To(Succeed())
+				expectedContainer.IP6 = "fd00::2"
+				Expect(dataStore.Create(expectedContainer)).To(Succeed())
+			})
+
+			It("retrieves the container with the matching IPv6 address", func() {
+				container, err := dataStore.GetBySandboxAndIP("some-sandbox", "fd00:0::2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container).To(Equal(expectedContainer))
+			})
+		})
+
+		Context("when no container matches", func() {
+			It("should return a RecordNotFoundError", func() {
+				_, err := dataStore.GetBySandboxAndIP("some-sandbox", "192.168.1.3")
+				Expect(err).To(Equal(store.RecordNotFoundError))
+			})
+		})
+
+		Context("when the db operation fails", func() {
+			BeforeEach(func() {
+				mockDb.GetReturns(errors.New("some get error"))
+			})
+
+			It("should return a sensible error", func() {
+				store, err := store.New(mockDb)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = store.GetBySandboxAndIP("some-sandbox", "192.168.1.2")
+				Expect(err).To(MatchError("getting record: some get error"))
+			})
+		})
+	})
+
+	Describe("GetBySandboxAndMAC", func() {
+		var expectedContainer models.Container
+
+		BeforeEach(func() {
+			expectedContainer = models.Container{
+				ID:          "some-container",
+				IP:          "192.168.1.2",
+				MAC:         "ee:ee:c0:a8:01:02",
+				SandboxName: "some-sandbox",
+			}
+
+			Expect(dataStore.Create(models.Container{ID: "some-other-container", IP: "192.168.1.2", MAC: "ee:ee:c0:a8:01:02", SandboxName: "some-other-sandbox"})).To(Succeed())
+			Expect(dataStore.Create(expectedContainer)).To(Succeed())
+		})
+
+		It("retrieves the container with the matching sandbox and MAC", func() {
+			container, err := dataStore.GetBySandboxAndMAC("some-sandbox", "EE-EE-C0-A8-01-02")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container).To(Equal(expectedContainer))
+		})
+
+		Context("when no container matches", func() {
+			It("should return a RecordNotFoundError", func() {
+				_, err := dataStore.GetBySandboxAndMAC("some-sandbox", "ee:ee:c0:a8:01:03")
+				Expect(err).To(Equal(store.RecordNotFoundError))
+			})
+		})
+
+		Context("when the db operation fails", func() {
+			BeforeEach(func() {
+				mockDb.GetReturns(errors.New("some get error"))
+			})
+
+			It("should return a sensible error", func() {
+				store, err := store.New(mockDb)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = store.GetBySandboxAndMAC("some-sandbox", "ee:ee:c0:a8:01:02")
+				Expect(err).To(MatchError("getting record: some get error"))
+			})
+		})
+	})
+
 	Describe("All", func() {
 		var expectedContainers []models.Container
 
@@ -221,6 +337,42 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	Describe("AllBySandbox", func() {
+		var expectedContainers []models.Container
+
+		BeforeEach(func() {
+			expectedContainers = []models.Container{
+				{ID: "some-id-1", SandboxName: "vni-1"},
+				{ID: "some-id-2", SandboxName: "vni-1"},
+			}
+
+			for _, c := range expectedContainers {
+				Expect(dataStore.Create(c)).To(Succeed())
+			}
+			Expect(dataStore.Create(models.Container{ID: "some-id-3", SandboxName: "vni-2"})).To(Succeed())
+		})
+
+		It("returns the containers in the sandbox", func() {
+			containers, err := dataStore.AllBySandbox("vni-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containers).To(ConsistOf(expectedContainers))
+		})
+
+		Context("when the db operation fails", func() {
+			BeforeEach(func() {
+				mockDb.SelectReturns(errors.New("some select error"))
+			})
+
+			It("should return a sensible error", func() {
+				store, err := store.New(mockDb)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = store.AllBySandbox("vni-1")
+				Expect(err).To(MatchError("listing by sandbox: some select error"))
+			})
+		})
+	})
+
 	Describe("Delete", func() {
 		BeforeEach(func() {
 			theContainers := []models.Container{
@@ -254,7 +406,7 @@ var _ = Describe("Store", func() {
 		Context("when the db operation fails", func() {
 			BeforeEach(func() {
 				mockDb.ExecStub = func(string, ...interface{}) (sql.Result, error) {
-					if mockDb.ExecCallCount() == 2 {
+					if mockDb.ExecCallCount() == 1 {
 						return nil, errors.New("some delete error")
 					}
 					return nil, nil
@@ -276,7 +428,7 @@ var _ = Describe("Store", func() {
 				mockExecResult.RowsAffectedReturns(0, errors.New("some rows affected error"))
 
 				mockDb.ExecStub = func(string, ...interface{}) (sql.Result, error) {
-					if mockDb.ExecCallCount() == 2 {
+					if mockDb.ExecCallCount() == 1 {
 						return mockExecResult, nil
 					}
 					return nil, nil
@@ -298,7 +450,7 @@ var _ = Describe("Store", func() {
 				mockExecResult.RowsAffectedReturns(-1, nil)
 
 				mockDb.ExecStub = func(string, ...interface{}) (sql.Result, error) {
-					if mockDb.ExecCallCount() == 2 {
+					if mockDb.ExecCallCount() == 1 {
 						return mockExecResult, nil
 					}
 					return nil, nil
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// MaxVNI is one more than the largest VXLAN network identifier.
+const MaxVNI = 1 << 24
+
+// maxVNIProbes bounds how far past a colliding hint the registry looks for a
+// free VNI before giving up.
+const maxVNIProbes = 256
+
+type VNIRegistry interface {
+	Assign(networkID string, hint int) (int, error)
+	Register(networkID string, vni int) error
+}
+
+type vniRegistry struct {
+	conn db
+}
+
+// NewVNIRegistry returns a registry that records the VNI of every network in
+// the network_vni table, so that no two networks ever share one.
+func NewVNIRegistry(dbConnectionPool db) VNIRegistry {
+	return &vniRegistry{conn: dbConnectionPool}
+}
+
+// Assign returns the VNI registered for the network. A network that has none
+// yet is given the hint when it is free, or else the next free VNI after it.
+func (r *vniRegistry) Assign(networkID string, hint int) (int, error) {
+	if hint < 0 || hint >= MaxVNI {
+		return 0, fmt.Errorf("vni hint out of range: %d", hint)
+	}
+
+	vni, err := r.lookup(networkID)
+	if err != RecordNotFoundError {
+		return vni, err
+	}
+
+	for probe := 0; probe < maxVNIProbes; probe++ {
+		candidate := (hint + probe) % MaxVNI
+
+		_, err := r.conn.Exec("INSERT INTO network_vni (network_id, vni) VALUES ($1, $2)", networkID, candidate)
+		if err == nil {
+			return candidate, nil
+		}
+
+		pqErr, ok := err.(*pq.Error)
+		if !ok {
+			return 0, fmt.Errorf("assigning vni: %s", err)
+		}
+		if pqErr.Code.Name() != "unique_violation" {
+			return 0, fmt.Errorf("assigning vni: %s", pqErr.Code.Name())
+		}
+		if pqErr.Constraint == "network_vni_pkey" {
+			// another daemon registered the network first
+			return r.lookup(networkID)
+		}
+	}
+
+	return 0, fmt.Errorf("no free vni within %d of %d", maxVNIProbes, hint)
+}
+
+// Register records vni as the network's VNI, the way network definitions
+// do. It returns VNIConflictError when the network already has a different
+// VNI or when another network holds this one.
+func (r *vniRegistry) Register(networkID string, vni int) error {
+	if vni < 0 || vni >= MaxVNI {
+		return fmt.Errorf("vni out of range: %d", vni)
+	}
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	err = registerVNI(tx, networkID, vni)
+	if err == VNIConflictError {
+		// another daemon may have registered the same VNI for the network first
+		if registered, lookupErr := r.lookup(networkID); lookupErr == nil && registered == vni {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit: %s", err)
+	}
+
+	return nil
+}
+
+func (r *vniRegistry) lookup(networkID string) (int, error) {
+	var vni int
+	err := r.conn.Get(&vni, "SELECT vni FROM network_vni WHERE network_id=$1", networkID)
+	if err == sql.ErrNoRows {
+		return 0, RecordNotFoundError
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading vni: %s", err)
+	}
+
+	return vni, nil
+}
@@ -0,0 +1,171 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"lib/db"
+	"lib/testsupport"
+	"math/rand"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VNIRegistry", func() {
+	var (
+		testDatabase *testsupport.TestDatabase
+		realDb       *sqlx.DB
+		mockDb       *fakes.Db
+		registry     store.VNIRegistry
+	)
+
+	BeforeEach(func() {
+		mockDb = &fakes.Db{}
+
+		dbName := fmt.Sprintf("test_ducati_database_%x", rand.Int())
+		dbConnectionInfo := testsupport.GetDBConnectionInfo()
+		testDatabase = dbConnectionInfo.CreateDatabase(dbName)
+
+		var err error
+		realDb, err = db.GetConnectionPool(testDatabase.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.New(realDb)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry = store.NewVNIRegistry(realDb)
+	})
+
+	AfterEach(func() {
+		if realDb != nil {
+			Expect(realDb.Close()).To(Succeed())
+		}
+		if testDatabase != nil {
+			testDatabase.Destroy()
+		}
+	})
+
+	It("assigns the hint to a new network", func() {
+		vni, err := registry.Assign("network-1", 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(42))
+	})
+
+	It("keeps returning the assigned VNI whatever the hint", func() {
+		_, err := registry.Assign("network-1", 42)
+		Expect(err).NotTo(HaveOccurred())
+
+		vni, err := registry.Assign("network-1", 99)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni).To(Equal(42))
+	})
+
+	Context("when the hint is held by another network", func() {
+		It("assigns the next free VNI", func() {
+			_, err := registry.Assign("network-1", 42)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = registry.Assign("network-2", 43)
+			Expect(err).NotTo(HaveOccurred())
+
+			vni, err := registry.Assign("network-3", 42)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vni).To(Equal(44))
+		})
+
+		It("wraps around at the top of the VNI range", func() {
+			_, err := registry.Assign("network-1", store.MaxVNI-1)
+			Expect(err).NotTo(HaveOccurred())
+
+			vni, err := registry.Assign("network-2", store.MaxVNI-1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vni).To(Equal(0))
+		})
+	})
+
+	Describe("Register", func() {
+		It("records the VNI for the network", func() {
+			Expect(registry.Register("network-1", 42)).To(Succeed())
+
+			vni, err := registry.Assign("network-1", 99)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vni).To(Equal(42))
+		})
+
+		It("succeeds when the network already has the VNI", func() {
+			Expect(registry.Register("network-1", 42)).To(Succeed())
+			Expect(registry.Register("network-1", 42)).To(Succeed())
+		})
+
+		Context("when the network already has a different VNI", func() {
+			It("returns a VNIConflictError", func() {
+				_, err := registry.Assign("network-1", 7)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(registry.Register("network-1", 42)).To(Equal(store.VNIConflictError))
+			})
+		})
+
+		Context("when another network holds the VNI", func() {
+			It("returns a VNIConflictError", func() {
+				_, err := registry.Assign("network-2", 42)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(registry.Register("network-1", 42)).To(Equal(store.VNIConflictError))
+			})
+		})
+
+		Context("when the VNI is out of range", func() {
+			It("returns an error", func() {
+				Expect(registry.Register("network-1", store.MaxVNI)).To(MatchError(fmt.Sprintf("vni out of range: %d", store.MaxVNI)))
+			})
+		})
+
+		Context("when beginning the transaction fails", func() {
+			It("returns a sensible error", func() {
+				mockDb.BeginxReturns(nil, errors.New("some begin error"))
+
+				err := store.NewVNIRegistry(mockDb).Register("network-1", 42)
+				Expect(err).To(MatchError("begin transaction: some begin error"))
+			})
+		})
+	})
+
+	Context("when the hint is out of range", func() {
+		It("returns an error", func() {
+			_, err := registry.Assign("network-1", store.MaxVNI)
+			Expect(err).To(MatchError(fmt.Sprintf("vni hint out of range: %d", store.MaxVNI)))
+
+			_, err = registry.Assign("network-1", -1)
+			Expect(err).To(MatchError("vni hint out of range: -1"))
+		})
+	})
+
+	Context("when the db operations fail", func() {
+		It("returns a sensible error when reading fails", func() {
+			mockDb.GetReturns(errors.New("some get error"))
+
+			_, err := store.NewVNIRegistry(mockDb).Assign("network-1", 42)
+			Expect(err).To(MatchError("reading vni: some get error"))
+		})
+
+		It("returns a sensible error when inserting fails", func() {
+			mockDb.GetStub = realDb.Get
+			mockDb.ExecReturns(nil, errors.New("some insert error"))
+
+			_, err := store.NewVNIRegistry(mockDb).Assign("network-1", 42)
+			Expect(err).To(MatchError("assigning vni: some insert error"))
+		})
+
+		It("names the postgres error code when inserting fails", func() {
+			mockDb.GetStub = realDb.Get
+			mockDb.ExecReturns(nil, &pq.Error{Code: "42P01"})
+
+			_, err := store.NewVNIRegistry(mockDb).Assign("network-1", 42)
+			Expect(err).To(MatchError("assigning vni: undefined_table"))
+		})
+	})
+})
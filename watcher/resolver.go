@@ -15,7 +15,7 @@ type resolver interface {
 
 type Resolver struct {
 	Logger lager.Logger
-	Store  store.Store
+	Store  containerLocator
 }
 
 func (d *Resolver) ResolveMisses(misses <-chan Neighbor, knownNeighbors chan<- Neighbor) {
@@ -26,36 +26,23 @@ func (d *Resolver) ResolveMisses(misses <-chan Neighbor, knownNeighbors chan<- N
 			"msg":     msg,
 		})
 
-		containers, err := d.Store.All()
+		container, err := d.Store.GetBySandboxAndIP(filepath.Base(msg.SandboxName), msg.Neigh.IP.String())
 		if err != nil {
-			d.Logger.Error("store-retrieval-failed", err)
-			continue
-		}
-
-		found := false
-		for _, container := range containers {
-			if container.SandboxName != filepath.Base(msg.SandboxName) {
-				continue
-			}
-
-			if container.IP == msg.Neigh.IP.String() {
-				mac, err := net.ParseMAC(container.MAC)
-				if err != nil {
-					d.Logger.Error("parse-mac-failed", err)
-					break
-				}
-
-				msg.Neigh.HardwareAddr = mac
-				msg.VTEP = net.ParseIP(container.HostIP)
-				found = true
-				break
+			if err != store.RecordNotFoundError {
+				d.Logger.Error("store-retrieval-failed", err)
 			}
+			continue
 		}
 
-		if !found {
+		mac, err := net.ParseMAC(container.MAC)
+		if err != nil {
+			d.Logger.Error("parse-mac-failed", err)
 			continue
 		}
 
+		msg.Neigh.HardwareAddr = mac
+		msg.VTEP = net.ParseIP(container.HostIP)
+
 		d.Logger.Info("resolved", lager.Data{
 			"msg":     msg,
 			"hw_addr": msg.Neigh.HardwareAddr.String(),
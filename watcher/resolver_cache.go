@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/pivotal-golang/clock"
+)
+
+type containerLocator interface {
+	GetBySandboxAndIP(sandboxName, ip string) (models.Container, error)
+}
+
+type cacheKey struct {
+	sandboxName string
+	ip          string
+}
+
+type cacheEntry struct {
+	container models.Container
+	found     bool
+	expires   time.Time
+}
+
+// ResolverCache fronts the datastore for neighbor resolution. Containers that
+// are found are cached for TTL; lookups that come back empty are cached for
+// NegativeTTL so that a storm of misses for an unknown address does not turn
+// into a storm of queries. A zero duration disables that kind of caching.
+type ResolverCache struct {
+	Store       containerLocator
+	Clock       clock.Clock
+	TTL         time.Duration
+	NegativeTTL time.Duration
+
+	lock      sync.Mutex
+	entries   map[cacheKey]cacheEntry
+	nextSweep time.Time
+}
+
+func (c *ResolverCache) GetBySandboxAndIP(sandboxName, ip string) (models.Container, error) {
+	key := cacheKey{sandboxName: sandboxName, ip: ip}
+
+	if entry, ok := c.lookup(key); ok {
+		if !entry.found {
+			return models.Container{}, store.RecordNotFoundError
+		}
+		return entry.container, nil
+	}
+
+	container, err := c.Store.GetBySandboxAndIP(sandboxName, ip)
+	switch {
+	case err == nil:
+		c.insert(key, cacheEntry{container: container, found: true}, c.TTL)
+	case err == store.RecordNotFoundError:
+		c.insert(key, cacheEntry{found: false}, c.NegativeTTL)
+	}
+
+	return container, err
+}
+
+// Invalidate drops any cached resolution for the sandbox and IP, whether it
+// was a hit or a miss.
+func (c *ResolverCache) Invalidate(sandboxName, ip string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, cacheKey{sandboxName: sandboxName, ip: ip})
+}
+
+func (c *ResolverCache) lookup(key cacheKey) (cacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	if !c.Clock.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (c *ResolverCache) insert(key cacheKey, entry cacheEntry, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.Clock.Now()
+	if c.entries == nil {
+		c.entries = map[cacheKey]cacheEntry{}
+	}
+
+	if now.After(c.nextSweep) {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(ttl)
+	}
+
+	entry.expires = now.Add(ttl)
+	c.entries[key] = entry
+}
@@ -0,0 +1,178 @@
+package watcher_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
+	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
+	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+)
+
+var _ = Describe("ResolverCache", func() {
+	var (
+		fakeStore *fakes.Store
+		fakeClock *fakeclock.FakeClock
+		cache     *watcher.ResolverCache
+		expected  models.Container
+	)
+
+	BeforeEach(func() {
+		fakeStore = &fakes.Store{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		expected = models.Container{
+			ID:          "some-container-id",
+			IP:          "192.168.1.2",
+			MAC:         "ff:ff:ff:ff:ff:ff",
+			HostIP:      "10.11.12.13",
+			SandboxName: "some-sandbox-name",
+		}
+		fakeStore.GetBySandboxAndIPReturns(expected, nil)
+
+		cache = &watcher.ResolverCache{
+			Store:       fakeStore,
+			Clock:       fakeClock,
+			TTL:         10 * time.Second,
+			NegativeTTL: 2 * time.Second,
+		}
+	})
+
+	It("looks up the container in the store", func() {
+		container, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container).To(Equal(expected))
+
+		Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(1))
+		sandboxName, ip := fakeStore.GetBySandboxAndIPArgsForCall(0)
+		Expect(sandboxName).To(Equal("some-sandbox-name"))
+		Expect(ip).To(Equal("192.168.1.2"))
+	})
+
+	It("serves repeated lookups from the cache until the TTL expires", func() {
+		for i := 0; i < 3; i++ {
+			container, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container).To(Equal(expected))
+		}
+		Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(1))
+
+		fakeClock.Increment(10 * time.Second)
+
+		_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(2))
+	})
+
+	It("keys entries by sandbox and IP", func() {
+		_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cache.GetBySandboxAndIP("some-other-sandbox-name", "192.168.1.2")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.3")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(3))
+	})
+
+	Context("when the store does not know the address", func() {
+		BeforeEach(func() {
+			fakeStore.GetBySandboxAndIPReturns(models.Container{}, store.RecordNotFoundError)
+		})
+
+		It("caches the miss for the negative TTL", func() {
+			for i := 0; i < 3; i++ {
+				_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+				Expect(err).To(Equal(store.RecordNotFoundError))
+			}
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(1))
+
+			fakeClock.Increment(2 * time.Second)
+
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			fakeStore.GetBySandboxAndIPReturns(models.Container{}, errors.New("banana"))
+		})
+
+		It("returns the error and does not cache it", func() {
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(MatchError("banana"))
+
+			_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(MatchError("banana"))
+
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the TTLs are zero", func() {
+		BeforeEach(func() {
+			cache.TTL = 0
+			cache.NegativeTTL = 0
+		})
+
+		It("does not cache anything", func() {
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeStore.GetBySandboxAndIPReturns(models.Container{}, store.RecordNotFoundError)
+			_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+
+			_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(3))
+		})
+	})
+
+	Describe("Invalidate", func() {
+		It("drops a cached hit", func() {
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+
+			cache.Invalidate("some-sandbox-name", "192.168.1.2")
+
+			fakeStore.GetBySandboxAndIPReturns(models.Container{}, store.RecordNotFoundError)
+			_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(2))
+		})
+
+		It("drops a cached miss", func() {
+			fakeStore.GetBySandboxAndIPReturns(models.Container{}, store.RecordNotFoundError)
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).To(Equal(store.RecordNotFoundError))
+
+			cache.Invalidate("some-sandbox-name", "192.168.1.2")
+
+			fakeStore.GetBySandboxAndIPReturns(expected, nil)
+			container, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container).To(Equal(expected))
+		})
+
+		It("leaves other entries alone", func() {
+			_, err := cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+
+			cache.Invalidate("some-sandbox-name", "192.168.1.3")
+
+			_, err = cache.GetBySandboxAndIP("some-sandbox-name", "192.168.1.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeStore.GetBySandboxAndIPCallCount()).To(Equal(1))
+		})
+	})
+})
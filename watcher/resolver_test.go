@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/ducati-daemon/fakes"
 	"github.com/cloudfoundry-incubator/ducati-daemon/models"
+	"github.com/cloudfoundry-incubator/ducati-daemon/store"
 	"github.com/cloudfoundry-incubator/ducati-daemon/watcher"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -53,25 +54,22 @@ var _ = Describe("Resolver", func() {
 				},
 			}
 
-			fakeStore.AllReturns([]models.Container{
-				models.Container{
-					IP:          "1.2.3.4",
-					HostIP:      "10.10.10.10",
-					SandboxName: "some-sandbox-name",
-				},
-				models.Container{
-					IP:          "192.168.1.2",
-					MAC:         "ff:ff:ff:ff:ff:ff",
-					HostIP:      "10.11.12.13",
-					SandboxName: "some-sandbox-name",
-				},
+			fakeStore.GetBySandboxAndIPReturns(models.Container{
+				IP:          "192.168.1.2",
+				MAC:         "ff:ff:ff:ff:ff:ff",
+				HostIP:      "10.11.12.13",
+				SandboxName: "some-sandbox-name",
 			}, nil)
 		})
 
-		It("retrieves the addresses associated with each incoming IP", func() {
+		It("looks up the container by sandbox name and IP", func() {
 			missesChannel <- msg
 
-			Eventually(fakeStore.AllCallCount).Should(Equal(1))
+			Eventually(fakeStore.GetBySandboxAndIPCallCount).Should(Equal(1))
+			sandboxName, ip := fakeStore.GetBySandboxAndIPArgsForCall(0)
+			Expect(sandboxName).To(Equal("some-sandbox-name"))
+			Expect(ip).To(Equal("192.168.1.2"))
+			Expect(fakeStore.AllCallCount()).To(Equal(0))
 		})
 
 		Context("when the IP and sandbox match", func() {
@@ -90,26 +88,9 @@ var _ = Describe("Resolver", func() {
 			})
 		})
 
-		Context("when the IP matches but the sandbox name does not", func() {
-			BeforeEach(func() {
-				msg = watcher.Neighbor{
-					SandboxName: "/path/to/some-other-sandbox-name",
-					Neigh: watcher.Neigh{
-						IP: net.ParseIP("192.168.1.2"),
-					},
-				}
-			})
-
-			It("does not write to the output channel", func() {
-				missesChannel <- msg
-
-				Consistently(knownNeighborsChannel).ShouldNot(Receive())
-			})
-		})
-
 		Context("when store fails", func() {
 			BeforeEach(func() {
-				fakeStore.AllReturns(nil, errors.New("banana"))
+				fakeStore.GetBySandboxAndIPReturns(models.Container{}, errors.New("banana"))
 				missesChannel <- msg
 			})
 
@@ -122,25 +103,27 @@ var _ = Describe("Resolver", func() {
 			})
 		})
 
-		Context("when the store results do not contain a match", func() {
+		Context("when the store does not contain a match", func() {
 			BeforeEach(func() {
-				msg.Neigh.IP = net.ParseIP("10.12.13.14")
+				fakeStore.GetBySandboxAndIPReturns(models.Container{}, store.RecordNotFoundError)
 				missesChannel <- msg
 			})
 
 			It("does not process the message", func() {
 				Consistently(knownNeighborsChannel).ShouldNot(Receive())
 			})
+
+			It("does not log an error", func() {
+				Consistently(logger).ShouldNot(gbytes.Say("store-retrieval-failed"))
+			})
 		})
 
 		Context("when fails to parseMAC of matching container", func() {
 			BeforeEach(func() {
-				fakeStore.AllReturns([]models.Container{
-					models.Container{
-						IP:          "192.168.1.2",
-						MAC:         "bad-mac",
-						SandboxName: "some-sandbox-name",
-					},
+				fakeStore.GetBySandboxAndIPReturns(models.Container{
+					IP:          "192.168.1.2",
+					MAC:         "bad-mac",
+					SandboxName: "some-sandbox-name",
 				}, nil)
 
 				missesChannel <- msg